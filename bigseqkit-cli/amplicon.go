@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bigseqkit"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+)
+
+func runAmplicon(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitAmpliconOptions(cmd)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Amplicon(input, opts)
+	})
+	return output, nil, err
+}
+
+func parseSeqKitAmpliconOptions(cmd *cobra.Command) *bigseqkit.SeqKitAmpliconOptions {
+	return (&bigseqkit.SeqKitAmpliconOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		Forward(getFlagString(cmd, "forward")).
+		Reverse(getFlagString(cmd, "reverse")).
+		PrimerFile(getFlagString(cmd, "primer-file")).
+		MaxMismatch(getFlagInt(cmd, "max-mismatch")).
+		OnlyPositiveStrand(getFlagBool(cmd, "only-positive-strand")).
+		OnlyInner(getFlagBool(cmd, "only-inner")).
+		Bed(getFlagBool(cmd, "bed")).
+		MinLen(getFlagInt(cmd, "min-len")).
+		MaxLen(getFlagInt(cmd, "max-len")).
+		Circular(getFlagBool(cmd, "circular"))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "amplicon",
+			Short: "retrieve amplicon (or specific region around it) via primer(s)",
+			Long: `retrieve amplicon (or specific region around it) via primer(s)
+
+Attentions:
+  1. Primers are given with -F/--forward and -R/--reverse (5'-3', the
+     reverse primer on the reverse strand), or as a tab-delimited file
+     (-p/--primer-file) of primer name, forward and reverse primer.
+  2. IUPAC degenerate bases are allowed in primers. Up to -m/--max-mismatch
+     mismatches are allowed in every primer, searched with the FM-index
+     like "locate", which does not allow degenerate bases.
+  3. Every forward primer hit is paired with the nearest downstream hit of
+     the reverse primer, on both strands unless -P/--only-positive-strand.
+  4. Products are named "ID_primer:start-end:strand" (1-based). -I/--only-inner
+     removes the primers. --min-len and --max-len filter the product size
+     (primers included).
+  5. In circular mode (-C/--circular) products may cross the origin of the
+     sequence, their BED end is greater than the sequence length.
+  6. -B/--bed outputs BED6 with the primer name and the number of
+     mismatches as score.
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitAmpliconOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runAmplicon)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runAmplicon)
+
+		cmd.Flags().StringP("forward", "F", "", "forward primer (5'-primer-3'), degenerate bases allowed")
+		cmd.Flags().StringP("reverse", "R", "", "reverse primer (5'-primer-3'), degenerate bases allowed")
+		cmd.Flags().StringP("primer-file", "p", "", "3-column tab-delimited primer file: name, forward primer, reverse primer")
+		cmd.Flags().IntP("max-mismatch", "m", 0, "max mismatch of every primer")
+		cmd.Flags().BoolP("only-positive-strand", "P", false, "only search on positive strand")
+		cmd.Flags().BoolP("only-inner", "I", false, "only output the region between the primers")
+		cmd.Flags().BoolP("bed", "B", false, "output in BED6 format")
+		cmd.Flags().IntP("min-len", "", 0, "minimum product size")
+		cmd.Flags().IntP("max-len", "", 0, "maximum product size, 0 for no limit")
+		cmd.Flags().BoolP("circular", "C", false, "circular genome")
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+)
+
+func runCardinality(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitCardinalityOptions(cmd)
+	records, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := bigseqkit.Cardinality(records, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := func() error {
+		fmt.Fprintf(reportOut, "num_seqs\tdistinct\terror_bound\tstd_error(%%)\tdup_rate(%%)\n")
+		fmt.Fprintf(reportOut, "%d\t%d\t%d\t%.2f\t%.2f\n", info.Records, info.Distinct, info.ErrorBound(),
+			info.StdError*100, info.DuplicationRate()*100)
+		return nil
+	}
+
+	return nil, report, nil
+}
+
+func parseSeqKitCardinalityOptions(cmd *cobra.Command) *bigseqkit.SeqKitCardinalityOptions {
+	return (&bigseqkit.SeqKitCardinalityOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		BySeq(getFlagBool(cmd, "by-seq")).
+		ByName(getFlagBool(cmd, "by-name")).
+		IgnoreCase(getFlagBool(cmd, "ignore-case")).
+		OnlyPositiveStrand(getFlagBool(cmd, "only-positive-strand")).
+		Precision(getFlagInt(cmd, "precision"))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "cardinality",
+			Short: "estimate the number of distinct sequences by ID/name/sequence",
+			Long: `estimate the number of distinct sequences by ID/name/sequence
+Attentions:
+  1. The keys are the same used by "rmdup", when comparing by sequences,
+     both positive and negative strands are compared. Switch on
+     -P/--only-positive-strand for considering the positive strand only.
+  2. The count is estimated with HyperLogLog sketches of 2^precision
+     registers, the relative standard error is 1.04/sqrt(2^precision).
+     The error bound covers two standard errors (~95% confidence).
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitCardinalityOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runCardinality)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runCardinality)
+
+		cmd.Flags().BoolP("by-name", "n", false, "by full name instead of just id")
+		cmd.Flags().BoolP("by-seq", "s", false, "by seq")
+		cmd.Flags().BoolP("ignore-case", "i", false, "ignore case")
+		cmd.Flags().BoolP("only-positive-strand", "P", false, "only considering positive strand when comparing by sequence")
+		cmd.Flags().IntP("precision", "p", 14, "HyperLogLog precision, number of registers is 2^precision (4-18)")
+	})
+}
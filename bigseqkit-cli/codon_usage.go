@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+	"math"
+	"strings"
+)
+
+func runCodonUsage(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitCodonUsageOptions(cmd)
+	sequences, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if getFlagBool(cmd, "per-record") {
+		records, err := bigseqkit.CodonUsagePerRecord(sequences, opts)
+		return records, nil, err
+	}
+
+	info, err := bigseqkit.CodonUsage(sequences, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := func() error {
+		var sb strings.Builder
+		codons := info.Codons()
+		rscu := info.RSCU()
+		sb.WriteString("codon\taa\tcount\tper_thousand\tRSCU\n")
+		for i := 0; i < 64; i++ {
+			perThousand := 0.0
+			if codons > 0 {
+				perThousand = float64(info.Counts[i]) * 1000 / float64(codons)
+			}
+			sb.WriteString(fmt.Sprintf("%s\t%c\t%d\t%.2f\t%.3f\n", bigseqkit.CodonString(i), info.AminoAcid(i),
+				info.Counts[i], perThousand, rscu[i]))
+		}
+		enc := "NA"
+		if v := info.ENC(); !math.IsNaN(v) {
+			enc = fmt.Sprintf("%.2f", v)
+		}
+		sb.WriteString(fmt.Sprintf("# num_seqs: %d, sense_codons: %d, GC3: %.4f, ENC: %s\n", info.Records, codons, info.GC3(), enc))
+		if info.Incomplete > 0 || info.Ambiguous > 0 {
+			sb.WriteString(fmt.Sprintf("# incomplete_seqs: %d, ambiguous_codons: %d\n", info.Incomplete, info.Ambiguous))
+		}
+		fmt.Fprint(reportOut, sb.String())
+		return nil
+	}
+
+	return nil, report, nil
+}
+
+func parseSeqKitCodonUsageOptions(cmd *cobra.Command) *bigseqkit.SeqKitCodonUsageOptions {
+	return (&bigseqkit.SeqKitCodonUsageOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		TranslTable(getFlagInt(cmd, "transl-table"))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "codon-usage",
+			Short: "codon usage statistics (counts, RSCU, ENC, GC3) of coding sequences",
+			Long: `codon usage statistics (counts, RSCU, ENC, GC3) of coding sequences
+
+Attentions:
+  1. Codons are read in frame 1 of every sequence, e.g., a CDS FASTA or the
+     output of "orfs". Codons with ambiguous bases are not counted.
+  2. RSCU (relative synonymous codon usage) and ENC (effective number of
+     codons, Wright 1990) group the synonymous codons with the translate
+     table (-T/--transl-table). GC3 is the GC content of the third position
+     of the sense codons.
+  3. By default the aggregated table of all sequences is output,
+     --per-record outputs a line per sequence: ID, sense codons, GC3, ENC,
+     and the counts and RSCU of the 64 codons (comma-separated, in the
+     TTT, TTC, TTA, TTG, TCT, ... order).
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitCodonUsageOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runCodonUsage)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runCodonUsage)
+
+		cmd.Flags().IntP("transl-table", "T", 1, `translate table/genetic code, type 'seqkit translate --help' for more details`)
+		cmd.Flags().BoolP("per-record", "r", false, "output the statistics of every sequence")
+	})
+}
@@ -2,17 +2,17 @@ package main
 
 import (
 	"bigseqkit"
-	"fmt"
 	"github.com/spf13/cobra"
 	"ignis/driver/api"
 )
 
-func runCommon(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runCommon(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	if len(input) < 2 {
-		checkError(fmt.Errorf("at least 2 files needed"))
+		return nil, nil, bigseqkit.OptionErrorf("at least 2 files needed")
 	}
 	opts := parseSeqKitCommonOptions(cmd)
-	return check(bigseqkit.Common(input[0], input[1], opts, input[2:]...))
+	output, err := bigseqkit.Common(input[0], input[1], opts, input[2:]...)
+	return output, nil, err
 }
 
 func parseSeqKitCommonOptions(cmd *cobra.Command) *bigseqkit.SeqKitCommonOptions {
@@ -34,8 +34,9 @@ func init() {
 Note:
   1. 'seqkit common' is designed to support 2 and MORE files.
   2. When comparing by sequences, both positive and negative strands are
-     compared. Switch on -P/--only-positive-strand for considering the
-     positive strand only.
+     compared: a sequence and its reverse complement share the same
+     canonical key, min(seq, revcom(seq)). Switch on -P/--only-positive-strand
+     for considering the positive strand only.
   3. For 2 files, 'seqkit grep' is much faster and consumes lesser memory:
      seqkit grep -f <(seqkit seq -n -i small.fq.gz) big.fq.gz # by seq ID
      seqkit grep -s -f <(seqkit seq -s small.fq.gz) big.fq.gz # by seq
@@ -43,11 +44,15 @@ Note:
      retrieved if the sequence/ID was shared in multiple files.
      So the records number may be larger than that of the smallest file.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runCommon)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitCommonOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runCommon)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runCommon)
 
 		cmd.Flags().BoolP("by-name", "n", false, "match by full name instead of just id")
 		cmd.Flags().BoolP("by-seq", "s", false, "match by sequence")
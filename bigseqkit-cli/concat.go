@@ -5,25 +5,55 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"ignis/driver/api"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
-func runConcat(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runConcat(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	if len(input) < 2 {
-		checkError(fmt.Errorf("at least 2 files needed"))
+		return nil, nil, bigseqkit.OptionErrorf("at least 2 files needed")
 	}
 	opts := parseSeqKitConcatOptions(cmd)
-	result := input[0]
-	for i := 1; i < len(input); i++ {
-		result = check(bigseqkit.Concat(result, input[i], opts))
+
+	partitionFile := getFlagString(cmd, "partition-file")
+	if partitionFile == "" {
+		output, err := bigseqkit.ConcatN(input, opts)
+		return output, nil, err
+	}
+
+	names := make([]string, len(input))
+	files, err := getFileListFromArgsAndFile(cmd, args, false, "infile-list", false)
+	if err != nil {
+		return nil, nil, err
 	}
-	return result
+	for i := range names {
+		if !pipe && len(files) == len(input) && files[i] != stdio {
+			names[i] = strings.TrimSuffix(filepath.Base(files[i]), filepath.Ext(files[i]))
+		} else {
+			names[i] = fmt.Sprintf("input%d", i)
+		}
+	}
+
+	result, partitions, err := bigseqkit.ConcatPartitions(input, names, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := func() error {
+		return os.WriteFile(partitionFile, []byte(partitions), 0644)
+	}
+
+	return result, report, nil
 }
 
 func parseSeqKitConcatOptions(cmd *cobra.Command) *bigseqkit.SeqKitConcatOptions {
 	return (&bigseqkit.SeqKitConcatOptions{}).
 		Config(parseSeqKitConfig(cmd)).
 		Separator(getFlagString(cmd, "separator")).
-		Full(getFlagBool(cmd, "full"))
+		Full(getFlagBool(cmd, "full")).
+		Fill(getFlagString(cmd, "fill")).
+		PartitionType(getFlagString(cmd, "partition-type"))
 }
 
 func init() {
@@ -41,14 +71,27 @@ Attentions:
       product of sequences.
    3. Description are also concatenated with a separator (-s/--separator).
    4. Order of sequences with different IDs are random.
+   5. With -f/--full, the blocks of the files missing an ID are filled with
+      -F/--fill (gaps by default) of the locus length (the length of the
+      longest sequence of the file), e.g. -F - or -F N.
+   6. -p/--partition-file saves a RAxML/IQ-TREE style partition file with the
+      block of every file, e.g. "DNA, gene1 = 1-500". It implies -f/--full,
+      so the positions are valid for every record of aligned files.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runConcat)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitConcatOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runConcat)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runConcat)
 
 		cmd.Flags().BoolP("full", "f", false, "keep all sequences, like full/outer join")
 		cmd.Flags().StringP("separator", "s", "|", "separator for descriptions of records with the same ID")
+		cmd.Flags().StringP("fill", "F", "", "character to fill the missing blocks with when using -f/--full, e.g. - or N (default \"-\")")
+		cmd.Flags().StringP("partition-file", "p", "", "save a RAxML/IQ-TREE style partition file")
+		cmd.Flags().StringP("partition-type", "", "DNA", "data type of the blocks in the partition file, e.g. DNA or WAG")
 	})
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var defaults *bigseqkit.Defaults
+
+// defaultSources are the sources of the persistent flags set from the defaults, and envSources of the
+// environment variables of the config files
+var defaultSources = make(map[string]string)
+var envSources = make(map[string]string)
+
+// applyDefaults sets the persistent flags not given in the command line from the config files and the
+// BIGSEQKIT_* environment variables, and exports the environment variables of the config files.
+func applyDefaults(cmd *cobra.Command) error {
+	if defaults == nil {
+		var err error
+		if defaults, err = bigseqkit.LoadDefaults(); err != nil {
+			return err
+		}
+		for name, value := range defaults.Env {
+			if _, ok := os.LookupEnv(name); ok {
+				envSources[name] = "environment"
+			} else {
+				envSources[name] = value.Source
+			}
+		}
+		if err = defaults.ExportEnv(); err != nil {
+			return err
+		}
+	}
+	persistent := cmd.Root().PersistentFlags()
+	for key, value := range defaults.Values {
+		if persistent.Lookup(key) == nil {
+			if strings.HasPrefix(value.Source, bigseqkit.DefaultsEnvPrefix) {
+				continue // other BIGSEQKIT_* variables, e.g. of the scripts
+			}
+			return bigseqkit.OptionErrorf("unknown option %s in config file %s", key, value.Source)
+		}
+		f := cmd.Flags().Lookup(key)
+		if f == nil || f.Changed {
+			continue
+		}
+		if err := f.Value.Set(value.Value); err != nil {
+			return bigseqkit.OptionErrorf("invalid value of %s (%s): %s", key, value.Source, value.Value)
+		}
+		defaultSources[key] = value.Source
+	}
+	return nil
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+		cmd := &cobra.Command{
+			Use:   "config",
+			Short: "show the defaults of the flags",
+			Long: fmt.Sprintf(`show the defaults of the flags
+
+The global flags (e.g. --partitions, --line-width) not given in the command
+line are read from the environment variables %s<FLAG> (e.g.
+%sLINE_WIDTH), the project config file (./%s) and the
+user config file (%s), in that order. A config file
+is a YAML map of flags to values, and an "env" map of environment variables
+exported when they are not already set, e.g. of the cluster:
+
+  partitions: 64
+  merge: true
+  env:
+    IGNIS_HOME: /opt/ignis
+
+`, bigseqkit.DefaultsEnvPrefix, bigseqkit.DefaultsEnvPrefix, bigseqkit.DefaultsFile, bigseqkit.DefaultsFiles()[0]),
+		}
+		parent.AddCommand(cmd)
+
+		cmd.AddCommand(&cobra.Command{
+			Use:   "show",
+			Short: "print the effective values of the global flags and their source",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				cmd.Root().PersistentFlags().VisitAll(func(f *pflag.Flag) {
+					source := "default"
+					if f.Changed {
+						source = "flag"
+					} else if s, ok := defaultSources[f.Name]; ok {
+						source = s
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\n", f.Name, f.Value.String(), source)
+				})
+				names := make([]string, 0, len(envSources))
+				for name := range envSources {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					fmt.Fprintf(w, "env %s\t%s\t%s\n", name, os.Getenv(name), envSources[name])
+				}
+				return w.Flush()
+			},
+		})
+	})
+}
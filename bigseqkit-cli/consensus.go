@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bigseqkit"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+)
+
+func runConsensus(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitConsensusOptions(cmd)
+	file := getFlagString(cmd, "vcf")
+	if file == "" {
+		return nil, nil, bigseqkit.OptionErrorf("flag --vcf needed")
+	}
+	vcf, err := bigseqkit.ReadAnnotation(file, jobWorker)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	consensus, mapping, err := bigseqkit.Consensus(records, vcf, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var report func() error
+	if mapFile := getFlagString(cmd, "map-file"); mapFile != "" {
+		report = func() error {
+			return bigseqkit.StoreFASTX(mapping, mapFile)
+		}
+	}
+
+	return consensus, report, nil
+}
+
+func parseSeqKitConsensusOptions(cmd *cobra.Command) *bigseqkit.SeqKitConsensusOptions {
+	return (&bigseqkit.SeqKitConsensusOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		Sample(getFlagString(cmd, "sample")).
+		Iupac(getFlagBool(cmd, "iupac")).
+		PassOnly(getFlagBool(cmd, "pass-only")).
+		Conflict(getFlagString(cmd, "conflict")).
+		MapFormat(getFlagString(cmd, "map-format"))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "consensus",
+			Short: "apply VCF variants to reference sequences",
+			Long: `apply VCF variants to reference sequences
+
+Attentions:
+  1. Sequences are matched with the CHROM column by ID (case insensitive),
+     sequences without variants are output unchanged.
+  2. SNPs, MNPs and indels are applied, symbolic alleles (<DEL>, breakends
+     and '*') are ignored. REF must match the reference sequence.
+  3. Without --sample the first ALT allele of every variant is applied.
+     With --sample, the genotype (GT) of the sample selects the allele,
+     missing and homozygous reference genotypes are ignored.
+     Heterozygous SNPs are written as IUPAC codes with --iupac.
+  4. Overlapping variants and variants of chromosomes missing from the
+     reference fail by default, use --conflict skip to keep the first of
+     the overlapping variants and ignore the rest with a warning.
+  5. --map-file saves the coordinate mapping from the reference to the
+     consensus, as UCSC chain ("chain", usable by liftOver) or as a TSV of
+     offsets ("offsets": seqID, first reference position after an indel,
+     offset to add to the following reference positions).
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitConsensusOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runConsensus)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runConsensus)
+
+		cmd.Flags().StringP("vcf", "", "", "VCF file with the variants")
+		cmd.Flags().StringP("sample", "s", "", "apply the genotypes of this sample")
+		cmd.Flags().BoolP("iupac", "", false, "write heterozygous SNPs as IUPAC ambiguity codes")
+		cmd.Flags().BoolP("pass-only", "", false, "only apply variants with FILTER PASS")
+		cmd.Flags().StringP("conflict", "", "error", "policy for overlapping variants and variants without reference (error|skip)")
+		cmd.Flags().StringP("map-file", "m", "", "save the coordinate mapping to this file")
+		cmd.Flags().StringP("map-format", "", "chain", "format of the coordinate mapping (chain|offsets)")
+	})
+}
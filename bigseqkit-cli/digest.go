@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+	"os"
+	"sort"
+	"strings"
+)
+
+func runDigest(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitDigestOptions(cmd)
+	records, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	fragments, err := bigseqkit.Digest(records, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var report func() error
+	if histFile := getFlagString(cmd, "hist-file"); histFile != "" {
+		if err = fragments.Cache(); err != nil {
+			return nil, nil, err
+		}
+		report = func() error {
+			histogram, err := bigseqkit.DigestHistogram(fragments, opts)
+			if err != nil {
+				return err
+			}
+			bins := make([]int64, 0, len(histogram))
+			for bin := range histogram {
+				bins = append(bins, bin)
+			}
+			sort.Slice(bins, func(i, j int) bool { return bins[i] < bins[j] })
+
+			bin := int64(getFlagInt(cmd, "hist-bin"))
+			var sb strings.Builder
+			sb.WriteString("min_len\tmax_len\tfragments\n")
+			for _, b := range bins {
+				sb.WriteString(fmt.Sprintf("%d\t%d\t%d\n", b, b+bin-1, histogram[b]))
+			}
+			return os.WriteFile(histFile, []byte(sb.String()), 0644)
+		}
+	}
+
+	return fragments, report, nil
+}
+
+func parseSeqKitDigestOptions(cmd *cobra.Command) *bigseqkit.SeqKitDigestOptions {
+	return (&bigseqkit.SeqKitDigestOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		Enzymes(getFlagStringSlice(cmd, "enzyme")).
+		EnzymeFile(getFlagString(cmd, "enzyme-file")).
+		Circular(getFlagBool(cmd, "circular")).
+		Bed(getFlagBool(cmd, "bed")).
+		MinLen(getFlagInt(cmd, "min-len")).
+		MaxLen(getFlagInt(cmd, "max-len")).
+		HistBin(getFlagInt(cmd, "hist-bin")).
+		SplitLen(getFlagInt(cmd, "split-len"))
+}
+
+func listEnzymes() {
+	names := make([]string, 0, len(bigseqkit.RestrictionEnzymes))
+	for name := range bigseqkit.RestrictionEnzymes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s\t%s\n", name, bigseqkit.RestrictionEnzymes[name])
+	}
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "digest",
+			Short: "in-silico restriction digest of sequences",
+			Long: `in-silico restriction digest of sequences
+
+Attentions:
+  1. Enzymes (-e/--enzyme, multiple values supported for multi-enzyme
+     digests) are taken from the built-in table (--list-enzymes), or from a
+     tab-delimited file (-E/--enzyme-file) of enzyme name and site.
+  2. Sites are given in REBASE notation: '^' marks the cut of the top strand
+     of palindromic sites (G^AATTC), (n/m) the cuts of the top and bottom
+     strands after non-palindromic sites (GGTCTC(1/5)). IUPAC degenerate
+     bases are allowed, and sites are searched on both strands.
+  3. Fragments are delimited by the cuts of the positive strand, overhangs
+     are not reported. They are named "ID_start-end" (1-based) with the
+     enzymes cutting both ends ('.' for the sequence ends) as description.
+  4. In circular mode (-C/--circular) the fragment crossing the origin is
+     joined, its BED end is greater than the sequence length.
+  5. -B/--bed outputs BED6 with the fragment length as score.
+  6. --hist-file saves the fragment-length histogram with bins of
+     --hist-bin bases.
+  7. Sequences longer than --split-len are split into chunks that are
+     digested by different executors. The fragments crossing the ends of
+     the chunks are output after the rest.
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitDigestOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if getFlagBool(cmd, "list-enzymes") {
+					listEnzymes()
+					return nil
+				}
+				return ignisDriver(cmd, args, runDigest)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runDigest)
+
+		cmd.Flags().StringSliceP("enzyme", "e", []string{}, "restriction enzyme name (multiple values supported)")
+		cmd.Flags().StringP("enzyme-file", "E", "", "2-column tab-delimited enzyme file: name, site in REBASE notation")
+		cmd.Flags().BoolP("list-enzymes", "", false, "list the built-in enzymes and exit")
+		cmd.Flags().BoolP("circular", "C", false, "circular genome")
+		cmd.Flags().BoolP("bed", "B", false, "output in BED6 format")
+		cmd.Flags().IntP("min-len", "", 0, "minimum fragment length")
+		cmd.Flags().IntP("max-len", "", 0, "maximum fragment length, 0 for no limit")
+		cmd.Flags().StringP("hist-file", "", "", "save the fragment-length histogram to a file")
+		cmd.Flags().IntP("hist-bin", "", 100, "bin size of the fragment-length histogram")
+		cmd.Flags().IntP("split-len", "", 1000000, "split sequences longer than this among the executors, 0 for no splitting")
+	})
+}
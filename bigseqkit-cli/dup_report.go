@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+	"sort"
+	"strings"
+)
+
+func runDupReport(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitDupReportOptions(cmd)
+	records, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	report, histogram, err := bigseqkit.DupReport(records, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var histogramReport func() error
+	if !pipe {
+		histogramReport = func() error {
+			levels := make([]int64, 0, len(histogram))
+			for level := range histogram {
+				levels = append(levels, level)
+			}
+			sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+
+			var sb strings.Builder
+			sb.WriteString("copies\tsequences\n")
+			for _, level := range levels {
+				sb.WriteString(fmt.Sprintf("%d\t%d\n", level, histogram[level]))
+			}
+			fmt.Fprint(reportOut, sb.String())
+			return nil
+		}
+	}
+
+	return report, histogramReport, nil
+}
+
+func parseSeqKitDupReportOptions(cmd *cobra.Command) *bigseqkit.SeqKitRmDupOptions {
+	return (&bigseqkit.SeqKitRmDupOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		BySeq(getFlagBool(cmd, "by-seq")).
+		ByName(getFlagBool(cmd, "by-name")).
+		IgnoreCase(getFlagBool(cmd, "ignore-case")).
+		OnlyPositiveStrand(getFlagBool(cmd, "only-positive-strand"))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "dup-report",
+			Short: "report duplicated sequences by ID/name/sequence and the duplication levels",
+			Long: `report duplicated sequences by ID/name/sequence and the duplication levels
+Output:
+  1. One tab-separated line for every duplicated key with the name of the
+     representative (first) record, the number of copies, the IDs of the
+     duplicates and the representative sequence (and qualities for FASTQ).
+  2. The duplication-level histogram (number of distinct sequences seen
+     1x, 2x, ...) is printed to stdout.
+Attentions:
+  1. When comparing by sequences, both positive and negative strands are
+     compared. Switch on -P/--only-positive-strand for considering the
+     positive strand only.
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitDupReportOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runDupReport)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runDupReport)
+
+		cmd.Flags().BoolP("by-name", "n", false, "by full name instead of just id")
+		cmd.Flags().BoolP("by-seq", "s", false, "by seq")
+		cmd.Flags().BoolP("ignore-case", "i", false, "ignore case")
+		cmd.Flags().BoolP("only-positive-strand", "P", false, "only considering positive strand when comparing by sequence")
+	})
+}
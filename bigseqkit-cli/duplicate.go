@@ -6,19 +6,18 @@ import (
 	"ignis/driver/api"
 )
 
-func runDuplicate(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runDuplicate(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitDuplicateOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Duplicate(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Duplicate(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitDuplicateOptions(cmd *cobra.Command) *bigseqkit.SeqKitDuplicateOptions {
 	return (&bigseqkit.SeqKitDuplicateOptions{}).
 		Config(parseSeqKitConfig(cmd)).
-		Times(int64(getFlagPositiveInt(cmd, "times")))
+		Times(int64(getFlagInt(cmd, "times")))
 }
 
 func init() {
@@ -31,11 +30,15 @@ func init() {
 			Long: `duplicate sequences N times
 You may need "seqkit rename" to make the the sequence IDs unique.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runDuplicate)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitDuplicateOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runDuplicate)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runDuplicate)
 
 		cmd.Flags().IntP("times", "n", 1, "duplication number")
 	})
@@ -6,13 +6,12 @@ import (
 	"ignis/driver/api"
 )
 
-func runFa2Fq(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runFa2Fq(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitFa2FqOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Fa2Fq(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Fa2Fq(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitFa2FqOptions(cmd *cobra.Command) *bigseqkit.SeqKitFa2FqOptions {
@@ -34,11 +33,15 @@ Attention:
      so they share sequence IDs, and sequences in FASTA
      should be subseq of sequences in FASTQ file.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runFa2Fq)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitFa2FqOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runFa2Fq)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runFa2Fq)
 
 		cmd.Flags().StringP("fasta-file", "f", "", "FASTA file)")
 		cmd.Flags().BoolP("only-positive-strand", "P", false, "only search on positive strand")
@@ -7,31 +7,40 @@ import (
 	"ignis/driver/api"
 )
 
-func runFaidx(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runFaidx(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	if len(input) != 1 {
-		checkError(fmt.Errorf("only 1 file needed"))
+		return nil, nil, bigseqkit.OptionErrorf("only 1 file needed")
 	}
 	opts := parseSeqKitFaidxOptions(cmd)
-	files := getFileListFromArgsAndFile(cmd, args, false, "infile-list", false)
+	files, err := getFileListFromArgsAndFile(cmd, args, false, "infile-list", false)
+	if err != nil {
+		return nil, nil, err
+	}
 	if !pipe {
 		files = files[1:]
 	}
 	opts.Regions(files)
-	checkError(input[0].Cache())
+	if err = input[0].Cache(); err != nil {
+		return nil, nil, err
+	}
 
 	idx, queries, err := bigseqkit.Faidx(input[0], opts)
-	checkError(err)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if queries == nil {
-		return idx
+		return idx, nil, nil
 	}
 
 	idxFile := getFlagString(cmd, "index-file")
 	if idxFile != "" {
-		checkError(bigseqkit.StoreFASTX(idx, idxFile))
+		if err = bigseqkit.StoreFASTX(idx, idxFile); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return queries
+	return queries, nil, nil
 }
 
 func parseSeqKitFaidxOptions(cmd *cobra.Command) *bigseqkit.SeqKitFaidxOptions {
@@ -59,11 +68,15 @@ The definition of region is 1-based and with some custom design.
 Examples:
 %s
 `, regionExample),
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runFaidx)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitFaidxOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runFaidx)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runFaidx)
 
 		cmd.Flags().BoolP("use-regexp", "r", false, "IDs are regular expression. But subseq region is not supported here.")
 		cmd.Flags().BoolP("ignore-case", "i", false, "ignore case")
@@ -6,13 +6,12 @@ import (
 	"ignis/driver/api"
 )
 
-func runFq2Fa(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runFq2Fa(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitFq2FaOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Fq2Fa(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Fq2Fa(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitFq2FaOptions(cmd *cobra.Command) *bigseqkit.SeqKitFq2FaOptions {
@@ -28,10 +27,14 @@ func init() {
 			Short: "convert FASTQ to FASTA",
 			Long: `convert FASTQ to FASTA
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runFq2Fa)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitFq2FaOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runFq2Fa)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runFq2Fa)
 	})
 }
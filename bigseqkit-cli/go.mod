@@ -4,13 +4,17 @@ go 1.20
 
 require (
 	bigseqkit v0.0.0
+	github.com/dustin/go-humanize v1.0.0
+	github.com/shenwei356/bio v0.7.0
+	github.com/shenwei356/util v0.5.0
 	github.com/spf13/cobra v1.2.1
+	github.com/spf13/pflag v1.0.5
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 	ignis v0.0.0
 )
 
 require (
 	github.com/apache/thrift v0.15.0 // indirect
-	github.com/dustin/go-humanize v1.0.0 // indirect
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/klauspost/compress v1.15.1 // indirect
 	github.com/klauspost/pgzip v1.2.5 // indirect
@@ -18,11 +22,8 @@ require (
 	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/pierrec/xxHash v0.1.5 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
-	github.com/shenwei356/bio v0.7.0 // indirect
 	github.com/shenwei356/natsort v0.0.0-20190418160752-600d539c017d // indirect
-	github.com/shenwei356/util v0.5.0 // indirect
 	github.com/shenwei356/xopen v0.2.1 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/tatsushid/go-prettytable v0.0.0-20141013043238-ed2d14c29939 // indirect
 	github.com/ulikunitz/xz v0.5.10 // indirect
 )
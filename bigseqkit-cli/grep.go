@@ -7,15 +7,24 @@ import (
 	"ignis/driver/api"
 )
 
-func runGrep(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runGrep(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitGrepOptions(cmd)
+	records, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
 	if getFlagBool(cmd, "count") {
-		fOuput = func() {
-			fmt.Print(check(bigseqkit.GrepCount(union(cmd, input...), opts)))
-		}
-		return nil
+		return nil, func() error {
+			count, err := bigseqkit.GrepCount(records, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(reportOut, count)
+			return nil
+		}, nil
 	}
-	return check(bigseqkit.Grep(union(cmd, input...), opts))
+	output, err := bigseqkit.Grep(records, opts)
+	return output, nil, err
 }
 
 func parseSeqKitGrepOptions(cmd *cobra.Command) *bigseqkit.SeqKitGrepOptions {
@@ -28,7 +37,7 @@ func parseSeqKitGrepOptions(cmd *cobra.Command) *bigseqkit.SeqKitGrepOptions {
 		InvertMatch(getFlagBool(cmd, "invert-match")).
 		BySeq(getFlagBool(cmd, "by-seq")).
 		OnlyPositiveStrand(getFlagBool(cmd, "only-positive-strand")).
-		MaxMismatch(getFlagNonNegativeInt(cmd, "max-mismatch")).
+		MaxMismatch(getFlagInt(cmd, "max-mismatch")).
 		ByName(getFlagBool(cmd, "by-name")).
 		IgnoreCase(getFlagBool(cmd, "ignore-case")).
 		Degenerate(getFlagBool(cmd, "degenerate")).
@@ -72,11 +81,15 @@ The definition of region is 1-based and with some custom design.
 Examples:
 %s
 `, regionExample),
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runGrep)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitGrepOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runGrep)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runGrep)
 
 		cmd.Flags().StringSliceP("pattern", "p", []string{""}, `search pattern (multiple values supported. Attention: use double quotation marks for patterns containing comma, e.g., -p '"A{2,}"'))`)
 		cmd.Flags().StringP("pattern-file", "f", "", "pattern file (one record per line)")
@@ -6,19 +6,18 @@ import (
 	"ignis/driver/api"
 )
 
-func runHead(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runHead(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitHeadOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Head(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Head(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitHeadOptions(cmd *cobra.Command) *bigseqkit.SeqKitHeadOptions {
 	return (&bigseqkit.SeqKitHeadOptions{}).
 		Config(parseSeqKitConfig(cmd)).
-		N(int64(getFlagPositiveInt(cmd, "number")))
+		N(int64(getFlagInt(cmd, "number")))
 }
 
 func init() {
@@ -31,11 +30,15 @@ func init() {
 For returning the last N records, use:
     seqkit range -N:-1 seqs.fasta
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runHead)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitHeadOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runHead)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runHead)
 
 		cmd.Flags().IntP("number", "n", 10, "print first N FASTA/Q records")
 	})
@@ -6,19 +6,18 @@ import (
 	"ignis/driver/api"
 )
 
-func runHeadGenome(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runHeadGenome(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitHeadGenomeOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.HeadGenome(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.HeadGenome(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitHeadGenomeOptions(cmd *cobra.Command) *bigseqkit.SeqKitHeadGenomeOptions {
 	return (&bigseqkit.SeqKitHeadGenomeOptions{}).
 		Config(parseSeqKitConfig(cmd)).
-		MiniCommonWords(int64(getFlagPositiveInt(cmd, "mini-common-words")))
+		MiniCommonWords(int64(getFlagInt(cmd, "mini-common-words")))
 }
 
 func init() {
@@ -41,11 +40,15 @@ i.e., "Vibrio cholerae strain M29".
 Attention:
   1. Sequences in file should be well organized.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runHeadGenome)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitHeadGenomeOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runHeadGenome)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runHeadGenome)
 
 		cmd.Flags().IntP("mini-common-words", "m", 1, "minimal shared prefix words")
 	})
@@ -8,28 +8,55 @@ import (
 	"github.com/shenwei356/bio/seqio/fastx"
 	"github.com/spf13/cobra"
 	"ignis/driver/api"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
+// stdio is the file name of the standard input and output
+const stdio = "-"
+
 var commands = make([]func(*cobra.Command), 0, 30)
 var jobWorker *api.IWorker
-var jobInput []*api.IDataFrame[string]
-var jobOuput *api.IDataFrame[string]
-var fOuput func()
+
+// cmdRunner runs a command on its input, it returns the output sequences (nil if none) and the report of the
+// non-sequence results (nil if none), which is called once the output is stored. pipe is set in the nodes of
+// a pipe.
+type cmdRunner func(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error)
+
+// commandStarted is set once the command line is parsed, the errors before are usage errors
+var commandStarted bool
+
+// runners are the runners of the commands by name, the nodes of a pipe call them directly
+var runners = make(map[string]cmdRunner)
+
+// stdinFile is the file where the standard input was staged, the executors only read files
+var stdinFile string
+var stagingDir string
+var bytesWritten int64
+
+// bytesInput is the size of the input files read by the executors, the total of the progress, which reads it
+// while the input is read
+var bytesInput atomic.Int64
+
+// reportOut is where the reports are printed, the standard error when the sequences are stored in the standard
+// output, so they are not mixed
+var reportOut io.Writer = os.Stdout
 
 func addCommand(f func(*cobra.Command)) {
 	commands = append(commands, f)
 }
 
-func check[T any](e T, err error) T {
-	if err != nil {
-		panic(err)
-	}
-	return e
+func addRunner(cmd *cobra.Command, runner cmdRunner) {
+	runners[cmd.Name()] = runner
 }
 
-func checkError(err error) {
+// checkFlag panics if a flag is not defined or has another type, which is a bug of the command.
+func checkFlag(err error) {
 	if err != nil {
 		panic(err)
 	}
@@ -44,110 +71,309 @@ func extension(s string, es []string) bool {
 	return false
 }
 
-func readSeqs(cmd *cobra.Command, args []string, pipe bool) []*api.IDataFrame[string] {
+func readSeqs(cmd *cobra.Command, args []string, pipe bool) ([]*api.IDataFrame[string], error) {
 	flag := true
 	if cmd.Use == "faidx" {
 		flag = false
 		if pipe {
-			return make([]*api.IDataFrame[string], 0)
+			return make([]*api.IDataFrame[string], 0), nil
 		}
 	}
 
-	files := getFileListFromArgsAndFile(cmd, args, flag, "infile-list", flag)
+	files, err := getFileListFromArgsAndFile(cmd, args, flag, "infile-list", flag)
+	if err != nil {
+		return nil, err
+	}
 	input := make([]*api.IDataFrame[string], len(files))
 
-	for i, file := range files {
-		fileInfo := check(os.Stat(file))
-		if fileInfo.IsDir() {
-			input[i] = check(jobWorker.PartitionTextFile(file))
-		} else if extension(file, []string{".fa", ".fna", ".ffn", ".faa", ".frn"}) {
-			input[i] = check(bigseqkit.ReadFASTA(file, jobWorker))
-		} else if extension(file, []string{".fq", ".fastq"}) {
-			input[i] = check(bigseqkit.ReadFASTQ(file, jobWorker))
-		} else {
-			f := check(os.Open(file))
-			buff := []byte{0}
-			check(f.Read(buff))
-			checkError(f.Close())
-			if buff[0] == '>' {
-				input[i] = check(bigseqkit.ReadFASTA(file, jobWorker))
-			} else if buff[0] == '@' {
-				input[i] = check(bigseqkit.ReadFASTQ(file, jobWorker))
-			} else {
-				checkError(fmt.Errorf(" <file> must be fasta or fastq"))
-			}
+	for i, name := range files {
+		if input[i], err = readSeqFile(name); err != nil {
+			return nil, err
 		}
 		if !flag {
 			break
 		}
 	}
-	return input
+	return input, nil
 }
 
-func ignisDriver(cmd *cobra.Command, args []string,
-	f func(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string]) {
-	if jobInput != nil {
-		jobInput = append(jobInput, readSeqs(cmd, args, true)...)
-		jobOuput = f(jobInput, cmd, args, true)
-		if getFlagInt(cmd, "partitions") > 0 {
-			jobOuput = check(jobOuput.Repartition(int64(getFlagInt(cmd, "partitions")), true, false))
+// readSeqFile reads a FASTA/Q file, by extension or by its first byte, or a directory of partitions.
+func readSeqFile(name string) (*api.IDataFrame[string], error) {
+	file := name
+	if file == stdio {
+		var err error
+		if file, err = stageStdin(); err != nil {
+			return nil, err
 		}
-		return
 	}
+	fileInfo, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.IsDir() {
+		return jobWorker.PartitionTextFile(file) // the directories of partitions are read without counters
+	}
+	bytesInput.Add(fileInfo.Size())
+	if extension(file, []string{".fa", ".fna", ".ffn", ".faa", ".frn"}) {
+		return bigseqkit.ReadFASTA(file, jobWorker)
+	} else if extension(file, []string{".fq", ".fastq"}) {
+		return bigseqkit.ReadFASTQ(file, jobWorker)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	buff := []byte{0}
+	_, err = f.Read(buff)
+	f.Close()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if buff[0] == '>' {
+		return bigseqkit.ReadFASTA(file, jobWorker)
+	} else if buff[0] == '@' {
+		return bigseqkit.ReadFASTQ(file, jobWorker)
+	}
+	return nil, &fs.PathError{Op: "read", Path: name, Err: fmt.Errorf("<file> must be fasta or fastq")}
+}
+
+// staging returns the staging directory (--tmp-dir), which must be shared with the executors.
+func staging() (string, error) {
+	dir := stagingDir
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Abs(dir)
+}
 
-	check(0, api.Ignis.Start())
+// stagingFile creates an empty file in the staging directory.
+func stagingFile(pattern string) (string, error) {
+	dir, err := staging()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// stageStdin copies the standard input to a staging file, only once because it can only be read once.
+func stageStdin() (string, error) {
+	if stdinFile == "" {
+		path, err := stagingFile("bigseqkit-stdin-*")
+		if err != nil {
+			return "", err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY, 0644)
+		if err != nil {
+			os.Remove(path)
+			return "", err
+		}
+		_, err = io.Copy(f, os.Stdin)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+			return "", err
+		}
+		stdinFile = path
+	}
+	return stdinFile, nil
+}
+
+// stagedFiles replaces the standard input by its staging file, it is dropped if it was not staged yet.
+func stagedFiles(files []string) []string {
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		if file != stdio {
+			result = append(result, file)
+		} else if stdinFile != "" {
+			result = append(result, stdinFile)
+		}
+	}
+	return result
+}
+
+// storeFASTX stores the output in a path, or in order to the standard output if the path is "-". The size of
+// the output is added to bytesWritten.
+func storeFASTX(output *api.IDataFrame[string], path string, merge bool) error {
+	if path != stdio {
+		var err error
+		if merge {
+			err = bigseqkit.StoreFASTX(output, path)
+		} else {
+			err = bigseqkit.StoreFASTXN(output, path)
+		}
+		if err != nil {
+			return err
+		}
+		return filepath.Walk(path, func(path string, info fs.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				bytesWritten += info.Size()
+			}
+			return err
+		})
+	}
+	path, err := stagingFile("bigseqkit-stdout-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	if err = bigseqkit.StoreFASTX(output, path); err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(os.Stdout, f)
+	bytesWritten += n
+	return err
+}
+
+// runNode runs a command in a node of a pipe, the input frames of the node are followed by the input files
+// of its arguments.
+func runNode(cmd *cobra.Command, args []string, input []*api.IDataFrame[string]) (*api.IDataFrame[string], func() error, error) {
+	runner, ok := runners[cmd.Name()]
+	if !ok {
+		return nil, nil, bigseqkit.OptionErrorf("command %s can not be run in a pipe", cmd.Name())
+	}
+	files, err := readSeqs(cmd, args, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, report, err := runner(append(input, files...), cmd, args, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if output != nil && getFlagInt(cmd, "partitions") > 0 {
+		if output, err = output.Repartition(int64(getFlagInt(cmd, "partitions")), true, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	return output, report, nil
+}
+
+// ignisDriver runs a command in a job, Ignis is stopped and the staging files are removed on return.
+func ignisDriver(cmd *cobra.Command, args []string, f cmdRunner) error {
+	stagingDir = getFlagString(cmd, "tmp-dir")
+	if err := api.Ignis.Start(); err != nil {
+		return err
+	}
 	defer api.Ignis.Stop()
+	defer func() {
+		if stdinFile != "" {
+			os.Remove(stdinFile)
+		}
+		if metricsDir != "" {
+			os.RemoveAll(metricsDir)
+		}
+	}()
 
-	cluster := check(api.NewIClusterDefault())
-	jobWorker = check(api.NewIWorkerDefault(cluster, "go"))
+	cluster, err := api.NewIClusterDefault()
+	if err != nil {
+		return err
+	}
+	if jobWorker, err = api.NewIWorkerDefault(cluster, "go"); err != nil {
+		return err
+	}
 
-	output := f(readSeqs(cmd, args, false), cmd, args, false)
-	if getFlagInt(cmd, "partitions") > 0 {
-		output = check(output.Repartition(int64(getFlagInt(cmd, "partitions")), true, false))
+	metrics := startMetrics(cmd)
+	progress := startProgress(cmd)
+	defer progress.stop()
+	bigseqkit.MetricsStage("read")
+	input, err := readSeqs(cmd, args, false)
+	if err != nil {
+		return err
+	}
+	bigseqkit.MetricsStage(cmd.Name())
+	output, report, err := f(input, cmd, args, false)
+	if err != nil {
+		return err
 	}
+	bigseqkit.MetricsStage("store")
+	if output != nil && getFlagInt(cmd, "partitions") > 0 {
+		if output, err = output.Repartition(int64(getFlagInt(cmd, "partitions")), true, false); err != nil {
+			return err
+		}
+	}
+	out := getFlagString(cmd, "out-file")
 	if output != nil {
-		out := getFlagString(cmd, "out-file")
-
 		if out == "" {
-			files := getFileListFromArgsAndFile(cmd, args, false, "infile-list", false)
+			files, err := getFileListFromArgsAndFile(cmd, args, false, "infile-list", false)
+			if err != nil {
+				return err
+			}
 			job := os.Getenv("IGNIS_JOB_NAME")
-			if len(files) == 1 {
+			if len(files) == 1 && files[0] == stdio {
+				out = stdio
+			} else if len(files) == 1 {
 				out = files[0] + "-out"
-			} else if len(job) > 0{
+			} else if len(job) > 0 {
 				out = job + "-out"
 			} else {
-				checkError(fmt.Errorf("out file -o required"))
+				return bigseqkit.OptionErrorf("out file -o required")
 			}
 		}
+		if out == stdio {
+			reportOut = os.Stderr
+		}
 
-		if getFlagBool(cmd, "merge") {
-			checkError(bigseqkit.StoreFASTX(output, out))
-		} else {
-			checkError(bigseqkit.StoreFASTXN(output, out))
+		if err = storeFASTX(output, out, getFlagBool(cmd, "merge")); err != nil {
+			return err
 		}
 	}
-	if fOuput != nil {
-		fOuput()
+	if report != nil {
+		if err = report(); err != nil {
+			return err
+		}
+	}
+	progress.stop()
+	// the output is stored, the job does not fail for the metrics
+	if err = metrics.write(out); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] metrics not written: %s\n", err)
 	}
+	return nil
 }
 
-func union(cmd *cobra.Command, input ...*api.IDataFrame[string]) *api.IDataFrame[string] {
+func union(cmd *cobra.Command, input ...*api.IDataFrame[string]) (*api.IDataFrame[string], error) {
 	result := input[0]
 	order := getFlagBool(cmd, "order")
 	for i := 1; i < len(input); i++ {
-		result = check(result.Union(input[i], order, nil))
+		var err error
+		if result, err = result.Union(input[i], order, nil); err != nil {
+			return nil, err
+		}
 	}
-	return result
+	return result, nil
+}
+
+// unionEach applies a function to every input and joins the results.
+func unionEach(cmd *cobra.Command, input []*api.IDataFrame[string],
+	f func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error)) (*api.IDataFrame[string], error) {
+	results := make([]*api.IDataFrame[string], len(input))
+	for i := range input {
+		var err error
+		if results[i], err = f(input[i]); err != nil {
+			return nil, err
+		}
+	}
+	return union(cmd, results...)
 }
 
 func parseSeqKitConfig(cmd *cobra.Command) *bigseqkit.SeqKitConfig {
 	return (&bigseqkit.SeqKitConfig{}).
 		SeqType(getFlagString(cmd, "seq-type")).
-		LineWidth(getFlagNonNegativeInt(cmd, "line-width")).
+		LineWidth(getFlagInt(cmd, "line-width")).
 		IDRegexp(getIDRegexp(cmd, "id-regexp")).
 		IDNCBI(getFlagBool(cmd, "id-ncbi")).
 		Quiet(getFlagBool(cmd, "quiet")).
-		AlphabetGuessSeqLength(getFlagAlphabetGuessSeqLength(cmd, "alphabet-guess-seq-length"))
+		AlphabetGuessSeqLength(getFlagInt(cmd, "alphabet-guess-seq-length"))
 }
 
 func Parser() *cobra.Command {
@@ -163,14 +389,31 @@ func Parser() *cobra.Command {
 	cmd.PersistentFlags().IntP("line-width", "w", 60, "line width when outputting FASTA format (0 for no wrap)")
 	cmd.PersistentFlags().StringP("id-regexp", "", fastx.DefaultIDRegexp, "regular expression for parsing ID")
 	cmd.PersistentFlags().BoolP("id-ncbi", "", false, "FASTA head is NCBI-style, e.g. >gi|110645304|ref|NC_002516.2| Pseud...")
-	cmd.PersistentFlags().StringP("out-file", "o", "", `out file`)
+	cmd.PersistentFlags().StringP("out-file", "o", "", `out file ("-" for stdout, merged in order, the reports are then printed to stderr)`)
 	cmd.PersistentFlags().BoolP("quiet", "", false, "be quiet and do not show extra information")
 	cmd.PersistentFlags().IntP("alphabet-guess-seq-length", "", 10000, "length of sequence prefix of the first FASTA record based on which seqkit guesses the sequence type (0 for whole seq)")
 	cmd.PersistentFlags().StringP("infile-list", "", "", "file of input files list (one file per line), if given, they are appended to files from cli arguments")
+	cmd.PersistentFlags().StringP("tmp-dir", "", "", "directory shared with the executors where stdin (\"-\") and stdout (-o -) are staged (default current directory)")
+	cmd.PersistentFlags().StringP("metrics-json", "", "", "write the run metadata and the records in, out and dropped by every stage to a JSON file")
+	cmd.PersistentFlags().DurationP("progress-interval", "", time.Minute, "interval of the progress log lines when stderr is not a terminal, a terminal is updated every second (0 disables the progress, as --quiet)")
 
 	cmd.PersistentFlags().BoolP("merge", "", false, "store all results in a single file. (default false, faster)")
 	cmd.PersistentFlags().IntP("partitions", "", 0, "set number of partitions to store the output (0 is auto)")
 	cmd.PersistentFlags().BoolP("order", "", false, "preserve the order of the sequences when there is more than one input file. (default false, faster)")
+	cmd.PersistentFlags().BoolP("debug", "", false, "show the wrapped causes of errors and the stack trace of bugs")
+
+	// flags not given are read from the config files and environment variables, see the config command
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		commandStarted = true
+		return applyDefaults(cmd)
+	}
+
+	// errors are printed by main, flag errors are usage errors
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return bigseqkit.OptionErrorf("%s, see '%s --help'", err, c.CommandPath())
+	})
 
 	cmd.CompletionOptions.DisableDefaultCmd = true
 	cmd.SetHelpCommand(&cobra.Command{Hidden: true})
@@ -182,26 +425,24 @@ func Parser() *cobra.Command {
 	return cmd
 }
 
-func getFileList(args []string, checkFile bool) []string {
-	files := make([]string, 0, 1000)
+func getFileList(args []string, checkFile bool) ([]string, error) {
 	for _, file := range args {
 
-		if !checkFile {
+		if !checkFile || file == stdio {
 			continue
 		}
 		if _, err := os.Stat(file); os.IsNotExist(err) {
-			checkError(err)
+			return nil, err
 		}
 	}
-	files = args
 
-	return files
+	return args, nil
 }
 
 func getFileListFromFile(file string, checkFile bool) ([]string, error) {
 	fh, err := os.Open(file)
 	if err != nil {
-		return nil, fmt.Errorf("read file list from '%s': %s", file, err)
+		return nil, fmt.Errorf("read file list from '%s': %w", file, err)
 	}
 
 	var _file string
@@ -214,84 +455,77 @@ func getFileListFromFile(file string, checkFile bool) ([]string, error) {
 		}
 		if checkFile {
 			if _, err = os.Stat(_file); os.IsNotExist(err) {
-				return lists, fmt.Errorf("check file '%s': %s", _file, err)
+				return lists, fmt.Errorf("check file '%s': %w", _file, err)
 			}
 		}
 		lists = append(lists, _file)
 	}
 	if err = scanner.Err(); err != nil {
-		return nil, fmt.Errorf("read file list from '%s': %s", file, err)
+		return nil, fmt.Errorf("read file list from '%s': %w", file, err)
 	}
 
 	return lists, nil
 }
 
-func getFileListFromArgsAndFile(cmd *cobra.Command, args []string, checkFileFromArgs bool, flag string, checkFileFromFile bool) []string {
+func getFileListFromArgsAndFile(cmd *cobra.Command, args []string, checkFileFromArgs bool, flag string, checkFileFromFile bool) ([]string, error) {
 	infileList := getFlagString(cmd, flag)
-	files := getFileList(args, checkFileFromArgs)
+	files, err := getFileList(args, checkFileFromArgs)
+	if err != nil {
+		return nil, err
+	}
 	if infileList != "" {
 		_files, err := getFileListFromFile(infileList, checkFileFromFile)
-		checkError(err)
+		if err != nil {
+			return nil, err
+		}
 		if len(_files) == 0 {
-			return files
+			return files, nil
 		}
 
 		files = append(files, _files...)
 	}
-	return files
+	return files, nil
 }
 
 func getFlagInt(cmd *cobra.Command, flag string) int {
 	value, err := cmd.Flags().GetInt(flag)
-	checkError(err)
+	checkFlag(err)
 	return value
 }
 
-func getFlagPositiveInt(cmd *cobra.Command, flag string) int {
-	value, err := cmd.Flags().GetInt(flag)
-	checkError(err)
-	if value <= 0 {
-		checkError(fmt.Errorf("value of flag --%s should be greater than 0", flag))
-	}
-	return value
-}
-
-func getFlagNonNegativeInt(cmd *cobra.Command, flag string) int {
-	value, err := cmd.Flags().GetInt(flag)
-	checkError(err)
-	if value < 0 {
-		checkError(fmt.Errorf("value of flag --%s should be greater than 0", flag))
-	}
+func getFlagDuration(cmd *cobra.Command, flag string) time.Duration {
+	value, err := cmd.Flags().GetDuration(flag)
+	checkFlag(err)
 	return value
 }
 
 func getFlagBool(cmd *cobra.Command, flag string) bool {
 	value, err := cmd.Flags().GetBool(flag)
-	checkError(err)
+	checkFlag(err)
 	return value
 }
 
 func getFlagString(cmd *cobra.Command, flag string) string {
 	value, err := cmd.Flags().GetString(flag)
-	checkError(err)
+	checkFlag(err)
 	return value
 }
 
 func getFlagFloat64(cmd *cobra.Command, flag string) float64 {
 	value, err := cmd.Flags().GetFloat64(flag)
-	checkError(err)
+	checkFlag(err)
 	return value
 }
 
 func getFlagInt64(cmd *cobra.Command, flag string) int64 {
 	value, err := cmd.Flags().GetInt64(flag)
-	checkError(err)
+	checkFlag(err)
 	return value
 }
 
 func getFlagStringSlice(cmd *cobra.Command, flag string) []string {
 	value, err := cmd.Flags().GetStringSlice(flag)
-	checkError(err)
+	checkFlag(err)
 	return value
 }
 
@@ -308,41 +542,24 @@ func getIDRegexp(cmd *cobra.Command, flag string) string {
 	return idRegexp
 }
 
-func getAlphabet(cmd *cobra.Command, flag string) *seq.Alphabet {
+func getAlphabet(cmd *cobra.Command, flag string) (*seq.Alphabet, error) {
 	value, err := cmd.Flags().GetString(flag)
-	checkError(err)
+	checkFlag(err)
 
 	switch strings.ToLower(value) {
 	case "dna":
-		return seq.DNAredundant
+		return seq.DNAredundant, nil
 	case "rna":
-		return seq.RNAredundant
+		return seq.RNAredundant, nil
 	case "protein":
-		return seq.Protein
+		return seq.Protein, nil
 	case "unlimit":
-		return seq.Unlimit
+		return seq.Unlimit, nil
 	case "auto":
-		return nil
+		return nil, nil
 	default:
-		checkError(fmt.Errorf("invalid sequence type: %s, available value: dna|rna|protein|unlimit|auto", value))
-		return nil
-	}
-}
-
-func getFlagAlphabetGuessSeqLength(cmd *cobra.Command, flag string) int {
-	alphabetGuessSeqLength := getFlagNonNegativeInt(cmd, flag)
-	if alphabetGuessSeqLength > 0 && alphabetGuessSeqLength < 1000 {
-		checkError(fmt.Errorf("value of flag --%s too small, should >= 1000", flag))
-	}
-	return alphabetGuessSeqLength
-}
-
-func getFlagValidateSeqLength(cmd *cobra.Command, flag string) int {
-	validateSeqLength := getFlagNonNegativeInt(cmd, flag)
-	if validateSeqLength > 0 && validateSeqLength < 1000 {
-		checkError(fmt.Errorf("value of flag --%s too small, should >= 1000", flag))
+		return nil, bigseqkit.OptionErrorf("invalid sequence type: %s, available value: dna|rna|protein|unlimit|auto", value)
 	}
-	return validateSeqLength
 }
 
 var regionExample = `
@@ -6,9 +6,14 @@ import (
 	"ignis/driver/api"
 )
 
-func runLocate(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runLocate(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitLocateOptions(cmd)
-	return check(bigseqkit.Locate(union(cmd, input...), opts))
+	records, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := bigseqkit.Locate(records, opts)
+	return output, nil, err
 }
 
 func parseSeqKitLocateOptions(cmd *cobra.Command) *bigseqkit.SeqKitLocateOptions {
@@ -24,7 +29,7 @@ func parseSeqKitLocateOptions(cmd *cobra.Command) *bigseqkit.SeqKitLocateOptions
 		NonGreedy(getFlagBool(cmd, "non-greedy")).
 		Gtf(getFlagBool(cmd, "gtf")).
 		Bed(getFlagBool(cmd, "bed")).
-		MaxMismatch(getFlagNonNegativeInt(cmd, "max-mismatch")).
+		MaxMismatch(getFlagInt(cmd, "max-mismatch")).
 		HideMatched(getFlagBool(cmd, "hide-matched")).
 		Circular(getFlagBool(cmd, "circular"))
 }
@@ -52,11 +57,15 @@ Attentions:
   5. When using flag --circular, end position of matched subsequence that 
      crossing genome sequence end would be greater than sequence length.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runLocate)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitLocateOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runLocate)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runLocate)
 
 		cmd.Flags().StringSliceP("pattern", "p", []string{""}, `pattern/motif (multiple values supported. Attention: use double quotation marks for patterns containing comma, e.g., -p '"A{2,}"')`)
 		cmd.Flags().StringP("pattern-file", "f", "", "pattern/motif file (FASTA format)")
@@ -1,5 +1,63 @@
 package main
 
+import (
+	"bigseqkit"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime/debug"
+)
+
+// exit codes by error class
+const (
+	exitRuntime = 1 // errors of the jobs
+	exitUsage   = 2 // invalid flags, options and job definitions
+	exitInput   = 3 // missing or unreadable input files
+)
+
 func main() {
-	checkError(Parser().Execute())
+	parser := Parser()
+	// the commands return their errors, a panic is a bug and is reported in one line, with its stack trace
+	// when --debug is given
+	defer func() {
+		if r := recover(); r != nil {
+			if showStack, _ := parser.PersistentFlags().GetBool("debug"); showStack {
+				fmt.Fprintf(os.Stderr, "[ERRO] panic: %v\n", r)
+				os.Stderr.Write(debug.Stack())
+			} else {
+				fmt.Fprintf(os.Stderr, "[ERRO] panic: %v (a bug, rerun with --debug for the stack trace)\n", r)
+			}
+			os.Exit(exitRuntime)
+		}
+	}()
+	if err := parser.Execute(); err != nil {
+		if !commandStarted {
+			// unknown commands and invalid arguments
+			err = bigseqkit.OptionErrorf("%s", err)
+		}
+		showCauses, _ := parser.PersistentFlags().GetBool("debug")
+		os.Exit(exitError(err, showCauses))
+	}
+}
+
+// exitError prints the one-line message of an error and returns the exit code of its class. showCauses
+// prints the chain of wrapped errors too.
+func exitError(err error, showCauses bool) int {
+	fmt.Fprintf(os.Stderr, "[ERRO] %s\n", err)
+	if showCauses {
+		for e := err; e != nil; e = errors.Unwrap(e) {
+			fmt.Fprintf(os.Stderr, "  %T: %s\n", e, e)
+		}
+	}
+
+	var optionError *bigseqkit.OptionError
+	var pathError *fs.PathError
+	if errors.As(err, &optionError) {
+		return exitUsage
+	}
+	if errors.As(err, &pathError) || errors.Is(err, fs.ErrNotExist) {
+		return exitInput
+	}
+	return exitRuntime
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bigseqkit"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+)
+
+func runMask(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitMaskOptions(cmd)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Mask(input, opts)
+	})
+	return output, nil, err
+}
+
+func parseSeqKitMaskOptions(cmd *cobra.Command) *bigseqkit.SeqKitMaskOptions {
+	return (&bigseqkit.SeqKitMaskOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		Method(getFlagString(cmd, "method")).
+		Window(getFlagInt(cmd, "window")).
+		DustLevel(getFlagFloat64(cmd, "dust-level")).
+		MinEntropy(getFlagFloat64(cmd, "min-entropy")).
+		Mode(getFlagString(cmd, "mode")).
+		MaxMaskedFrac(getFlagFloat64(cmd, "max-masked-frac")).
+		Bed(getFlagBool(cmd, "bed"))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "mask",
+			Short: "mask or filter low-complexity regions (DUST/entropy)",
+			Long: `mask or filter low-complexity regions (DUST/entropy)
+
+Attentions:
+  1. Two methods (-M/--method) of nucleotide sequences are available:
+     dust     windows (-W/--window) are moved by half of their size and the
+              sub-interval with the highest DUST score of every window is
+              low-complexity if its score is over --dust-level. The score
+              of l trinucleotides is 10 * sum(c_t * (c_t - 1) / 2) / (l - 1).
+     entropy  windows are moved base by base, and whole windows are
+              low-complexity if the Shannon entropy of their trinucleotides,
+              normalized to [0, 1], is under --min-entropy.
+  2. Low-complexity regions are soft-masked (lower case) by default,
+     --mode hard replaces them with N, and --mode none keeps the sequences.
+  3. Sequences with a fraction of low-complexity bases greater than
+     --max-masked-frac are dropped.
+  4. -B/--bed outputs the low-complexity regions of all sequences in BED
+     format instead of the sequences.
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitMaskOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runMask)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runMask)
+
+		cmd.Flags().StringP("method", "M", "dust", "low-complexity method: dust, entropy")
+		cmd.Flags().IntP("window", "W", 64, "window size")
+		cmd.Flags().Float64P("dust-level", "", 20, "DUST score over which a region is low-complexity")
+		cmd.Flags().Float64P("min-entropy", "", 0.5, "normalized entropy under which a window is low-complexity")
+		cmd.Flags().StringP("mode", "", "soft", "mask mode: soft (lower case), hard (N), none")
+		cmd.Flags().Float64P("max-masked-frac", "", 1, "drop sequences with a greater fraction of low-complexity bases")
+		cmd.Flags().BoolP("bed", "B", false, "output low-complexity regions in BED format")
+	})
+}
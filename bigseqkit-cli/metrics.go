@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bigseqkit"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+// runMetrics is the content of the --metrics-json file. The stages are the executor functions with counters
+// grouped by the phase that created them: "read", the command (or every pipe node) and "store". The
+// functions of a stage recomputed by a later action are counted again.
+type runMetrics struct {
+	Command      string                   `json:"command"`
+	Args         []string                 `json:"args"`
+	Start        time.Time                `json:"start"`
+	WallTime     float64                  `json:"wall_time"`
+	Output       string                   `json:"output,omitempty"`
+	BytesWritten int64                    `json:"bytes_written"`
+	Stages       []bigseqkit.StageMetrics `json:"stages"`
+
+	file string
+}
+
+// metricsDir is the directory where the executors save their counters, shared by --metrics-json and the
+// progress.
+var metricsDir string
+
+// createMetricsDir creates the metrics directory in the staging directory, once.
+func createMetricsDir() error {
+	if metricsDir == "" {
+		dir, err := staging()
+		if err != nil {
+			return err
+		}
+		if metricsDir, err = os.MkdirTemp(dir, "bigseqkit-metrics-*"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startMetrics enables the metrics of the executors if --metrics-json is set, or returns nil. The metrics are
+// informative, they are disabled with a warning if the metrics directory can not be created.
+func startMetrics(cmd *cobra.Command) *runMetrics {
+	file := getFlagString(cmd, "metrics-json")
+	if file == "" {
+		return nil
+	}
+	if err := createMetricsDir(); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] metrics disabled: %s\n", err)
+		return nil
+	}
+	bigseqkit.EnableMetrics(metricsDir)
+	return &runMetrics{
+		Command: cmd.CommandPath(),
+		Args:    os.Args[1:],
+		Start:   time.Now(),
+		file:    file,
+	}
+}
+
+// write collects the counters of the executors and writes the metrics file.
+func (this *runMetrics) write(output string) error {
+	if this == nil {
+		return nil
+	}
+	this.WallTime = time.Since(this.Start).Seconds()
+	this.Output = output
+	this.BytesWritten = bytesWritten
+	stages, err := bigseqkit.CollectMetrics()
+	if err != nil {
+		return err
+	}
+	this.Stages = stages
+	data, err := json.MarshalIndent(this, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(this.file, append(data, '\n'), 0644)
+}
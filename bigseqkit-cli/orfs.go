@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bigseqkit"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+)
+
+func runOrfs(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitOrfsOptions(cmd)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Orfs(input, opts)
+	})
+	return output, nil, err
+}
+
+func parseSeqKitOrfsOptions(cmd *cobra.Command) *bigseqkit.SeqKitOrfsOptions {
+	return (&bigseqkit.SeqKitOrfsOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		TranslTable(getFlagInt(cmd, "transl-table")).
+		Frame(getFlagStringSlice(cmd, "frame")).
+		MinLen(getFlagInt(cmd, "min-len")).
+		Start(getFlagString(cmd, "start")).
+		Nested(getFlagBool(cmd, "nested")).
+		OutFormat(getFlagString(cmd, "out-format"))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "orfs",
+			Short: "find open reading frames (ORFs) of DNA/RNA sequences",
+			Long: `find open reading frames (ORFs) of DNA/RNA sequences
+
+Attentions:
+  1. ORFs end with a stop codon of the translate table, ORFs without stop
+     codon at the end of the sequence are not reported.
+  2. Start codons (-s/--start):
+       atg   ATG only
+       alt   initiation codons of the translate table, including the
+             alternative ones, e.g., CTG and TTG for table 1
+       none  ORFs span from stop codon to stop codon
+  3. By default only the longest ORF of every stop codon is reported,
+     switch on --nested for an ORF for every in-frame start codon.
+  4. The minimum length is in nucleotides, the stop codon is not counted.
+  5. Coordinates are on the source record, ORFs are named
+     "ID_start-end:strand" with 1-based start. Output formats
+     (--out-format): nucl, protein (FASTA), bed (BED6) and gtf (CDS).
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitOrfsOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runOrfs)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runOrfs)
+
+		cmd.Flags().IntP("transl-table", "T", 1, `translate table/genetic code, type 'seqkit translate --help' for more details`)
+		cmd.Flags().StringSliceP("frame", "f", []string{"6"}, "frame(s) to search, available value: 1, 2, 3, -1, -2, -3, and 6 for all six frames")
+		cmd.Flags().IntP("min-len", "m", 90, "minimum ORF length in nucleotides (stop codon excluded)")
+		cmd.Flags().StringP("start", "s", "atg", "start codons (atg|alt|none)")
+		cmd.Flags().BoolP("nested", "", false, "report an ORF for every start codon instead of the longest one of every stop codon")
+		cmd.Flags().StringP("out-format", "", "nucl", "output format (nucl|protein|bed|gtf)")
+	})
+}
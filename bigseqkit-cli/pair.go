@@ -2,85 +2,104 @@ package main
 
 import (
 	"bigseqkit"
-	"fmt"
 	"github.com/shenwei356/util/pathutil"
 	"github.com/spf13/cobra"
 	"ignis/driver/api"
+	"ignis/executor/api/ipair"
 	"os"
 	"path/filepath"
 )
 
-func runPair(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runPair(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	if len(input) < 2 {
-		checkError(fmt.Errorf("2 files needed"))
+		return nil, nil, bigseqkit.OptionErrorf("2 files needed")
 	}
 	opts := parseSeqKitPairOptions(cmd)
 
 	outdir := getFlagString(cmd, "out-dir")
 	if outdir == "" {
-		checkError(fmt.Errorf("out-dir required"))
+		return nil, nil, bigseqkit.OptionErrorf("out-dir required")
 	}
 
 	if outdir != "./" && outdir != "." {
 		existed, err := pathutil.DirExists(outdir)
-		checkError(err)
+		if err != nil {
+			return nil, nil, err
+		}
 		if existed {
 			empty, err := pathutil.IsEmpty(outdir)
-			checkError(err)
+			if err != nil {
+				return nil, nil, err
+			}
 			if !empty {
-				if getFlagBool(cmd, "force") {
-					checkError(os.RemoveAll(outdir))
-					checkError(os.MkdirAll(outdir, 0755))
-				} else {
-					checkError(fmt.Errorf("outdir not empty: %s, you can use --force to overwrite", outdir))
+				if !getFlagBool(cmd, "force") {
+					return nil, nil, bigseqkit.OptionErrorf("outdir not empty: %s, you can use --force to overwrite", outdir)
+				}
+				if err = os.RemoveAll(outdir); err != nil {
+					return nil, nil, err
+				}
+				if err = os.MkdirAll(outdir, 0755); err != nil {
+					return nil, nil, err
 				}
 			}
-		} else {
-			checkError(os.MkdirAll(outdir, 0755))
+		} else if err = os.MkdirAll(outdir, 0755); err != nil {
+			return nil, nil, err
 		}
 	}
 
 	pair, unpaired, cache, err := bigseqkit.Pair(input[0], input[1], opts)
-	checkError(err)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	fOuput = func() {
-		checkError(cache.Cache())
-		defer cache.Cache()
-		checkError(pair.Cache())
+	report := func() error {
+		if err := cache.Cache(); err != nil {
+			return err
+		}
+		defer cache.Uncache()
+		if err := pair.Cache(); err != nil {
+			return err
+		}
 		defer pair.Uncache()
 
-		pair1 := check(bigseqkit.PairIndex(pair, 0))
-		pair2 := check(bigseqkit.PairIndex(pair, 1))
-
-		check(0, pair1.SaveAsTextFile(getFlagString(cmd, filepath.Join(outdir, "paired.1"))))
-		check(0, pair2.SaveAsTextFile(getFlagString(cmd, filepath.Join(outdir, "paired.2"))))
+		if err := savePairIndex(pair, 0, filepath.Join(outdir, "paired.1")); err != nil {
+			return err
+		}
+		if err := savePairIndex(pair, 1, filepath.Join(outdir, "paired.2")); err != nil {
+			return err
+		}
 
 		if getFlagBool(cmd, "save-unpaired") {
-			checkError(unpaired.Cache())
+			if err := unpaired.Cache(); err != nil {
+				return err
+			}
 			defer unpaired.Uncache()
-			unpaired1 := check(bigseqkit.UnpairedId(pair, "1"))
-			unpaired2 := check(bigseqkit.UnpairedId(pair, "2"))
-			check(0, unpaired1.SaveAsTextFile(getFlagString(cmd, filepath.Join(outdir, "unpaired.1"))))
-			check(0, unpaired2.SaveAsTextFile(getFlagString(cmd, filepath.Join(outdir, "unpaired.2"))))
+			for _, id := range []string{"1", "2"} {
+				reads, err := bigseqkit.UnpairedId(unpaired, id)
+				if err != nil {
+					return err
+				}
+				if err = bigseqkit.StoreFASTXN(reads, filepath.Join(outdir, "unpaired."+id)); err != nil {
+					return err
+				}
+			}
 		}
+		return nil
 	}
 
-	return nil
+	return nil, report, nil
 }
 
-func parseSeqKitPairOptions(cmd *cobra.Command) *bigseqkit.SeqKitPairOptions {
-	if getFlagString(cmd, "read1") != "" {
-		checkError(fmt.Errorf("Don't use  --read1"))
-	}
-
-	if getFlagString(cmd, "read2") != "" {
-		checkError(fmt.Errorf("Don't use  --read2"))
-	}
-
-	if getFlagString(cmd, "out-dir") != "" {
-		checkError(fmt.Errorf("Don't use  --out-dir"))
+// savePairIndex saves the reads of a side of the pairs.
+func savePairIndex(pair *api.IDataFrame[ipair.IPair[string, string]], index int, path string) error {
+	reads, err := bigseqkit.PairIndex(pair, index)
+	if err != nil {
+		return err
 	}
+	return bigseqkit.StoreFASTXN(reads, path)
+}
 
+func parseSeqKitPairOptions(cmd *cobra.Command) *bigseqkit.SeqKitPairOptions {
 	return (&bigseqkit.SeqKitPairOptions{}).
 		Config(parseSeqKitConfig(cmd)).
 		SaveUnpaired(getFlagBool(cmd, "save-unpaired"))
@@ -103,11 +122,21 @@ Attentions:
 4. Paired gzipped files may be slightly larger than original files, because
    of using a different gzip package/library, don't worry.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runPair)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				if getFlagString(cmd, "read1") != "" {
+					return bigseqkit.OptionErrorf("Don't use  --read1")
+				}
+				if getFlagString(cmd, "read2") != "" {
+					return bigseqkit.OptionErrorf("Don't use  --read2")
+				}
+				return parseSeqKitPairOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runPair)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runPair)
 
 		cmd.Flags().StringP("read1", "1", "", "(gzipped) read1 file. (DEPRECATED use normal input with 2 files)")
 		cmd.Flags().StringP("read2", "2", "", "(gzipped) read2 file. (DEPRECATED use normal input with 2 files)")
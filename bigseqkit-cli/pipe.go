@@ -1,69 +1,483 @@
 package main
 
 import (
+	"bigseqkit"
 	"encoding/json"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
 	"ignis/driver/api"
 	"io/ioutil"
+	"os"
 	"os/exec"
+	"strings"
 )
 
+// cmdPipe is the former job format, a tree in which every command depends on the commands of Pipe. It is
+// converted into a pipeline.
 type cmdPipe struct {
-	Pipe []*cmdPipe `json:"pipe"`
-	Cmd  []string   `json:"cmd"`
-	sh   *string    `json:"sh"`
+	Pipe []*cmdPipe `json:"pipe" yaml:"pipe"`
+	Cmd  []string   `json:"cmd" yaml:"cmd"`
+	Sh   string     `json:"sh" yaml:"sh"`
 }
 
-func runCmd(c *cmdPipe) {
-	jobInput = make([]*api.IDataFrame[string], 0)
-	jobOuput = nil
-	fOuput = nil
-	result := make([]*api.IDataFrame[string], 0)
-	if c.Pipe != nil {
-		for _, dep := range c.Pipe {
-			runCmd(dep)
-			if jobOuput == nil {
-				checkError(fmt.Errorf("bad execution dependency"))
+// pipeNode is a named command of a pipeline, its input is the union of the outputs of Inputs ("-" for the
+// input files of the pipe command) and the files of Cmd.
+type pipeNode struct {
+	Name       string   `json:"name" yaml:"name"`
+	Cmd        []string `json:"cmd" yaml:"cmd"`
+	Inputs     []string `json:"inputs" yaml:"inputs"`
+	Sh         string   `json:"sh" yaml:"sh"`
+	Output     string   `json:"output" yaml:"output"`
+	Format     string   `json:"format" yaml:"format"`
+	Partitions int      `json:"partitions" yaml:"partitions"`
+	Checkpoint bool     `json:"checkpoint" yaml:"checkpoint"`
+
+	consumers int
+	cache     bool
+}
+
+type pipeline struct {
+	Nodes   []*pipeNode `json:"nodes" yaml:"nodes"`
+	WorkDir string      `json:"workdir" yaml:"workdir"`
+	// former job format
+	Pipe []*cmdPipe `json:"pipe" yaml:"pipe"`
+	Cmd  []string   `json:"cmd" yaml:"cmd"`
+	Sh   string     `json:"sh" yaml:"sh"`
+
+	legacy bool
+	order  []*pipeNode
+}
+
+func (c *cmdPipe) toNodes(p *pipeline) string {
+	node := &pipeNode{Cmd: c.Cmd, Sh: c.Sh}
+	for _, dep := range c.Pipe {
+		node.Inputs = append(node.Inputs, dep.toNodes(p))
+	}
+	node.Name = fmt.Sprintf("node%d", len(p.Nodes)+1)
+	p.Nodes = append(p.Nodes, node)
+	return node.Name
+}
+
+func readPipeline(file string) (*pipeline, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	p := &pipeline{}
+	if extension(file, []string{".yaml", ".yml"}) {
+		err = yaml.Unmarshal(data, p)
+	} else {
+		err = json.Unmarshal(data, p)
+	}
+	if err != nil {
+		return nil, bigseqkit.OptionErrorf("incorrect job format: %s", err)
+	}
+
+	if len(p.Nodes) == 0 && len(p.Cmd) > 0 {
+		(&cmdPipe{Pipe: p.Pipe, Cmd: p.Cmd, Sh: p.Sh}).toNodes(p)
+		p.legacy = true
+	}
+	if len(p.Nodes) == 0 {
+		return nil, bigseqkit.OptionErrorf("no nodes found in job: %s", file)
+	}
+	return p, p.resolve()
+}
+
+// resolve checks the nodes and sorts them in execution order, nodes with several consumers or consumed
+// and stored are cached.
+func (p *pipeline) resolve() error {
+	nodes := make(map[string]*pipeNode, len(p.Nodes))
+	for _, node := range p.Nodes {
+		if node.Name == "" || node.Name == "-" {
+			return bigseqkit.OptionErrorf("invalid node name: '%s'", node.Name)
+		}
+		if _, ok := nodes[node.Name]; ok {
+			return bigseqkit.OptionErrorf("duplicated node: %s", node.Name)
+		}
+		if len(node.Cmd) == 0 {
+			return bigseqkit.OptionErrorf("node %s: cmd needed", node.Name)
+		}
+		if node.Format != "" && node.Format != "partitions" && node.Format != "merged" {
+			return bigseqkit.OptionErrorf("node %s: invalid format: %s, available: partitions, merged", node.Name, node.Format)
+		}
+		if node.Partitions < 0 {
+			return bigseqkit.OptionErrorf("node %s: partitions should not be negative", node.Name)
+		}
+		nodes[node.Name] = node
+	}
+	for _, node := range p.Nodes {
+		for _, in := range node.Inputs {
+			if in == "-" {
+				continue
+			}
+			dep, ok := nodes[in]
+			if !ok {
+				return bigseqkit.OptionErrorf("node %s: unknown input: %s", node.Name, in)
 			}
-			result = append(result, jobOuput)
+			dep.consumers++
 		}
-		jobInput = result
 	}
 
-	if c.sh != nil && *c.sh != " " {
-		check(exec.Command("sh", "-c", *c.sh).Output())
+	const (
+		pending = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(p.Nodes))
+	var visit func(node *pipeNode) error
+	visit = func(node *pipeNode) error {
+		switch state[node.Name] {
+		case visiting:
+			return bigseqkit.OptionErrorf("cycle found in job at node: %s", node.Name)
+		case done:
+			return nil
+		}
+		state[node.Name] = visiting
+		for _, in := range node.Inputs {
+			if in != "-" {
+				if err := visit(nodes[in]); err != nil {
+					return err
+				}
+			}
+		}
+		state[node.Name] = done
+		p.order = append(p.order, node)
+		return nil
 	}
+	for _, node := range p.Nodes {
+		if err := visit(node); err != nil {
+			return err
+		}
+		node.cache = node.consumers > 1 || node.consumers > 0 && node.Output != ""
+	}
+	return nil
+}
 
-	parser := Parser()
-	parser.SetArgs(c.Cmd)
-	checkError(parser.Execute())
+// sinks returns the nodes that are neither consumed nor stored, their output is the output of the pipe.
+func (p *pipeline) sinks() []*pipeNode {
+	result := make([]*pipeNode, 0, 1)
+	for _, node := range p.order {
+		if node.consumers == 0 && node.Output == "" {
+			result = append(result, node)
+		}
+	}
+	return result
 }
 
-func runPipe(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
-	job := getFlagString(cmd, "job")
-	if job == "" {
-		checkError(fmt.Errorf("job not defined"))
+func (p *pipeline) print(files []string) error {
+	stages, err := p.stages(nil, files, nil)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for i, node := range p.order {
+		sb.WriteString(fmt.Sprintf("%d. %s: %s\n", i+1, node.Name, strings.Join(node.Cmd, " ")))
+		if len(node.Inputs) > 0 {
+			sb.WriteString(fmt.Sprintf("   inputs: %s\n", strings.Join(node.Inputs, ", ")))
+		}
+		if node.Sh != "" {
+			sb.WriteString(fmt.Sprintf("   sh: %s\n", node.Sh))
+		}
+		if node.cache {
+			sb.WriteString(fmt.Sprintf("   cached (consumers: %d)\n", node.consumers))
+		}
+		if node.Output != "" {
+			format := node.Format
+			if format == "" {
+				format = "partitions"
+			}
+			sb.WriteString(fmt.Sprintf("   output: %s (%s", node.Output, format))
+			if node.Partitions > 0 {
+				sb.WriteString(fmt.Sprintf(", %d partitions", node.Partitions))
+			}
+			sb.WriteString(")\n")
+		}
+		if node.Checkpoint {
+			resumable, err := stages.Resumable(node.Name)
+			if err != nil {
+				return err
+			}
+			if resumable {
+				sb.WriteString("   checkpoint: resumed\n")
+			} else {
+				sb.WriteString("   checkpoint: run\n")
+			}
+		}
+	}
+	names := make([]string, 0)
+	for _, node := range p.sinks() {
+		names = append(names, node.Name)
 	}
-	p := cmdPipe{}
-	if err := json.Unmarshal(check(ioutil.ReadFile(job)), &p); err != nil {
-		checkError(fmt.Errorf("incorrect job format"))
+	if len(names) > 0 {
+		sb.WriteString(fmt.Sprintf("pipe output: %s\n", strings.Join(names, ", ")))
 	}
+	_, err = fmt.Print(sb.String())
+	return err
+}
 
-	runCmd(&p)
-	if jobOuput == nil {
-		if len(input) > 0 {
-			return union(cmd, input...)
-		} else {
-			return nil
+// stages builds a stage for every node, the stages run the commands of the nodes with the outputs of their
+// inputs. The reports of the non-sequence results of the commands are appended to reports. files are the
+// input files of the pipe command.
+func (p *pipeline) stages(input []*api.IDataFrame[string], files []string, reports *[]func() error) (*bigseqkit.Pipeline, error) {
+	if p.WorkDir == "" {
+		for _, node := range p.order {
+			if node.Checkpoint {
+				return nil, bigseqkit.OptionErrorf("node %s: work directory (--work-dir) needed for checkpoints", node.Name)
+			}
+		}
+	}
+	result := bigseqkit.NewPipeline(jobWorker, p.WorkDir)
+	for _, node := range p.order {
+		node := node
+		flags, err := node.flags()
+		if err != nil {
+			return nil, err
+		}
+		stage := &bigseqkit.PipelineStage{
+			Name: node.Name,
+			Options: bigseqkit.OptionsToString(struct {
+				Cmd    []string
+				Flags  []string
+				Inputs []string
+				Sh     string
+			}{node.Cmd, flags, node.Inputs, node.Sh}),
+			Checkpoint: node.Checkpoint,
+		}
+		for _, in := range node.Inputs {
+			if in == "-" {
+				stage.Files = append(stage.Files, stagedFiles(files)...)
+			} else {
+				stage.Inputs = append(stage.Inputs, in)
+			}
+		}
+		if node.readsStdin() {
+			stage.Files = append(stage.Files, stagedFiles([]string{stdio})...)
+		}
+		for _, arg := range node.Cmd[1:] {
+			if _, err := os.Stat(arg); err == nil {
+				stage.Files = append(stage.Files, arg)
+			}
+		}
+
+		stage.Run = func(inputs []*api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+			if node.Sh != "" { // the stage prefixes the errors with the node name
+				if _, err := exec.Command("sh", "-c", node.Sh).Output(); err != nil {
+					if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+						return nil, fmt.Errorf("sh: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+					}
+					return nil, fmt.Errorf("sh: %w", err)
+				}
+			}
+			nodeInput := make([]*api.IDataFrame[string], 0)
+			for _, in := range node.Inputs {
+				if in == "-" {
+					nodeInput = append(nodeInput, input...)
+				} else {
+					nodeInput = append(nodeInput, inputs[0])
+					inputs = inputs[1:]
+				}
+			}
+
+			bigseqkit.MetricsStage(node.Name)
+			c, args, err := Parser().Find(node.Cmd)
+			if err != nil {
+				return nil, err
+			}
+			if err = c.ParseFlags(args); err != nil {
+				return nil, err
+			}
+			if err = applyDefaults(c); err != nil {
+				return nil, err
+			}
+			output, report, err := runNode(c, c.Flags().Args(), nodeInput)
+			if err != nil {
+				return nil, err
+			}
+			if report != nil {
+				*reports = append(*reports, report)
+			}
+
+			if output != nil && node.cache && !node.Checkpoint { // checkpointed outputs are always cached
+				if err := output.Cache(); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		}
+		if err := result.AddStage(stage); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// flags returns the effective value of every flag of the command of a node, the persistent flags included,
+// after the defaults of the config files and the BIGSEQKIT_* environment variables are applied. They are part
+// of the fingerprint of the checkpoints, so a checkpoint is not resumed when a default changes.
+func (node *pipeNode) flags() ([]string, error) {
+	c, args, err := Parser().Find(node.Cmd)
+	if err != nil {
+		return nil, bigseqkit.OptionErrorf("node %s: %s", node.Name, err)
+	}
+	if err = c.ParseFlags(args); err != nil {
+		return nil, bigseqkit.OptionErrorf("node %s: %s", node.Name, err)
+	}
+	if err = applyDefaults(c); err != nil {
+		return nil, err
+	}
+	flags := make([]string, 0)
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		flags = append(flags, "--"+f.Name+"="+f.Value.String())
+	})
+	return flags, nil
+}
+
+// readsStdin returns whether the command of a node reads the standard input ("-" as file).
+func (node *pipeNode) readsStdin() bool {
+	c, args, err := Parser().Find(node.Cmd)
+	if err != nil || c.ParseFlags(args) != nil {
+		return false
+	}
+	for _, arg := range c.Flags().Args() {
+		if arg == stdio {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the flags and options of the commands of the nodes, before any node is run.
+func (p *pipeline) validate() error {
+	for _, node := range p.Nodes {
+		c, args, err := Parser().Find(node.Cmd)
+		if err != nil {
+			return bigseqkit.OptionErrorf("node %s: %s", node.Name, err)
+		}
+		if err = c.ParseFlags(args); err != nil {
+			return bigseqkit.OptionErrorf("node %s: %s", node.Name, err)
+		}
+		if err = applyDefaults(c); err != nil {
+			return err
+		}
+		if c.PreRunE == nil {
+			continue
+		}
+		if err = c.PreRunE(c, c.Flags().Args()); err != nil {
+			return fmt.Errorf("node %s: %w", node.Name, err)
+		}
+	}
+	return nil
+}
+
+// run executes the stored and sink nodes, other nodes are executed when they are consumed, unless their
+// consumers are resumed from checkpoints. The outputs of the sinks are returned with the report that stores
+// the outputs of the nodes and the non-sequence results at the end of the pipe.
+func (p *pipeline) run(input []*api.IDataFrame[string], files []string) ([]*api.IDataFrame[string], func() error, error) {
+	for _, node := range p.order {
+		if node.Output == stdio {
+			reportOut = os.Stderr
+		}
+	}
+	stores := make([]func() error, 0)
+	stages, err := p.stages(input, files, &stores)
+	if err != nil {
+		return nil, nil, err
+	}
+	sinks := make([]*api.IDataFrame[string], 0, 1)
+	for _, node := range p.order {
+		if node.consumers > 0 && node.Output == "" {
+			continue
+		}
+		output, err := stages.Output(node.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if output == nil {
+			if node.Output != "" {
+				return nil, nil, fmt.Errorf("node %s: bad execution dependency, no sequences produced", node.Name)
+			}
+			continue
+		}
+		if node.Output == "" {
+			sinks = append(sinks, output)
+			continue
+		}
+
+		node := node
+		stores = append(stores, func() error {
+			if node.Partitions > 0 {
+				var err error
+				if output, err = output.Repartition(int64(node.Partitions), true, false); err != nil {
+					return err
+				}
+			}
+			return storeFASTX(output, node.Output, node.Format == "merged")
+		})
+	}
+
+	if len(stores) == 0 {
+		return sinks, nil, nil
+	}
+	return sinks, func() error {
+		for _, f := range stores {
+			if err := f(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+func runPipe(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	p, err := readPipelineFlags(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, node := range p.Nodes {
+		if node.readsStdin() {
+			// staged before the fingerprints of the checkpoints, so a node reading stdin is never resumed
+			if _, err = stageStdin(); err != nil {
+				return nil, nil, err
+			}
 		}
 	}
+	files, err := getFileListFromArgsAndFile(cmd, args, true, "infile-list", true)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, report, err := p.run(input, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.legacy {
+		output = append(output, input...)
+	}
+	if len(output) == 0 {
+		return nil, report, nil
+	}
 
-	result := make([]*api.IDataFrame[string], 0, len(input)+1)
-	result = append(result, jobOuput)
-	result = append(result, input...)
+	result, err := union(cmd, output...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, report, nil
+}
 
-	return union(cmd, result...)
+func readPipelineFlags(cmd *cobra.Command) (*pipeline, error) {
+	job := getFlagString(cmd, "job")
+	if job == "" {
+		return nil, bigseqkit.OptionErrorf("job not defined")
+	}
+	p, err := readPipeline(job)
+	if err != nil {
+		return nil, err
+	}
+	if workDir := getFlagString(cmd, "work-dir"); workDir != "" {
+		p.WorkDir = workDir
+	}
+	return p, nil
 }
 
 func init() {
@@ -71,14 +485,74 @@ func init() {
 		cmd := &cobra.Command{
 			Use:   "pipe",
 			Short: "execute multiple commands like a pipe in the same job",
-			Long:  `execute multiple commands like a pipe in the same job`,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runPipe)
+			Long: `execute multiple commands like a pipe in the same job
+
+The job (--job) is a JSON or YAML (.yaml, .yml) file with a list of named
+nodes, e.g.:
+
+  nodes:
+    - name: clean
+      cmd: [seq, -m, "50", reads.fq]
+    - name: dedup
+      cmd: [rmdup, -s]
+      inputs: [clean]
+      output: dedup.fq
+    - name: stats
+      cmd: [stats]
+      inputs: [clean, dedup]
+
+Attentions:
+  1. The input of a node is the union of the outputs of its inputs ("-" for
+     the input files of the pipe command) and the files of its cmd.
+  2. A node can feed several nodes, the outputs consumed by several nodes
+     (or consumed and stored) are cached.
+  3. Nodes with "output" are stored there, as a file per partition or in a
+     single file ("format": partitions, merged), optionally repartitioned
+     ("partitions"). The outputs of the nodes neither consumed nor stored
+     are stored in the output file of the pipe command (-o).
+  4. "sh" runs a shell command before the node.
+  5. The former job format, a tree of "cmd" and "pipe", is still accepted.
+  6. Nodes with "checkpoint: true" persist their output to the work
+     directory ("workdir" or --work-dir) with a manifest of the fingerprints
+     of their input files (path, size, mtime), command, flag values (the
+     defaults of bigseqkit.yaml and BIGSEQKIT_* included) and inputs. A rerun
+     loads the outputs of the nodes whose fingerprints have not changed,
+     without running them or the nodes they depend on.
+  7. --dry-run prints the nodes in execution order without running them,
+     and whether their checkpoints would be resumed.
+  8. A file "-" reads the standard input, it is staged once (--tmp-dir) and
+     the nodes reading it are never resumed. "output: -" writes the output
+     to the standard output, merged in order.
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				p, err := readPipelineFlags(cmd)
+				if err != nil {
+					return err
+				}
+				return p.validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if getFlagBool(cmd, "dry-run") {
+					p, err := readPipelineFlags(cmd)
+					if err != nil {
+						return err
+					}
+					files, err := getFileListFromArgsAndFile(cmd, args, true, "infile-list", true)
+					if err != nil {
+						return err
+					}
+					return p.print(files)
+				}
+				return ignisDriver(cmd, args, runPipe)
 			},
 		}
 
 		parent.AddCommand(cmd)
+		addRunner(cmd, runPipe)
 
 		cmd.Flags().String("job", "", "job definition")
+		cmd.Flags().Bool("dry-run", false, "print the resolved job without running it")
+		cmd.Flags().String("work-dir", "", "work directory of the checkpoints, overrides \"workdir\" of the job")
 	})
 }
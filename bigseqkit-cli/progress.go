@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"os"
+	"sync"
+	"time"
+)
+
+// progress reports the partitions, records and input bytes read by the executors, with an ETA estimated from
+// the input bytes. Only the readers count for it, the other functions only with --metrics-json. The records
+// are read lazily by the stages that follow, so the total of partitions is estimated too, and the ETA is
+// unknown once the input is read and a stage (e.g. a sort) is still running. To a terminal, the report is a
+// line updated every second, otherwise a log line every --progress-interval.
+type progress struct {
+	start    time.Time
+	interval time.Duration
+	tty      bool
+	done     chan bool
+	wait     sync.WaitGroup
+}
+
+// startProgress reports the progress until stop, or returns nil if it is disabled with --quiet. The progress is
+// informative, it is disabled with a warning if the metrics directory can not be created.
+func startProgress(cmd *cobra.Command) *progress {
+	interval := getFlagDuration(cmd, "progress-interval")
+	if getFlagBool(cmd, "quiet") || interval <= 0 {
+		return nil
+	}
+	if err := createMetricsDir(); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] progress disabled: %s\n", err)
+		return nil
+	}
+	this := &progress{
+		start:    time.Now(),
+		interval: interval,
+		done:     make(chan bool),
+	}
+	if info, err := os.Stderr.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		this.tty = true
+		this.interval = time.Second
+	}
+	bigseqkit.EnableProgress(metricsDir, this.interval)
+	this.wait.Add(1)
+	go func() {
+		defer this.wait.Done()
+		ticker := time.NewTicker(this.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.done:
+				if this.tty {
+					this.report()
+					fmt.Fprintln(os.Stderr)
+				}
+				return
+			case <-ticker.C:
+				this.report()
+			}
+		}
+	}()
+	return this
+}
+
+// stop ends the reports, it can be called more than once.
+func (this *progress) stop() {
+	if this == nil || this.done == nil {
+		return
+	}
+	close(this.done)
+	this.wait.Wait()
+	this.done = nil
+}
+
+func (this *progress) report() {
+	elapsed := time.Since(this.start).Round(time.Second)
+	line := fmt.Sprintf("%s: waiting for the executors", elapsed)
+	// the progress is informative, a failed read waits for the next report
+	if read, err := bigseqkit.ReadProgress(); err == nil && read.Partitions > 0 {
+		bytes, total := read.Counters["bytes_in"], bytesInput.Load()
+		done := 0.0
+		if total > 0 {
+			done = float64(bytes) / float64(total)
+		}
+		if done > 1 {
+			done = 1
+		}
+
+		partitions := fmt.Sprint(read.Partitions)
+		eta := "unknown"
+		if done > 0 && done < 1 {
+			partitions += fmt.Sprintf("/~%d", int64(float64(read.Partitions)/done+0.5))
+			eta = time.Duration(float64(elapsed) * (1 - done) / done).Round(time.Second).String()
+		}
+		line = fmt.Sprintf("%s: %s partitions, %d records, %s/%s read (%.1f%%), ETA %s", elapsed, partitions,
+			read.Counters["records_in"], humanize.Bytes(uint64(bytes)), humanize.Bytes(uint64(total)), done*100, eta)
+	}
+	if this.tty {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+	} else {
+		fmt.Fprintf(os.Stderr, "[INFO] %s\n", line)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+	"sort"
+	"strings"
+)
+
+func runProteinStats(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitProteinStatsOptions(cmd)
+	sequences, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if getFlagBool(cmd, "per-record") {
+		records, err := bigseqkit.ProteinStatsPerRecord(sequences, opts)
+		return records, nil, err
+	}
+
+	info, err := bigseqkit.ProteinStats(sequences, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := func() error {
+		residues := make([]byte, 0, len(info.Counts))
+		for aa := range info.Counts {
+			residues = append(residues, aa)
+		}
+		sort.Slice(residues, func(i, j int) bool { return residues[i] < residues[j] })
+
+		var sb strings.Builder
+		composition := info.Composition()
+		sb.WriteString("aa\tcount\tpercent\n")
+		for _, aa := range residues {
+			sb.WriteString(fmt.Sprintf("%c\t%d\t%.2f\n", aa, info.Counts[aa], composition[aa]*100))
+		}
+		sb.WriteString(fmt.Sprintf("# num_seqs: %d, residues: %d, GRAVY: %.4f\n", info.Records, info.Length(), info.Gravy()))
+		fmt.Fprint(reportOut, sb.String())
+		return nil
+	}
+
+	return nil, report, nil
+}
+
+func parseSeqKitProteinStatsOptions(cmd *cobra.Command) *bigseqkit.SeqKitProteinStatsOptions {
+	return (&bigseqkit.SeqKitProteinStatsOptions{}).
+		Config(parseSeqKitConfig(cmd))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "protein-stats",
+			Short: "amino-acid composition and properties (MW, pI, GRAVY) of protein sequences",
+			Long: `amino-acid composition and properties (MW, pI, GRAVY) of protein sequences
+
+Attentions:
+  1. Stop codons ('*') and gaps ('-', '.') are not counted.
+  2. By default the aggregated composition of all sequences is output,
+     --per-record outputs a line per sequence: ID, length, molecular weight
+     (average masses, Da), isoelectric point (EMBOSS pKa values) and GRAVY
+     (Kyte-Doolittle grand average of hydropathy).
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitProteinStatsOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runProteinStats)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runProteinStats)
+
+		cmd.Flags().BoolP("per-record", "r", false, "output the properties of every sequence")
+	})
+}
@@ -6,13 +6,12 @@ import (
 	"ignis/driver/api"
 )
 
-func runRange(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runRange(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitRangeOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Range(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Range(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitRangeOptions(cmd *cobra.Command) *bigseqkit.SeqKitRangeOptions {
@@ -39,11 +38,15 @@ Examples:
       seqkit range -r 10:100
       seqkit range -r -100:-10
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runRange)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitRangeOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runRange)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runRange)
 
 		cmd.Flags().StringP("range", "r", "", `range. e.g., 1:12 for first 12 records (head -n 12), -12:-1 for last 12 records (tail -n 12)`)
 	})
@@ -6,13 +6,12 @@ import (
 	"ignis/driver/api"
 )
 
-func runRename(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runRename(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitRenameOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Rename(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Rename(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitRenameOptions(cmd *cobra.Command) *bigseqkit.SeqKitRenameOptions {
@@ -43,11 +42,15 @@ Example:
     >id_2 description
     ACTG
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runRename)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitRenameOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runRename)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runRename)
 
 		cmd.Flags().StringSliceP("chr", "", []string{}, "select limited sequence with sequence IDs when using --gtf or --bed (multiple value supported, case ignored)")
 		cmd.Flags().StringP("region", "r", "", "by region. "+
@@ -6,13 +6,12 @@ import (
 	"ignis/driver/api"
 )
 
-func runReplace(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runReplace(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitReplaceOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Replace(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Replace(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitReplaceOptions(cmd *cobra.Command) *bigseqkit.SeqKitReplaceOptions {
@@ -20,11 +19,11 @@ func parseSeqKitReplaceOptions(cmd *cobra.Command) *bigseqkit.SeqKitReplaceOptio
 		Config(parseSeqKitConfig(cmd)).
 		Pattern(getFlagString(cmd, "pattern")).
 		Replacement(getFlagString(cmd, "replacement")).
-		NrWidth(getFlagPositiveInt(cmd, "nr-width")).
+		NrWidth(getFlagInt(cmd, "nr-width")).
 		KvFile(getFlagString(cmd, "kv-file")).
 		KeepKey(getFlagBool(cmd, "keep-key")).
 		KeepUntouch(getFlagBool(cmd, "keep-untouch")).
-		KeyCaptIdx(getFlagPositiveInt(cmd, "key-capt-idx")).
+		KeyCaptIdx(getFlagInt(cmd, "key-capt-idx")).
 		KeyMissRepl(getFlagString(cmd, "key-miss-repl")).
 		BySeq(getFlagBool(cmd, "by-seq")).
 		IgnoreCase(getFlagBool(cmd, "ignore-case"))
@@ -57,11 +56,15 @@ Special cases:
     b). If not, use '$$':
             -r 'xxx$$xx'
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runReplace)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitReplaceOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runReplace)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runReplace)
 
 		cmd.Flags().StringP("pattern", "p", "", "search regular expression")
 		cmd.Flags().StringP("replacement", "r", "",
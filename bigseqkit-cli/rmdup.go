@@ -2,13 +2,99 @@ package main
 
 import (
 	"bigseqkit"
+	"fmt"
 	"github.com/spf13/cobra"
 	"ignis/driver/api"
+	"os"
+	"path/filepath"
 )
 
-func runRmDup(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runRmDup(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitRmDupOptions(cmd)
-	return check(bigseqkit.RmDup(union(cmd, input...), opts))
+	if !getFlagBool(cmd, "optical") {
+		records, err := union(cmd, input...)
+		if err != nil {
+			return nil, nil, err
+		}
+		output, err := bigseqkit.RmDup(records, opts)
+		return output, nil, err
+	}
+
+	if getFlagBool(cmd, "paired") {
+		report, err := runRmDupOpticalPairs(input, cmd, opts, pipe)
+		return nil, report, err
+	}
+
+	records, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, info, err := bigseqkit.RmDupOptical(records, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !pipe && !getFlagBool(cmd, "quiet") {
+		return result, func() error {
+			printOpticalDupInfo(info)
+			return nil
+		}, nil
+	}
+	return result, nil, nil
+}
+
+// runRmDupOpticalPairs removes the optical/PCR duplicates of the read pairs of two files, the mates are
+// matched up like pair does and saved in the output directory by the returned report.
+func runRmDupOpticalPairs(input []*api.IDataFrame[string], cmd *cobra.Command, opts *bigseqkit.SeqKitRmDupOptions, pipe bool) (func() error, error) {
+	if pipe {
+		return nil, bigseqkit.OptionErrorf("flag --paired is not allowed in a pipe")
+	}
+	if len(input) != 2 {
+		return nil, bigseqkit.OptionErrorf("2 files needed with flag --paired")
+	}
+	outdir := getFlagString(cmd, "out-dir")
+	if outdir == "" {
+		return nil, bigseqkit.OptionErrorf("flag -O (--out-dir) required with flag --paired")
+	}
+	if err := os.MkdirAll(outdir, 0755); err != nil {
+		return nil, err
+	}
+
+	pairOpts := (&bigseqkit.SeqKitPairOptions{}).Config(parseSeqKitConfig(cmd))
+	pairs, _, _, err := bigseqkit.Pair(input[0], input[1], pairOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, info, err := bigseqkit.RmDupOpticalPairs(pairs, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if err := result.Cache(); err != nil {
+			return err
+		}
+		defer result.Uncache()
+		for i, name := range []string{"paired.1", "paired.2"} {
+			reads, err := bigseqkit.PairIndex(result, i)
+			if err != nil {
+				return err
+			}
+			if err = storeFASTX(reads, filepath.Join(outdir, name), getFlagBool(cmd, "merge")); err != nil {
+				return err
+			}
+		}
+		if !getFlagBool(cmd, "quiet") {
+			printOpticalDupInfo(info)
+		}
+		return nil
+	}, nil
+}
+
+func printOpticalDupInfo(info *bigseqkit.OpticalDupInfo) {
+	fmt.Fprintf(reportOut, "reads\toptical\toptical_rate\tpcr\tpcr_rate\n")
+	fmt.Fprintf(reportOut, "%d\t%d\t%.6f\t%d\t%.6f\n", info.Reads, info.Optical, info.OpticalRate(), info.PCR, info.PCRRate())
 }
 
 func parseSeqKitRmDupOptions(cmd *cobra.Command) *bigseqkit.SeqKitRmDupOptions {
@@ -19,7 +105,12 @@ func parseSeqKitRmDupOptions(cmd *cobra.Command) *bigseqkit.SeqKitRmDupOptions {
 		IgnoreCase(getFlagBool(cmd, "ignore-case")).
 		DupSeqsFile(getFlagString(cmd, "dup-seqs-file")).
 		DupNumFile(getFlagString(cmd, "dup-num-file")).
-		OnlyPositiveStrand(getFlagBool(cmd, "only-positive-strand"))
+		OnlyPositiveStrand(getFlagBool(cmd, "only-positive-strand")).
+		Optical(getFlagBool(cmd, "optical")).
+		IlluminaRegexp(getFlagString(cmd, "illumina-regexp")).
+		PixelDistance(getFlagInt(cmd, "pixel-distance")).
+		PrefixLength(getFlagInt(cmd, "prefix-length")).
+		RemoveClass(getFlagString(cmd, "remove-class"))
 }
 
 func init() {
@@ -31,16 +122,35 @@ func init() {
 			Long: `remove duplicated sequences by ID/name/sequence
 Attentions:
   1. When comparing by sequences, both positive and negative strands are
-     compared. Switch on -P/--only-positive-strand for considering the
-     positive strand only.
+     compared: a sequence and its reverse complement share the same
+     canonical key, min(seq, revcom(seq)). Switch on -P/--only-positive-strand
+     for considering the positive strand only.
   2. Only the first record is saved for duplicates.
+  3. With --optical, reads with identical sequences (or identical first
+     --prefix-length bases) are classified using the Illumina read names
+     (instrument:run:flowcell:lane:tile:x:y): copies on the same lane and
+     tile within --pixel-distance pixels are optical duplicates, the rest
+     are PCR duplicates. --remove-class selects the removed class and the
+     rates of both classes are printed. Strands are not merged in this mode.
+  4. With --optical --paired, the two input files are matched up as read
+     pairs, a pair is a duplicate when the first --prefix-length bases of
+     both mates are identical, and the coordinates are read from the first
+     mate. The kept pairs are saved to paired.1 and paired.2 of -O/--out-dir.
+  5. -d/--dup-seqs-file and -D/--dup-num-file are not available with --optical.
      
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runRmDup)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				if getFlagBool(cmd, "paired") && !getFlagBool(cmd, "optical") {
+					return bigseqkit.OptionErrorf("flag --optical needed when using --paired")
+				}
+				return parseSeqKitRmDupOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runRmDup)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runRmDup)
 
 		cmd.Flags().BoolP("by-name", "n", false, "by full name instead of just id")
 		cmd.Flags().BoolP("by-seq", "s", false, "by seq")
@@ -49,5 +159,12 @@ Attentions:
 		cmd.Flags().StringP("dup-num-file", "D", "", "file to save number and list of duplicated seqs")
 		// cmd.Flags().BoolP("consider-revcom", "r", false, "considering the reverse compelment sequence")
 		cmd.Flags().BoolP("only-positive-strand", "P", false, "only considering positive strand when comparing by sequence")
+		cmd.Flags().BoolP("optical", "", false, "classify duplicates into optical and PCR duplicates using Illumina read names")
+		cmd.Flags().StringP("illumina-regexp", "", bigseqkit.DefaultIlluminaRegexp, "regular expression capturing lane, tile, x and y from read names")
+		cmd.Flags().IntP("pixel-distance", "", 100, "maximum pixel distance between optical duplicates")
+		cmd.Flags().IntP("prefix-length", "", 0, "only compare the first N bases of the sequences (0 for whole sequence)")
+		cmd.Flags().StringP("remove-class", "", "all", "duplicates to remove with --optical (all|optical|pcr)")
+		cmd.Flags().BoolP("paired", "", false, "detect optical duplicates of the read pairs of two files, requires --optical")
+		cmd.Flags().StringP("out-dir", "O", "", "output directory of the read pairs with --paired")
 	})
 }
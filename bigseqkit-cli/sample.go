@@ -2,17 +2,17 @@ package main
 
 import (
 	"bigseqkit"
-	"fmt"
 	"github.com/spf13/cobra"
 	"ignis/driver/api"
 )
 
-func runSample(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runSample(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	if len(input) != 1 {
-		checkError(fmt.Errorf("only 1 file needed"))
+		return nil, nil, bigseqkit.OptionErrorf("only 1 file needed")
 	}
 	opts := parseSeqKitSampleOptions(cmd)
-	return check(bigseqkit.Sample(input[0], opts))
+	output, err := bigseqkit.Sample(input[0], opts)
+	return output, nil, err
 }
 
 func parseSeqKitSampleOptions(cmd *cobra.Command) *bigseqkit.SeqKitSampleOptions {
@@ -34,11 +34,15 @@ Attention:
 1. Do not use '-n' on large FASTQ files, it loads all seqs into memory!
    use 'seqkit sample -p 0.1 seqs.fq.gz | seqkit head -n N' instead!
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runSample)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitSampleOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runSample)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runSample)
 
 		cmd.Flags().Int64P("rand-seed", "s", 11, "rand seed")
 		cmd.Flags().Int64P("number", "n", 0, "sample by number (result may not exactly match), DO NOT use on large FASTQ files.")
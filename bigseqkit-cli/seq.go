@@ -6,13 +6,12 @@ import (
 	"ignis/driver/api"
 )
 
-func runSeq(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runSeq(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitSeqOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Seq(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Seq(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitSeqOptions(cmd *cobra.Command) *bigseqkit.SeqKitSeqOptions {
@@ -31,10 +30,10 @@ func parseSeqKitSeqOptions(cmd *cobra.Command) *bigseqkit.SeqKitSeqOptions {
 		Dna2rna(getFlagBool(cmd, "dna2rna")).
 		Rna2dna(getFlagBool(cmd, "rna2dna")).
 		ValidateSeq(getFlagBool(cmd, "validate-seq")).
-		ValidateSeqLength(getFlagValidateSeqLength(cmd, "validate-seq-length")).
+		ValidateSeqLength(getFlagInt(cmd, "validate-seq-length")).
 		MinLen(getFlagInt(cmd, "min-len")).
 		MaxLen(getFlagInt(cmd, "max-len")).
-		QualAsciiBase(getFlagPositiveInt(cmd, "qual-ascii-base")).
+		QualAsciiBase(getFlagInt(cmd, "qual-ascii-base")).
 		MinQual(getFlagFloat64(cmd, "min-qual")).MaxQual(getFlagFloat64(cmd, "max-qual"))
 }
 
@@ -44,12 +43,16 @@ func init() {
 			Use:   "seq",
 			Short: "transform sequences (extract ID, filter by length, remove gaps, reverse complement...)",
 			Long:  `transform sequences (extract ID, filter by length, remove gaps, reverse complement...)`,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runSeq)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitSeqOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runSeq)
 			},
 		}
 
 		parent.AddCommand(cmd)
+		addRunner(cmd, runSeq)
 
 		cmd.Flags().BoolP("reverse", "r", false, "reverse sequence")
 		cmd.Flags().BoolP("complement", "p", false, "complement sequence, flag '-v' is recommended to switch on")
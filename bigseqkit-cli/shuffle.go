@@ -6,13 +6,12 @@ import (
 	"ignis/driver/api"
 )
 
-func runShuffle(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runShuffle(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitShuffleOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Shuffle(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Shuffle(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitShuffleOptions(cmd *cobra.Command) *bigseqkit.SeqKitShuffleOptions {
@@ -35,11 +34,15 @@ Firstly, seqkit reads the sequence IDs. If the file is not plain FASTA file,
 seqkit will write the sequences to temporary files, and create FASTA index.
 Secondly, seqkit shuffles sequence IDs and extract sequences by FASTA index.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runShuffle)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitShuffleOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runShuffle)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runShuffle)
 
 		cmd.Flags().Int64P("rand-seed", "s", 23, "rand seed for shuffle")
 		cmd.Flags().BoolP("two-pass", "2", false, "two-pass mode read files twice to lower memory usage. (only for FASTA format)")
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bigseqkit"
+	"github.com/spf13/cobra"
+	"ignis/driver/api"
+)
+
+func runSliding(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
+	opts := parseSeqKitSlidingOptions(cmd)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Sliding(input, opts)
+	})
+	return output, nil, err
+}
+
+func parseSeqKitSlidingOptions(cmd *cobra.Command) *bigseqkit.SeqKitSlidingOptions {
+	return (&bigseqkit.SeqKitSlidingOptions{}).
+		Config(parseSeqKitConfig(cmd)).
+		Step(getFlagInt(cmd, "step")).
+		Window(getFlagInt(cmd, "window")).
+		Circular(getFlagBool(cmd, "circular")).
+		Greedy(getFlagBool(cmd, "greedy")).
+		Suffix(getFlagString(cmd, "suffix")).
+		Stats(getFlagBool(cmd, "stats")).
+		SplitLen(getFlagInt(cmd, "split-len"))
+}
+
+func init() {
+	addCommand(func(parent *cobra.Command) {
+
+		cmd := &cobra.Command{
+			Use:   "sliding",
+			Short: "extract subsequences in sliding windows",
+			Long: `extract subsequences in sliding windows
+
+Attentions:
+  1. Windows are named "ID{suffix}:start-end" with 1-based start. In
+     circular mode (-C/--circular) the windows continue across the end
+     of the sequence and end may be lower than start.
+  2. -g/--greedy outputs the last windows even if they are shorter than
+     the window size.
+  3. --stats outputs a table instead of the subsequences, columns:
+     ID, start, end, length, GC content, GC skew (G-C)/(G+C),
+     AT skew (A-T)/(A+T), N fraction and Shannon entropy (bits) of
+     A, C, G and T/U.
+  4. Sequences longer than --split-len are split into chunks of whole
+     windows that are distributed among the executors, the order of the
+     windows is kept. Use 0 to disable it.
+
+`,
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitSlidingOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runSliding)
+			},
+		}
+		parent.AddCommand(cmd)
+		addRunner(cmd, runSliding)
+
+		cmd.Flags().IntP("step", "s", 0, "step size")
+		cmd.Flags().IntP("window", "W", 0, "window size")
+		cmd.Flags().BoolP("circular", "C", false, "circular genome")
+		cmd.Flags().BoolP("greedy", "g", false, "greedy mode, i.e., exporting last subsequences even shorter than window size")
+		cmd.Flags().StringP("suffix", "S", "_sliding", "suffix added to the sequence ID")
+		cmd.Flags().BoolP("stats", "", false, "output a table of GC content, GC/AT skew, N fraction and entropy of every window")
+		cmd.Flags().IntP("split-len", "", 1000000, "split sequences longer than this among the executors, 0 for no splitting")
+	})
+}
@@ -6,9 +6,14 @@ import (
 	"ignis/driver/api"
 )
 
-func runSort(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runSort(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitSortOptions(cmd)
-	return check(bigseqkit.Sort(union(cmd, input...), opts))
+	records, err := union(cmd, input...)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := bigseqkit.Sort(records, opts)
+	return output, nil, err
 }
 
 func parseSeqKitSortOptions(cmd *cobra.Command) *bigseqkit.SeqKitSortOptions {
@@ -22,7 +27,7 @@ func parseSeqKitSortOptions(cmd *cobra.Command) *bigseqkit.SeqKitSortOptions {
 		GapLetters(getFlagString(cmd, "gap-letters")).
 		Reverse(getFlagBool(cmd, "reverse")).
 		IgnoreCase(getFlagBool(cmd, "ignore-case")).
-		SeqPrefixLength(uint(getFlagNonNegativeInt(cmd, "seq-prefix-length")))
+		SeqPrefixLength(uint(getFlagInt(cmd, "seq-prefix-length")))
 }
 
 func init() {
@@ -41,11 +46,18 @@ seqkit will write the sequences to temporary files, and create FASTA index.
 Secondly, seqkit sorts sequence by head and length information
 and extracts sequences by FASTA index.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runSort)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				if getFlagInt(cmd, "seq-prefix-length") < 0 {
+					return bigseqkit.OptionErrorf("value of flag -L (--seq-prefix-length) should not be negative")
+				}
+				return parseSeqKitSortOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runSort)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runSort)
 
 		cmd.Flags().BoolP("natural-order", "N", false, "sort in natural order, when sorting by IDs/full name")
 		cmd.Flags().BoolP("by-name", "n", false, "by full name instead of just id")
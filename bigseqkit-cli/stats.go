@@ -8,23 +8,27 @@ import (
 	"strings"
 )
 
-func runStats(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runStats(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitStatsOptions(cmd)
 	head := ""
 	body := ""
 
 	for i := range input {
-		table := check(bigseqkit.StatsString(fmt.Sprintf("input%d", i), "N/A", input[i], opts))
+		table, err := bigseqkit.StatsString(fmt.Sprintf("input%d", i), "N/A", input[i], opts)
+		if err != nil {
+			return nil, nil, err
+		}
 		lines := strings.Split(table, "\n")
 		head = lines[0] + "\n"
 		body += strings.Join(lines[1:], "\n") + "\n"
 	}
 
-	fOuput = func() {
-		fmt.Print(head + body)
+	report := func() error {
+		fmt.Fprint(reportOut, head+body)
+		return nil
 	}
 
-	return nil
+	return nil, report, nil
 }
 
 func parseSeqKitStatsOptions(cmd *cobra.Command) *bigseqkit.SeqKitStatsOptions {
@@ -52,11 +56,15 @@ The definition of region is 1-based and with some custom design.
 Examples:
 %s
 `, regionExample),
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runStats)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitStatsOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runStats)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runStats)
 
 		cmd.Flags().BoolP("tabular", "T", false, "output in machine-friendly tabular format")
 		cmd.Flags().StringP("gap-letters", "G", "- .", "gap letters")
@@ -7,13 +7,32 @@ import (
 	"ignis/driver/api"
 )
 
-func runSubseq(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runSubseq(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitSubseqOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Subseq(input[i], opts))
+	if getFlagBool(cmd, "distributed") {
+		var file, format string
+		if file = getFlagString(cmd, "gtf"); file != "" {
+			format = "gtf"
+		} else if file = getFlagString(cmd, "gff"); file != "" {
+			format = "gff"
+		} else if file = getFlagString(cmd, "bed"); file != "" {
+			format = "bed"
+		} else {
+			return nil, nil, bigseqkit.OptionErrorf("flag --distributed needs one of the flags --bed, --gtf, --gff")
+		}
+		annotation, err := bigseqkit.ReadAnnotation(file, jobWorker)
+		if err != nil {
+			return nil, nil, err
+		}
+		output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+			return bigseqkit.SubseqJoin(input, annotation, format, opts)
+		})
+		return output, nil, err
 	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Subseq(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitSubseqOptions(cmd *cobra.Command) *bigseqkit.SeqKitSubseqOptions {
@@ -23,11 +42,16 @@ func parseSeqKitSubseqOptions(cmd *cobra.Command) *bigseqkit.SeqKitSubseqOptions
 		Region(getFlagString(cmd, "region")).
 		Gtf(getFlagString(cmd, "gtf")).
 		Feature(getFlagStringSlice(cmd, "feature")).
-		UpStream(getFlagNonNegativeInt(cmd, "up-stream")).
-		DownStream(getFlagNonNegativeInt(cmd, "down-stream")).
+		UpStream(getFlagInt(cmd, "up-stream")).
+		DownStream(getFlagInt(cmd, "down-stream")).
 		OnlyFlank(getFlagBool(cmd, "only-flank")).
 		Bed(getFlagString(cmd, "bed")).
-		GtfTag(getFlagString(cmd, "gtf-tag"))
+		GtfTag(getFlagString(cmd, "gtf-tag")).
+		Gff(getFlagString(cmd, "gff")).
+		Attribute(getFlagStringSlice(cmd, "attribute")).
+		Extract(getFlagString(cmd, "extract")).
+		GroupBy(getFlagString(cmd, "group-by")).
+		TranslTable(getFlagInt(cmd, "transl-table"))
 }
 
 func init() {
@@ -41,17 +65,34 @@ Attentions:
   1. Use "seqkit grep" for extract subsets of sequences.
      "seqtk subseq seqs.fasta id.txt" equals to
      "seqkit grep -f id.txt seqs.fasta"
+  2. --gff reads GFF3 files, features are selected by type (--feature) and
+     attributes (--attribute key=value).
+  3. --extract outputs spliced sequences: "transcript" joins the exons of
+     every transcript by Parent (or the BED12 blocks), "cds" joins the CDS
+     (or the thick part of the BED12 blocks) and "protein" translates them.
+     The strand is applied. Use --group-by gene to keep the longest
+     transcript of every gene.
+  4. --distributed reads the GTF/GFF3/BED file as a distributed dataset and
+     joins it with the sequences by chromosome and bin of 1 Mb, instead of
+     loading the whole annotation in every executor. Recommended for huge
+     annotations and genomes.
+  5. With --gtf, --gff and --bed, a subsequence is output for every selected
+     feature of a sequence, as seqkit does.
 Recommendation:
   1. use plain FASTA file, so seqkit could utilize FASTA index.
 The definition of region is 1-based and with some custom design.
 Examples:
 %s
 `, regionExample),
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runSubseq)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitSubseqOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runSubseq)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runSubseq)
 
 		cmd.Flags().StringSliceP("chr", "", []string{}, "select limited sequence with sequence IDs when using --gtf or --bed (multiple value supported, case ignored)")
 		cmd.Flags().StringP("region", "r", "", "by region. "+
@@ -59,11 +100,17 @@ Examples:
 			` 13:-1 for cutting first 12 bases. type "seqkit subseq -h" for more examples`)
 
 		cmd.Flags().StringP("gtf", "", "", "by GTF (version 2.2) file")
-		cmd.Flags().StringSliceP("feature", "", []string{}, `select limited feature types (multiple value supported, case ignored, only works with GTF)`)
+		cmd.Flags().StringSliceP("feature", "", []string{}, `select limited feature types (multiple value supported, case ignored, only works with GTF and GFF3)`)
 		cmd.Flags().IntP("up-stream", "u", 0, "up stream length")
 		cmd.Flags().IntP("down-stream", "d", 0, "down stream length")
 		cmd.Flags().BoolP("only-flank", "f", false, "only return up/down stream sequence")
 		cmd.Flags().StringP("bed", "", "", "by tab-delimited BED file")
 		cmd.Flags().StringP("gtf-tag", "", "gene_id", `output this tag as sequence comment`)
+		cmd.Flags().StringP("gff", "", "", "by GFF3 file")
+		cmd.Flags().StringSliceP("attribute", "", []string{}, `select features by attribute, e.g. biotype=protein_coding (multiple value supported, only works with GFF3)`)
+		cmd.Flags().StringP("extract", "", "", `output spliced sequences from GFF3/BED12 (transcript|cds|protein)`)
+		cmd.Flags().StringP("group-by", "", "transcript", `name spliced sequences by transcript or gene ID, keeping the longest transcript of every gene (transcript|gene)`)
+		cmd.Flags().BoolP("distributed", "", false, `join the annotation with the sequences by chromosome instead of loading it in every executor`)
+		cmd.Flags().IntP("transl-table", "T", 1, `translate table/genetic code for --extract protein`)
 	})
 }
@@ -6,19 +6,18 @@ import (
 	"ignis/driver/api"
 )
 
-func runTranslate(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) *api.IDataFrame[string] {
+func runTranslate(input []*api.IDataFrame[string], cmd *cobra.Command, args []string, pipe bool) (*api.IDataFrame[string], func() error, error) {
 	opts := parseSeqKitTranslateOptions(cmd)
-	results := make([]*api.IDataFrame[string], len(input))
-	for i := range input {
-		results[i] = check(bigseqkit.Translate(input[i], opts))
-	}
-	return union(cmd, results...)
+	output, err := unionEach(cmd, input, func(input *api.IDataFrame[string]) (*api.IDataFrame[string], error) {
+		return bigseqkit.Translate(input, opts)
+	})
+	return output, nil, err
 }
 
 func parseSeqKitTranslateOptions(cmd *cobra.Command) *bigseqkit.SeqKitTranslateOptions {
 	return (&bigseqkit.SeqKitTranslateOptions{}).
 		Config(parseSeqKitConfig(cmd)).
-		TranslTable(getFlagPositiveInt(cmd, "transl-table")).
+		TranslTable(getFlagInt(cmd, "transl-table")).
 		Frame(getFlagStringSlice(cmd, "frame")).
 		Trim(getFlagBool(cmd, "trim")).
 		Clean(getFlagBool(cmd, "clean")).
@@ -26,7 +25,10 @@ func parseSeqKitTranslateOptions(cmd *cobra.Command) *bigseqkit.SeqKitTranslateO
 		InitCodonAsM(getFlagBool(cmd, "init-codon-as-M")).
 		ListTranslTable(getFlagInt(cmd, "list-transl-table")).
 		ListTranslTableWithAmbCodons(getFlagInt(cmd, "list-transl-table-with-amb-codons")).
-		AppendFrame(getFlagBool(cmd, "append-frame"))
+		AppendFrame(getFlagBool(cmd, "append-frame")).
+		TranslTableFrom(getFlagString(cmd, "transl-table-from")).
+		TranslTableRegexp(getFlagString(cmd, "transl-table-regexp")).
+		CodonTableFile(getFlagString(cmd, "codon-table-file"))
 }
 
 func init() {
@@ -76,12 +78,30 @@ Translate Tables/Genetic Codes:
     29: Mesodinium Nuclear
     30: Peritrich Nuclear
     31: Blastocrithidia Nuclear
+Per-record tables:
+  --transl-table-from header captures the table from the header with
+  --transl-table-regexp (default: transl_table=N), --transl-table-from FILE
+  reads a tab-delimited file of sequence ID and table. Other records use
+  -T/--transl-table.
+User-defined tables (--codon-table-file), NCBI format, one or more tables
+starting with a line with the table ID and name:
+    100 My Code
+      AAs  = FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG
+    Starts = ---M------**--*----M---------------M----------------------------
+    Base1  = TTTTTTTTTTTTTTTTCCCCCCCCCCCCCCCCAAAAAAAAAAAAAAAAGGGGGGGGGGGGGGGG
+    Base2  = TTTTCCCCAAAAGGGGTTTTCCCCAAAAGGGGTTTTCCCCAAAAGGGGTTTTCCCCAAAAGGGG
+    Base3  = TCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAGTCAG
+  A table with the ID of a built-in table replaces it.
 `,
-			Run: func(cmd *cobra.Command, args []string) {
-				ignisDriver(cmd, args, runTranslate)
+			PreRunE: func(cmd *cobra.Command, args []string) error {
+				return parseSeqKitTranslateOptions(cmd).Validate()
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return ignisDriver(cmd, args, runTranslate)
 			},
 		}
 		parent.AddCommand(cmd)
+		addRunner(cmd, runTranslate)
 
 		cmd.Flags().IntP("transl-table", "T", 1, `translate table/genetic code, type 'seqkit translate --help' for more details`)
 		cmd.Flags().StringSliceP("frame", "f", []string{"1"}, "frame(s) to translate, available value: 1, 2, 3, -1, -2, -3, and 6 for all six frames")
@@ -92,5 +112,8 @@ Translate Tables/Genetic Codes:
 		cmd.Flags().IntP("list-transl-table", "l", -1, "show details of translate table N, 0 for all")
 		cmd.Flags().IntP("list-transl-table-with-amb-codons", "L", -1, "show details of translate table N (including ambigugous codons), 0 for all. ")
 		cmd.Flags().BoolP("append-frame", "F", false, "append frame information to sequence ID")
+		cmd.Flags().StringP("transl-table-from", "", "", `take the table of every sequence from the header ("header") or from a tab-delimited file of ID and table`)
+		cmd.Flags().StringP("transl-table-regexp", "", `transl_table=(\d+)`, `regular expression capturing the table from the header, for --transl-table-from header`)
+		cmd.Flags().StringP("codon-table-file", "", "", "file with user-defined codon tables in NCBI format")
 	})
 }
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bigseqkit"
+	"bytes"
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"github.com/shenwei356/bio/seqio/fastx"
+	"github.com/shenwei356/bwt/fmi"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/iterator"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ampliconPrimer is a primer pair in upper case, with the reverse complement of both primers.
+type ampliconPrimer struct {
+	name                 string
+	forward, reverse     []byte
+	forwardRC, reverseRC []byte
+}
+
+func NewAmplicon() any {
+	return &Amplicon{}
+}
+
+type Amplicon struct {
+	base.IMapPartitions[string, string]
+	function.IAfterNone
+	opts     bigseqkit.AmpliconOptions
+	alphabet *seq.Alphabet
+	primers  []ampliconPrimer
+	regexps  map[string]*regexp.Regexp
+}
+
+func (this *Amplicon) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.AmpliconOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	if err != nil {
+		return err
+	}
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+
+	pairs := make([][3]string, 0, 1)
+	if *this.opts.PrimerFile != "" {
+		data, err := os.ReadFile(*this.opts.PrimerFile)
+		if err != nil {
+			return fmt.Errorf("read primer file: %s", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimRight(line, "\r")
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			items := strings.Split(line, "\t")
+			if len(items) < 3 {
+				return fmt.Errorf("primer file should have three tab-delimited columns (name, forward, reverse): %s", line)
+			}
+			pairs = append(pairs, [3]string{items[0], items[1], items[2]})
+		}
+		if len(pairs) == 0 {
+			return fmt.Errorf("no primers found in primer file: %s", *this.opts.PrimerFile)
+		}
+	} else {
+		pairs = append(pairs, [3]string{"primer", *this.opts.Forward, *this.opts.Reverse})
+	}
+
+	this.regexps = make(map[string]*regexp.Regexp)
+	for _, pair := range pairs {
+		p := ampliconPrimer{name: pair[0]}
+		if p.forward, p.forwardRC, err = this.preparePrimer(pair[1]); err != nil {
+			return err
+		}
+		if p.reverse, p.reverseRC, err = this.preparePrimer(pair[2]); err != nil {
+			return err
+		}
+		this.primers = append(this.primers, p)
+	}
+
+	return nil
+}
+
+// preparePrimer checks a primer and returns it and its reverse complement in upper case. Like Locate, the
+// regular expressions of degenerate primers are compiled, and mismatches are only allowed with plain primers.
+func (this *Amplicon) preparePrimer(primer string) ([]byte, []byte, error) {
+	p := bytes.ToUpper([]byte(primer))
+	if len(p) == 0 || seq.DNAredundant.IsValid(p) != nil && seq.RNAredundant.IsValid(p) != nil {
+		return nil, nil, fmt.Errorf("illegal DNA/RNA primer: %s", primer)
+	}
+	if *this.opts.MaxMismatch > len(p) {
+		return nil, nil, fmt.Errorf("mismatch should be <= length of primer: %s", primer)
+	}
+	s, err := seq.NewSeqWithoutValidation(seq.DNAredundant, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc := s.RevCom()
+	if ampliconDegenerate(s.Seq) {
+		if *this.opts.MaxMismatch > 0 {
+			return nil, nil, fmt.Errorf("degenerate primer not allowed when giving flag -m (--max-mismatch): %s", primer)
+		}
+		for _, x := range []*seq.Seq{s, rc} {
+			if this.regexps[string(x.Seq)], err = regexp.Compile(x.Degenerate2Regexp()); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return s.Seq, rc.Seq, nil
+}
+
+func ampliconDegenerate(p []byte) bool {
+	return bytes.IndexFunc(p, func(r rune) bool { return r != 'A' && r != 'C' && r != 'G' && r != 'T' && r != 'U' }) >= 0
+}
+
+// ampliconMismatches counts the positions of the target not matching the primer.
+func ampliconMismatches(primer, target []byte) int {
+	n := 0
+	for i, b := range primer {
+		if target[i] != b {
+			n++
+		}
+	}
+	return n
+}
+
+// locate returns the sorted positions of a primer in the sequence (in upper case) with the searches of
+// Locate: the FM-index for plain primers and the regular expression for degenerate ones.
+func (this *Amplicon) locate(sfmi *fmi.FMIndex, s []byte, primer []byte) ([]int, error) {
+	re, ok := this.regexps[string(primer)]
+	if !ok {
+		return fmiLocate(sfmi, primer, *this.opts.MaxMismatch)
+	}
+	matches := patternLocate(re, primer, s, false)
+	locs := make([]int, len(matches))
+	for i, match := range matches {
+		locs[i] = match[0]
+	}
+	return locs, nil
+}
+
+// ampliconHit is a product [start, end) on the positive strand.
+type ampliconHit struct {
+	start, end int
+	mismatches int
+}
+
+// pair pairs every hit of the first primer with the nearest downstream hit of the second one (reverse
+// complemented) not overlapping it. Products longer than the sequence or out of the size range are dropped.
+func (this *Amplicon) pair(s []byte, l int, first, second []byte, starts, ends []int) []ampliconHit {
+	hits := make([]ampliconHit, 0, 1)
+	for _, f := range starts {
+		if f >= l { // 2nd copy of circular sequences
+			break
+		}
+		k := sort.SearchInts(ends, f+len(first))
+		if k == len(ends) {
+			continue
+		}
+		end := ends[k] + len(second)
+		size := end - f
+		if size > l || size < *this.opts.MinLen || *this.opts.MaxLen > 0 && size > *this.opts.MaxLen {
+			continue
+		}
+		mismatches := 0
+		if *this.opts.MaxMismatch > 0 { // degenerate primers are matched exactly
+			mismatches = ampliconMismatches(first, s[f:f+len(first)]) + ampliconMismatches(second, s[ends[k]:end])
+		}
+		hits = append(hits, ampliconHit{f, end, mismatches})
+	}
+	return hits
+}
+
+func (this *Amplicon) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, 100)
+	sfmi := fmi.NewFMIndex()
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		s := record.Seq.Seq
+		l := len(s)
+		if *this.opts.Circular { // concat two copies of sequence
+			s = append(append(make([]byte, 0, 2*l), s...), s...)
+		}
+		upper := bytes.ToUpper(s)
+		if _, err = sfmi.Transform(upper); err != nil {
+			return nil, fmt.Errorf("fail to build FMIndex for sequence: %s", record.Name)
+		}
+
+		for _, p := range this.primers {
+			for _, strand := range []string{"+", "-"} {
+				if strand == "-" && *this.opts.OnlyPositiveStrand {
+					break
+				}
+				// on the negative strand, the reverse primer and the reverse complement of the forward
+				// primer are found in the positive strand
+				first, second, secondRC := p.forward, p.reverse, p.reverseRC
+				if strand == "-" {
+					first, second, secondRC = p.reverse, p.forward, p.forwardRC
+				}
+				starts, err := this.locate(sfmi, upper, first)
+				if err != nil {
+					return nil, fmt.Errorf("fail to search primer '%s' on seq '%s': %s", p.name, record.Name, err)
+				}
+				if len(starts) == 0 {
+					continue
+				}
+				ends, err := this.locate(sfmi, upper, secondRC)
+				if err != nil {
+					return nil, fmt.Errorf("fail to search primer '%s' on seq '%s': %s", p.name, record.Name, err)
+				}
+
+				for _, hit := range this.pair(upper, l, first, secondRC, starts, ends) {
+					start, end := hit.start, hit.end
+					if *this.opts.OnlyInner {
+						start, end = start+len(first), end-len(second)
+						if start > end {
+							continue
+						}
+					}
+					// products crossing the origin of circular sequences end after the sequence length in BED
+					if *this.opts.Bed {
+						result = append(result, fmt.Sprintf("%s\t%d\t%d\t%s\t%d\t%s",
+							record.ID, start, end, p.name, hit.mismatches, strand))
+						continue
+					}
+
+					product := s[start:end]
+					if strand == "-" {
+						rc, err := seq.NewSeqWithoutValidation(seq.DNAredundant, product)
+						if err != nil {
+							return nil, err
+						}
+						product = rc.RevCom().Seq
+					}
+					e := end
+					if e > l {
+						e -= l
+					}
+					productID := fmt.Sprintf("%s_%s:%d-%d:%s", record.ID, p.name, start+1, e, strand)
+					newRecord, err := fastx.NewRecordWithoutValidation(record.Seq.Alphabet, []byte(productID), []byte(productID), nil, product)
+					if err != nil {
+						return nil, err
+					}
+					result = append(result, string(newRecord.Format(*this.opts.Config.LineWidth)))
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bigseqkit"
+	"github.com/cespare/xxhash/v2"
+	"github.com/shenwei356/bio/seq"
+	"github.com/shenwei356/bio/seqio/fastx"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/iterator"
+	"io"
+	"math/bits"
+)
+
+func NewCardinalitySketch() any {
+	return &CardinalitySketch{}
+}
+
+type CardinalitySketch struct {
+	base.IMapPartitions[string, []int64]
+	function.IAfterNone
+	opts     bigseqkit.CardinalityOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *CardinalitySketch) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.CardinalityOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call builds the HyperLogLog sketch of the partition, the first element is the number of records
+// and the rest are the 2^precision registers.
+func (this *CardinalitySketch) Call(v1 iterator.IReadIterator[string], context api.IContext) ([][]int64, error) {
+	p := uint(*this.opts.Precision)
+	sketch := make([]int64, 1+(1<<p))
+
+	var record *fastx.Record
+
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	revcom := !*this.opts.OnlyPositiveStrand
+
+	for {
+		record, err = fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		hash := xxhash.Sum64(recordKey(record, *this.opts.BySeq, *this.opts.ByName, *this.opts.IgnoreCase, revcom))
+		i := hash >> (64 - p)
+		rho := int64(bits.LeadingZeros64(hash<<p|1<<(p-1)) + 1)
+		if rho > sketch[1+i] {
+			sketch[1+i] = rho
+		}
+		sketch[0]++
+	}
+
+	return [][]int64{sketch}, nil
+}
+
+func NewCardinalityMerge() any {
+	return &CardinalityMerge{}
+}
+
+type CardinalityMerge struct {
+	base.IReduce[[]int64]
+	function.IOnlyCall
+}
+
+func (this *CardinalityMerge) Call(v1 []int64, v2 []int64, context api.IContext) ([]int64, error) {
+	v1[0] += v2[0]
+	for i := 1; i < len(v1); i++ {
+		if v2[i] > v1[i] {
+			v1[i] = v2[i]
+		}
+	}
+	return v1, nil
+}
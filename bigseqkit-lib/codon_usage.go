@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/iterator"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// countCodons adds the codons of frame 1 of a sequence to the counters, a trailing incomplete codon is ignored.
+func countCodons(sequence []byte, counts map[int64]int64) {
+	counts[bigseqkit.CodonUsageRecords]++
+	if len(sequence)%3 != 0 {
+		counts[bigseqkit.CodonUsageIncomplete]++
+	}
+	for i := 0; i+3 <= len(sequence); i += 3 {
+		if idx := bigseqkit.CodonIndex(sequence[i : i+3]); idx >= 0 {
+			counts[int64(idx)]++
+		} else {
+			counts[bigseqkit.CodonUsageAmbiguous]++
+		}
+	}
+}
+
+func NewCodonUsage() any {
+	return &CodonUsage{}
+}
+
+type CodonUsage struct {
+	base.IMapPartitions[string, map[int64]int64]
+	function.IAfterNone
+	opts     bigseqkit.CodonUsageOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *CodonUsage) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.CodonUsageOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+func (this *CodonUsage) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]map[int64]int64, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]int64, 70)
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		countCodons(record.Seq.Seq, counts)
+	}
+
+	return []map[int64]int64{counts}, nil
+}
+
+func NewCodonUsageRecords() any {
+	return &CodonUsageRecords{}
+}
+
+type CodonUsageRecords struct {
+	base.IMapPartitions[string, string]
+	function.IAfterNone
+	opts     bigseqkit.CodonUsageOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *CodonUsageRecords) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.CodonUsageOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call outputs the ID, sense codons, GC3, ENC, codon counts and RSCU of every record. The counts and the RSCU
+// of the 64 codons are separated by commas, in the order of CodonIndex.
+func (this *CodonUsageRecords) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, 100)
+	counts := make(map[int64]int64, 70)
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		for k := range counts {
+			delete(counts, k)
+		}
+		countCodons(record.Seq.Seq, counts)
+		info, err := bigseqkit.NewCodonUsageInfo(counts, *this.opts.TranslTable)
+		if err != nil {
+			return nil, err
+		}
+		enc := "NA"
+		if v := info.ENC(); !math.IsNaN(v) {
+			enc = fmt.Sprintf("%.2f", v)
+		}
+		var codons, rscu strings.Builder
+		for i, v := range info.RSCU() {
+			if i > 0 {
+				codons.WriteByte(',')
+				rscu.WriteByte(',')
+			}
+			codons.WriteString(strconv.FormatInt(info.Counts[i], 10))
+			rscu.WriteString(strconv.FormatFloat(v, 'f', 3, 64))
+		}
+		result = append(result, fmt.Sprintf("%s\t%d\t%.4f\t%s\t%s\t%s", record.ID, info.Codons(), info.GC3(), enc,
+			codons.String(), rscu.String()))
+	}
+
+	return result, nil
+}
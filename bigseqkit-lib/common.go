@@ -2,7 +2,6 @@ package main
 
 import (
 	"bigseqkit"
-	"bytes"
 	"fmt"
 	"github.com/cespare/xxhash/v2"
 	"github.com/shenwei356/bio/seq"
@@ -13,7 +12,9 @@ import (
 	"ignis/executor/api/ipair"
 	"ignis/executor/api/iterator"
 	"io"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 func NewCommonPrepare() any {
@@ -31,20 +32,13 @@ type commonPrepare struct {
 func (this *commonPrepare) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.CommonOptions](context.Vars()["opts"].(string))
 	this.id = context.Vars()["id"].(string)
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
 	seq.ValidateSeq = false
 
-	if *this.opts.BySeq && *this.opts.ByName {
-		return fmt.Errorf("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
-	}
-
-	revcom := !*this.opts.OnlyPositiveStrand
-
-	if !revcom && !*this.opts.BySeq {
-		return fmt.Errorf("flag -s (--by-seq) needed when using -P (--only-positive-strand)")
-	}
-
 	return err
 }
 
@@ -73,37 +67,10 @@ func (this *commonPrepare) Call(v1 iterator.IReadIterator[string], context api.I
 			fastx.ForcelyOutputFastq = true
 		}
 
-		var subject uint64
-		if *this.opts.BySeq {
-			if revcom {
-				if *this.opts.IgnoreCase {
-					subject = xxhash.Sum64(bytes.ToLower(record.Seq.Seq))
-				} else {
-					subject = xxhash.Sum64(record.Seq.Seq)
-				}
-				if *this.opts.IgnoreCase {
-					subject = xxhash.Sum64(bytes.ToLower(record.Seq.RevComInplace().Seq))
-				} else {
-					subject = xxhash.Sum64(record.Seq.RevComInplace().Seq)
-				}
-			}
-		} else if *this.opts.ByName {
-			if *this.opts.IgnoreCase {
-				subject = xxhash.Sum64(bytes.ToLower(record.Name))
-			} else {
-				subject = xxhash.Sum64(record.Name)
-			}
-		} else { // byID
-			if *this.opts.IgnoreCase {
-				subject = xxhash.Sum64(bytes.ToLower(record.ID))
-			} else {
-				subject = xxhash.Sum64(record.ID)
-			}
-		}
-
-		record.ID = append(record.ID, []byte("_"+this.id)...)
+		subject := xxhash.Sum64(recordKey(record, *this.opts.BySeq, *this.opts.ByName, *this.opts.IgnoreCase, revcom))
 
-		result = append(result, *ipair.New(int64(subject), string(record.Format(*this.opts.Config.LineWidth))))
+		bb := record.Format(*this.opts.Config.LineWidth)
+		result = append(result, *ipair.New(int64(subject), this.id+string(bb[:len(bb)-1])))
 	}
 
 	return result, nil
@@ -137,15 +104,35 @@ func (this *CommonJoin) Call(v ipair.IPair[int64, []string], context api.IContex
 
 	result := make([]string, 0, len(v.Second))
 
-	reader := NewArrayIterator(v.Second)
+	// every record is prefixed with the number of the file it comes from
+	files := make([]int, len(v.Second))
+	records := make([]string, len(v.Second))
+	for j, e := range v.Second {
+		sep := strings.IndexAny(e, ">@")
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid common record: %s", e)
+		}
+		i, err := strconv.Atoi(e[:sep])
+		if err != nil {
+			return nil, err
+		}
+		files[j] = i
+		records[j] = e[sep:]
+	}
+
+	reader := NewArrayIterator(records)
 	fastxReader, err := NewSeqParser(this.alphabet, reader, *this.opts.Config.IDRegexp)
 	if err != nil {
 		return nil, err
 	}
 
-	counter := make(map[string][]int)
+	// a group can have several keys if their hashes collide, the record of a key is the one of the first file,
+	// the lowest if there are several, so the output does not depend on the order of the group
+	found := make(map[string][]bool)
+	first := make(map[string]string)
+	revcom := !*this.opts.OnlyPositiveStrand
 
-	for {
+	for j := 0; ; j++ {
 		record, err := fastxReader.Read()
 		if err != nil {
 			if err == io.EOF {
@@ -158,54 +145,33 @@ func (this *CommonJoin) Call(v ipair.IPair[int64, []string], context api.IContex
 			fastx.ForcelyOutputFastq = true
 		}
 
-		sep := bytes.LastIndexByte(record.ID, '_')
-		i, err := strconv.Atoi(string(record.ID[sep+1:]))
-		revcom := !*this.opts.OnlyPositiveStrand
-		if err != nil {
-			return nil, err
+		subject := string(recordKey(record, *this.opts.BySeq, *this.opts.ByName, *this.opts.IgnoreCase, revcom))
+
+		if _, ok := found[subject]; !ok {
+			found[subject] = make([]bool, this.ids+1)
 		}
-		record.ID = record.ID[:sep]
-
-		var subject string
-		if *this.opts.BySeq {
-			if revcom {
-				if *this.opts.IgnoreCase {
-					subject = string(bytes.ToLower(record.Seq.Seq))
-				} else {
-					subject = string(record.Seq.Seq)
-				}
-				if *this.opts.IgnoreCase {
-					subject = string(bytes.ToLower(record.Seq.RevComInplace().Seq))
-				} else {
-					subject = string(record.Seq.RevComInplace().Seq)
-				}
-			}
-		} else if *this.opts.ByName {
-			if *this.opts.IgnoreCase {
-				subject = string(bytes.ToLower(record.Name))
-			} else {
-				subject = string(record.Name)
-			}
-		} else { // byID
-			if *this.opts.IgnoreCase {
-				subject = string(bytes.ToLower(record.ID))
-			} else {
-				subject = string(record.ID)
-			}
+		found[subject][files[j]] = true
+		if r, ok := first[subject]; files[j] == 1 && (!ok || records[j] < r) {
+			first[subject] = records[j]
 		}
+	}
 
-		if n, ok := counter[subject]; ok {
-			if n[0] == this.ids {
-				result = append(result, string(record.Format(*this.opts.Config.LineWidth)))
-				delete(counter, subject)
+	subjects := make([]string, 0, len(found))
+	for subject, in := range found {
+		shared := true
+		for i := 1; i <= this.ids; i++ {
+			if !in[i] {
+				shared = false
+				break
 			}
-			counter[subject][0]++
-			counter[subject][i] = 1
-		} else {
-			counter[subject] = make([]int, this.ids+1)
-			counter[subject][0] = 1
-			counter[subject][i] = 1
 		}
+		if shared {
+			subjects = append(subjects, subject)
+		}
+	}
+	sort.Strings(subjects)
+	for _, subject := range subjects {
+		result = append(result, first[subject])
 	}
 
 	return result, nil
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bigseqkit"
+	"github.com/shenwei356/bio/seq"
+	"github.com/shenwei356/bio/seqio/fastx"
+	"ignis/executor/api/ipair"
+	"reflect"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func testCommonOptions(onlyPositiveStrand, ignoreCase bool) bigseqkit.CommonOptions {
+	return bigseqkit.CommonOptions{
+		Config: bigseqkit.KitConfig{
+			SeqType:                ptr("dna"),
+			LineWidth:              ptr(0),
+			IDRegexp:               ptr(fastx.DefaultIDRegexp),
+			IDNCBI:                 ptr(false),
+			Quiet:                  ptr(true),
+			AlphabetGuessSeqLength: ptr(10000),
+			ValidateSeqLength:      ptr(10000),
+		},
+		ByName:             ptr(false),
+		BySeq:              ptr(true),
+		IgnoreCase:         ptr(ignoreCase),
+		OnlyPositiveStrand: ptr(onlyPositiveStrand),
+	}
+}
+
+func TestRecordKeyCanonical(t *testing.T) {
+	record := func(s string) *fastx.Record {
+		r, _ := fastx.NewRecord(seq.DNAredundant, []byte("r"), []byte("r"), []byte(""), []byte(s))
+		return r
+	}
+	forward, reverse := record("ACGTTG"), record("CAACGT")
+
+	if a, b := recordKey(forward, true, false, false, true), recordKey(reverse, true, false, false, true); string(a) != string(b) {
+		t.Errorf("both strands: keys of reverse complements differ: %s %s", a, b)
+	}
+	if a, b := recordKey(forward, true, false, false, false), recordKey(reverse, true, false, false, false); string(a) == string(b) {
+		t.Errorf("positive strand: keys of reverse complements are equal: %s", a)
+	}
+	if a, b := recordKey(record("acgttg"), true, false, true, true), recordKey(reverse, true, false, true, true); string(a) != string(b) {
+		t.Errorf("ignore case: keys differ: %s %s", a, b)
+	}
+}
+
+func TestCommonJoinReverseComplement(t *testing.T) {
+	tests := []struct {
+		name               string
+		onlyPositiveStrand bool
+		group              []string
+		want               []string
+	}{
+		{
+			name:  "reverse complement in the second file",
+			group: []string{"2>b\nCAACGT", "1>a2\nACGTTG", "1>a\nACGTTG"},
+			want:  []string{">a\nACGTTG"},
+		},
+		{
+			name:               "only positive strand",
+			onlyPositiveStrand: true,
+			group:              []string{"2>b\nCAACGT", "1>a\nACGTTG"},
+			want:               []string{},
+		},
+		{
+			name:  "only in the first file",
+			group: []string{"1>a\nACGTTG", "1>c\nCAACGT"},
+			want:  []string{},
+		},
+	}
+	for _, test := range tests {
+		join := &CommonJoin{opts: testCommonOptions(test.onlyPositiveStrand, false), alphabet: seq.DNAredundant, ids: 2}
+		got, err := join.Call(*ipair.New(int64(0), test.group), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
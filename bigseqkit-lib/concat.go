@@ -3,6 +3,7 @@ package main
 import (
 	"bigseqkit"
 	"bytes"
+	"fmt"
 	"github.com/shenwei356/bio/seq"
 	"github.com/shenwei356/bio/seqio/fastx"
 	"ignis/executor/api"
@@ -12,6 +13,7 @@ import (
 	"ignis/executor/api/iterator"
 	"io"
 	"strconv"
+	"strings"
 )
 
 func NewConcatPrepare() any {
@@ -59,15 +61,99 @@ func (this *ConcatPrepare) Call(v1 iterator.IReadIterator[string], context api.I
 			fastx.ForcelyOutputFastq = true
 		}
 
-		id := string(record.ID)
-		record.ID = append(record.ID, []byte("_"+this.id)...)
-
-		result = append(result, *ipair.New(id, string(record.Format(*this.opts.Config.LineWidth))))
+		result = append(result, *ipair.New(string(record.ID), this.id+string(record.Format(*this.opts.Config.LineWidth))))
 	}
 
 	return result, nil
 }
 
+// concatSplit separates the input index prefixed by ConcatPrepare from the record.
+func concatSplit(v string) (int, string, error) {
+	sep := strings.IndexAny(v, ">@")
+	if sep < 1 {
+		return 0, "", fmt.Errorf("input index expected")
+	}
+	i, err := strconv.Atoi(v[:sep])
+	if err != nil {
+		return 0, "", err
+	}
+	return i, v[sep:], nil
+}
+
+func NewConcatLengths() any {
+	return &ConcatLengths{}
+}
+
+type ConcatLengths struct {
+	base.IMapPartitions[ipair.IPair[string, string], map[int64]int64]
+	function.IAfterNone
+	opts     bigseqkit.ConcatOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *ConcatLengths) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.ConcatOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call returns the length of the longest sequence of every input in the partition.
+func (this *ConcatLengths) Call(v1 iterator.IReadIterator[ipair.IPair[string, string]], context api.IContext) ([]map[int64]int64, error) {
+	lengths := make(map[int64]int64)
+	inputs := make([]int, 0, 100)
+	records := make([]string, 0, 100)
+
+	for v1.HasNext() {
+		v, err := v1.Next()
+		if err != nil {
+			return nil, err
+		}
+		i, record, err := concatSplit(v.Second)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, i)
+		records = append(records, record)
+	}
+
+	fastxReader, err := NewSeqParser(this.alphabet, NewArrayIterator(records), *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range inputs {
+		record, err := fastxReader.Read()
+		if err != nil {
+			return nil, err
+		}
+		if l := int64(len(record.Seq.Seq)); l > lengths[int64(i)] {
+			lengths[int64(i)] = l
+		}
+	}
+
+	return []map[int64]int64{lengths}, nil
+}
+
+func NewConcatLengthsReduce() any {
+	return &ConcatLengthsReduce{}
+}
+
+type ConcatLengthsReduce struct {
+	base.IReduce[map[int64]int64]
+	function.IOnlyCall
+}
+
+func (this *ConcatLengthsReduce) Call(v1 map[int64]int64, v2 map[int64]int64, context api.IContext) (map[int64]int64, error) {
+	for k, v := range v2 {
+		if v > v1[k] {
+			v1[k] = v
+		}
+	}
+	return v1, nil
+}
+
 func NewConcatJoin() any {
 	return &ConcatJoin{}
 }
@@ -77,10 +163,12 @@ type ConcatJoin struct {
 	function.IAfterNone
 	opts     bigseqkit.ConcatOptions
 	alphabet *seq.Alphabet
+	lengths  []int64
 }
 
 func (this *ConcatJoin) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.ConcatOptions](context.Vars()["opts"].(string))
+	this.lengths = bigseqkit.StringToOptions[[]int64](context.Vars()["lengths"].(string))
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
 	seq.ValidateSeq = false
@@ -90,74 +178,109 @@ func (this *ConcatJoin) Before(context api.IContext) (err error) {
 }
 
 func (this *ConcatJoin) Call(v ipair.IPair[string, []string], context api.IContext) ([]string, error) {
-	if len(v.Second) == 1 && *this.opts.Full {
-		return v.Second, nil
-	}
-
 	result := make([]string, 0, len(v.Second))
+	n := len(this.lengths)
+
+	inputs := make([]int, len(v.Second))
+	records := make([]string, len(v.Second))
+	for j, e := range v.Second {
+		i, record, err := concatSplit(e)
+		if err != nil {
+			return nil, err
+		}
+		if i >= n {
+			return nil, fmt.Errorf("input index out of range: %d", i)
+		}
+		inputs[j], records[j] = i, record
+	}
 
-	reader := NewArrayIterator(v.Second)
-	fastxReader, err := NewSeqParser(this.alphabet, reader, *this.opts.Config.IDRegexp)
+	fastxReader, err := NewSeqParser(this.alphabet, NewArrayIterator(records), *this.opts.Config.IDRegexp)
 	if err != nil {
 		return nil, err
 	}
 
-	seqs := [][]*fastx.Record{make([]*fastx.Record, 0, len(v.Second)), make([]*fastx.Record, 0, len(v.Second))}
-	separator := []byte(*this.opts.Separator)
-
-	for {
+	seqs := make([][]*fastx.Record, n)
+	for _, i := range inputs {
 		record, err := fastxReader.Read()
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
 			return nil, err
 		}
 		if fastxReader.IsFastq {
 			*this.opts.Config.LineWidth = 0
 			fastx.ForcelyOutputFastq = true
 		}
+		seqs[i] = append(seqs[i], record.Clone())
+	}
 
-		sep := bytes.LastIndexByte(record.ID, '_')
-		i, err := strconv.Atoi(string(record.ID[sep+1:]))
-		if err != nil {
-			return nil, err
+	fill := *this.opts.Fill
+	for i := range seqs {
+		if len(seqs[i]) > 0 {
+			continue
+		}
+		if !*this.opts.Full {
+			return result, nil
+		}
+		if len(fill) > 0 { // missing block
+			block := &fastx.Record{Seq: &seq.Seq{Seq: bytes.Repeat([]byte(fill), int(this.lengths[i]))}}
+			if fastxReader.IsFastq {
+				block.Seq.Qual = bytes.Repeat([]byte{'!'}, int(this.lengths[i]))
+			}
+			seqs[i] = []*fastx.Record{block}
 		}
-		record.ID = record.ID[:sep]
-
-		seqs[i] = append(seqs[i], record)
 	}
 
-	if (len(seqs[0]) == 0 || len(seqs[1]) == 0) && *this.opts.Full {
-		var ifull int
-		if len(seqs[0]) == 0 {
-			ifull = 1
-		} else {
-			ifull = 0
+	separator := []byte(*this.opts.Separator)
+	selected := make([]int, n) // Cartesian product of the records of every input
+	for {
+		var first *fastx.Record
+		descs := make([][]byte, 0, n)
+		seqBlocks := make([][]byte, 0, n)
+		qualBlocks := make([][]byte, 0, n)
+		for i := range seqs {
+			if len(seqs[i]) == 0 {
+				continue
+			}
+			block := seqs[i][selected[i]]
+			if block.ID != nil {
+				if first == nil {
+					first = block
+				}
+				if len(descs) > 0 {
+					descs = append(descs, separator)
+				}
+				descs = append(descs, block.Desc)
+			}
+			seqBlocks = append(seqBlocks, block.Seq.Seq)
+			qualBlocks = append(qualBlocks, block.Seq.Qual)
 		}
 
-		for _, record := range seqs[ifull] {
-			seqBB := record.Format(*this.opts.Config.LineWidth)
-			result = append(result, string(seqBB[:len(seqBB)-1]))
+		record := &fastx.Record{
+			ID:   first.ID,
+			Name: first.ID,
+			Desc: mergeBytes(descs...),
+			Seq: &seq.Seq{
+				Alphabet: first.Seq.Alphabet,
+				Seq:      mergeBytes(seqBlocks...),
+				Qual:     mergeBytes(qualBlocks...),
+			},
 		}
-		return result, nil
-	}
 
-	for _, recordA := range seqs[0] {
-		for _, recordB := range seqs[1] {
-			record := &fastx.Record{
-				ID:   recordA.ID,
-				Name: recordA.ID,
-				Desc: mergeBytes(recordA.Desc, separator, recordB.Desc),
-				Seq: &seq.Seq{
-					Alphabet: recordA.Seq.Alphabet,
-					Seq:      mergeBytes(recordA.Seq.Seq, recordB.Seq.Seq),
-					Qual:     mergeBytes(recordA.Seq.Qual, recordB.Seq.Qual),
-				},
-			}
+		seqBB := record.Format(*this.opts.Config.LineWidth)
+		result = append(result, string(seqBB[:len(seqBB)-1]))
 
-			seqBB := record.Format(*this.opts.Config.LineWidth)
-			result = append(result, string(seqBB[:len(seqBB)-1]))
+		i := n - 1
+		for ; i >= 0; i-- {
+			if len(seqs[i]) == 0 {
+				continue
+			}
+			selected[i]++
+			if selected[i] < len(seqs[i]) {
+				break
+			}
+			selected[i] = 0
+		}
+		if i < 0 {
+			break
 		}
 	}
 
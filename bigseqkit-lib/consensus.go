@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bigseqkit"
+	"bytes"
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"github.com/shenwei356/bio/seqio/fastx"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/ipair"
+	"ignis/executor/api/iterator"
+	log "ignis/executor/core/logger"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func NewConsensusHeader() any {
+	return &ConsensusHeader{}
+}
+
+type ConsensusHeader struct {
+	base.IFilter[string]
+	function.IOnlyCall
+}
+
+func (this *ConsensusHeader) Call(v string, context api.IContext) (bool, error) {
+	return strings.HasPrefix(v, "#CHROM"), nil
+}
+
+func NewConsensusKeyRecords() any {
+	return &ConsensusKeyRecords{}
+}
+
+type ConsensusKeyRecords struct {
+	base.IMapPartitions[string, ipair.IPair[string, string]]
+	function.IAfterNone
+	opts     bigseqkit.ConsensusOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *ConsensusKeyRecords) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.ConsensusOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call keys the records by their chromosome (ID in lower case), tagged with "0".
+func (this *ConsensusKeyRecords) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]ipair.IPair[string, string], error) {
+	result := make([]ipair.IPair[string, string], 0, 100)
+
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if fastxReader.IsFastq {
+			return nil, fmt.Errorf("consensus requires FASTA records, FASTQ found: %s", record.Name)
+		}
+
+		result = append(result, *ipair.New(strings.ToLower(string(record.ID)), "0"+string(record.Format(0))))
+	}
+
+	return result, nil
+}
+
+func NewConsensusKeyVariants() any {
+	return &ConsensusKeyVariants{}
+}
+
+type ConsensusKeyVariants struct {
+	base.IMapPartitions[string, ipair.IPair[string, string]]
+	function.IOnlyCall
+}
+
+// Call keys the VCF data lines by their chromosome in lower case, tagged with "1". Header lines are dropped.
+func (this *ConsensusKeyVariants) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]ipair.IPair[string, string], error) {
+	result := make([]ipair.IPair[string, string], 0, 100)
+
+	for v1.HasNext() {
+		line, err := v1.Next()
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, '\t')
+		if i < 1 {
+			return nil, fmt.Errorf("bad vcf line: %s", line)
+		}
+		result = append(result, *ipair.New(strings.ToLower(line[:i]), "1"+line))
+	}
+
+	return result, nil
+}
+
+// consensusVariant is a variant with the allele that will be applied to the reference.
+type consensusVariant struct {
+	pos    int // 0-based
+	ref    string
+	allele string
+	line   string
+}
+
+// iupacCodes maps a sorted set of bases to its IUPAC ambiguity code.
+var iupacCodes = map[string]byte{
+	"A": 'A', "C": 'C', "G": 'G', "T": 'T',
+	"AC": 'M', "AG": 'R', "AT": 'W', "CG": 'S', "CT": 'Y', "GT": 'K',
+	"ACG": 'V', "ACT": 'H', "AGT": 'D', "CGT": 'B', "ACGT": 'N',
+}
+
+func iupacCode(alleles []string) (byte, bool) {
+	bases := make([]byte, 0, len(alleles))
+	for _, a := range alleles {
+		if len(a) != 1 {
+			return 0, false
+		}
+		b := a[0] &^ 0x20 // upper case
+		if bytes.IndexByte(bases, b) < 0 {
+			bases = append(bases, b)
+		}
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+	code, ok := iupacCodes[string(bases)]
+	return code, ok
+}
+
+// parseConsensusVariant selects the allele of a VCF line. A nil variant is returned when there is nothing to
+// apply: filtered, symbolic, missing or homozygous reference genotypes.
+func parseConsensusVariant(line string, sample int, opts *bigseqkit.ConsensusOptions) (*consensusVariant, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 8 {
+		return nil, fmt.Errorf("bad vcf line: %s", line)
+	}
+	if *opts.PassOnly && fields[6] != "PASS" {
+		return nil, nil
+	}
+	pos, err := strconv.Atoi(fields[1])
+	if err != nil || pos < 1 {
+		return nil, fmt.Errorf("bad vcf position: %s", line)
+	}
+	alleles := append([]string{fields[3]}, strings.Split(fields[4], ",")...)
+
+	gt := []int{1}
+	if sample >= 0 {
+		if len(fields) <= sample {
+			return nil, fmt.Errorf("sample column not found in vcf line: %s", line)
+		}
+		gtIndex := -1
+		for i, key := range strings.Split(fields[8], ":") {
+			if key == "GT" {
+				gtIndex = i
+				break
+			}
+		}
+		if gtIndex < 0 {
+			return nil, fmt.Errorf("GT field not found in vcf line: %s", line)
+		}
+		values := strings.Split(fields[sample], ":")
+		if gtIndex >= len(values) {
+			return nil, nil
+		}
+		gt = gt[:0]
+		for _, a := range strings.FieldsFunc(values[gtIndex], func(r rune) bool { return r == '/' || r == '|' }) {
+			if a == "." {
+				return nil, nil
+			}
+			i, err := strconv.Atoi(a)
+			if err != nil || i >= len(alleles) {
+				return nil, fmt.Errorf("bad genotype: %s", line)
+			}
+			gt = append(gt, i)
+		}
+	}
+
+	called := make([]string, 0, len(gt))
+	alt := -1
+	for _, i := range gt {
+		called = append(called, alleles[i])
+		if alt < 0 && i > 0 {
+			alt = i
+		}
+	}
+	if alt < 0 {
+		return nil, nil
+	}
+	allele := alleles[alt]
+	if allele == "*" || allele == "." || strings.ContainsAny(allele, "<>[]") {
+		return nil, nil
+	}
+
+	if *opts.Iupac && len(fields[3]) == 1 {
+		if code, ok := iupacCode(called); ok {
+			allele = string(code)
+		}
+	}
+
+	return &consensusVariant{pos: pos - 1, ref: fields[3], allele: allele, line: line}, nil
+}
+
+// consensusChain builds the alignment blocks of the chain between the reference and the consensus.
+type consensusChain struct {
+	blocks         [][3]int // size, gap in the reference, gap in the consensus
+	size           int
+	tStart, qStart int // an indel at the start of the sequences moves the start of the chain
+}
+
+func (this *consensusChain) gap(dt, dq int) {
+	if this.size == 0 && len(this.blocks) == 0 {
+		this.tStart += dt
+		this.qStart += dq
+		return
+	}
+	if this.size == 0 {
+		this.blocks[len(this.blocks)-1][1] += dt
+		this.blocks[len(this.blocks)-1][2] += dq
+		return
+	}
+	this.blocks = append(this.blocks, [3]int{this.size, dt, dq})
+	this.size = 0
+}
+
+// format returns the chain without its ID, ConsensusChainId appends it to the header line.
+func (this *consensusChain) format(name string, tSize, qSize int) string {
+	blocks, size := this.blocks, this.size
+	tEnd, qEnd := tSize, qSize
+	if size == 0 && len(blocks) > 0 {
+		// an indel at the end of the sequences moves the end of the chain
+		last := blocks[len(blocks)-1]
+		blocks, size = blocks[:len(blocks)-1], last[0]
+		tEnd -= last[1]
+		qEnd -= last[2]
+	}
+	score := size
+	for _, b := range blocks {
+		score += b[0]
+	}
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("chain %d %s %d + %d %d %s %d + %d %d\n", score, name, tSize, this.tStart, tEnd,
+		name, qSize, this.qStart, qEnd))
+	for _, b := range blocks {
+		buf.WriteString(fmt.Sprintf("%d\t%d\t%d\n", b[0], b[1], b[2]))
+	}
+	buf.WriteString(fmt.Sprintf("%d\n", size))
+	return buf.String()
+}
+
+func NewConsensusApply() any {
+	return &ConsensusApply{}
+}
+
+type ConsensusApply struct {
+	base.IFlatmap[ipair.IPair[string, []string], ipair.IPair[string, string]]
+	function.IAfterNone
+	opts     bigseqkit.ConsensusOptions
+	alphabet *seq.Alphabet
+	sample   int
+}
+
+func (this *ConsensusApply) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.ConsensusOptions](context.Vars()["opts"].(string))
+	this.sample = context.Vars()["sample"].(int)
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call applies the variants of a chromosome to its records, it returns pairs of consensus record and mapping.
+func (this *ConsensusApply) Call(v ipair.IPair[string, []string], context api.IContext) ([]ipair.IPair[string, string], error) {
+	records := make([]string, 0, 1)
+	variants := make([]*consensusVariant, 0, len(v.Second))
+	for _, e := range v.Second {
+		if e[0] == '0' {
+			records = append(records, e[1:])
+			continue
+		}
+		variant, err := parseConsensusVariant(e[1:], this.sample, &this.opts)
+		if err != nil {
+			return nil, err
+		}
+		if variant != nil {
+			variants = append(variants, variant)
+		}
+	}
+	if len(records) == 0 {
+		if len(variants) > 0 {
+			chr := variants[0].line[:strings.IndexByte(variants[0].line, '\t')]
+			if *this.opts.Conflict == "error" {
+				return nil, fmt.Errorf("%d variants of a chromosome without reference: %s", len(variants), chr)
+			}
+			log.Warn(fmt.Sprintf("%d variants of a chromosome without reference skipped: %s", len(variants), chr))
+		}
+		return nil, nil
+	}
+	sort.SliceStable(variants, func(i, j int) bool { return variants[i].pos < variants[j].pos })
+
+	fastxReader, err := NewSeqParser(this.alphabet, NewArrayIterator(records), *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ipair.IPair[string, string], 0, len(records))
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		ref := record.Seq.Seq
+		name := string(record.ID)
+		consensus := make([]byte, 0, len(ref))
+		var chain consensusChain
+		var offsets strings.Builder
+		cursor := 0
+		for _, variant := range variants {
+			end := variant.pos + len(variant.ref)
+			if variant.pos < cursor {
+				if *this.opts.Conflict == "error" {
+					return nil, fmt.Errorf("overlapping variant: %s", variant.line)
+				}
+				log.Warn(fmt.Sprintf("overlapping variant skipped: %s", variant.line))
+				continue
+			}
+			if end > len(ref) {
+				return nil, fmt.Errorf("variant out of the range of %s (%d bp): %s", name, len(ref), variant.line)
+			}
+			if !bytes.EqualFold(ref[variant.pos:end], []byte(variant.ref)) {
+				return nil, fmt.Errorf("REF mismatch with %s (%s): %s", name, ref[variant.pos:end], variant.line)
+			}
+
+			consensus = append(consensus, ref[cursor:variant.pos]...)
+			consensus = append(consensus, variant.allele...)
+			aligned := len(variant.ref)
+			if len(variant.allele) < aligned {
+				aligned = len(variant.allele)
+			}
+			chain.size += variant.pos - cursor + aligned
+			if len(variant.ref) != len(variant.allele) {
+				chain.gap(len(variant.ref)-aligned, len(variant.allele)-aligned)
+				offsets.WriteString(fmt.Sprintf("%s\t%d\t%d\n", name, end+1, len(consensus)-end))
+			}
+			cursor = end
+		}
+		consensus = append(consensus, ref[cursor:]...)
+		chain.size += len(ref) - cursor
+
+		newRecord, err := fastx.NewRecordWithoutValidation(record.Seq.Alphabet, record.ID, record.Name, record.Desc, consensus)
+		if err != nil {
+			return nil, err
+		}
+
+		var mapping string
+		if *this.opts.MapFormat == "chain" {
+			mapping = chain.format(name, len(ref), len(consensus))
+		} else {
+			mapping = strings.TrimSuffix(offsets.String(), "\n")
+		}
+		result = append(result, *ipair.New(string(newRecord.Format(*this.opts.Config.LineWidth)), mapping))
+	}
+
+	return result, nil
+}
+
+func NewConsensusRecord() any {
+	return &ConsensusRecord{}
+}
+
+type ConsensusRecord struct {
+	base.IMap[ipair.IPair[string, string], string]
+	function.IOnlyCall
+}
+
+func (this *ConsensusRecord) Call(v ipair.IPair[string, string], context api.IContext) (string, error) {
+	return v.First, nil
+}
+
+func NewConsensusMapping() any {
+	return &ConsensusMapping{}
+}
+
+type ConsensusMapping struct {
+	base.IFlatmap[ipair.IPair[string, string], string]
+	function.IOnlyCall
+}
+
+// Call drops the empty mappings, records without indels have no offsets.
+func (this *ConsensusMapping) Call(v ipair.IPair[string, string], context api.IContext) ([]string, error) {
+	if v.Second == "" {
+		return nil, nil
+	}
+	return []string{v.Second}, nil
+}
+
+func NewConsensusChainId() any {
+	return &ConsensusChainId{}
+}
+
+type ConsensusChainId struct {
+	base.IMapWithIndex[string, string]
+	function.IOnlyCall
+}
+
+// Call appends the ID to the header line of a chain, the 1-based index of the chain in the whole DataFrame.
+func (this *ConsensusChainId) Call(v1 int64, v2 string, context api.IContext) (string, error) {
+	i := strings.IndexByte(v2, '\n')
+	return v2[:i] + " " + strconv.FormatInt(v1+1, 10) + v2[i:], nil
+}
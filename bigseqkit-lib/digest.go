@@ -0,0 +1,629 @@
+package main
+
+import (
+	"bigseqkit"
+	"bytes"
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"github.com/shenwei356/bio/seqio/fastx"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/ipair"
+	"ignis/executor/api/iterator"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// digestEnzyme is a restriction site with the cut of the top strand for matches on both strands, as
+// offsets from the first base of the match in the positive strand.
+type digestEnzyme struct {
+	name       string
+	re, reRC   *regexp.Regexp // reRC is nil for palindromic sites
+	cut, cutRC int
+	size       int
+}
+
+var reDigestSite = regexp.MustCompile(`^([A-Za-z^]+)(?:\((-?\d+)/(-?\d+)\))?$`)
+
+// parseDigestSite parses a site in REBASE notation, e.g. G^AATTC or GGTCTC(1/5). As in Locate, degenerate
+// bases are searched with regular expressions.
+func parseDigestSite(name, site string) (*digestEnzyme, error) {
+	m := reDigestSite.FindStringSubmatch(strings.TrimSpace(site))
+	if m == nil || strings.Count(m[1], "^") > 1 || strings.Contains(m[1], "^") == (m[2] != "") {
+		return nil, fmt.Errorf("invalid site of enzyme %s: %s, use a cut mark '^' or (n/m)", name, site)
+	}
+	top, bottom := strings.Index(m[1], "^"), 0
+	p := bytes.ToUpper([]byte(strings.Replace(m[1], "^", "", 1)))
+	if seq.DNAredundant.IsValid(p) != nil {
+		return nil, fmt.Errorf("invalid site of enzyme %s: %s", name, site)
+	}
+	if m[2] != "" {
+		n, _ := strconv.Atoi(m[2])
+		m, _ := strconv.Atoi(m[3])
+		top, bottom = len(p)+n, len(p)+m
+	} else {
+		bottom = len(p) - top
+	}
+
+	s, err := seq.NewSeqWithoutValidation(seq.DNAredundant, p)
+	if err != nil {
+		return nil, err
+	}
+	enzyme := &digestEnzyme{name: name, cut: top, cutRC: len(p) - bottom, size: len(p)}
+	if enzyme.re, err = regexp.Compile(s.Degenerate2Regexp()); err != nil {
+		return nil, err
+	}
+	if rc := s.RevCom(); !bytes.Equal(rc.Seq, p) {
+		if enzyme.reRC, err = regexp.Compile(rc.Degenerate2Regexp()); err != nil {
+			return nil, err
+		}
+	} else if enzyme.cut != enzyme.cutRC {
+		return nil, fmt.Errorf("asymmetric cut of palindromic site of enzyme %s: %s", name, site)
+	}
+	return enzyme, nil
+}
+
+// digestFragment is a fragment [start, end) of the positive strand with the enzymes that cut its ends.
+type digestFragment struct {
+	start, end  int
+	left, right string
+}
+
+type digestBase struct {
+	opts     bigseqkit.DigestOptions
+	alphabet *seq.Alphabet
+	enzymes  []*digestEnzyme
+}
+
+func (this *digestBase) before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.DigestOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	if err != nil {
+		return err
+	}
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+
+	sites := make(map[string]string, len(bigseqkit.RestrictionEnzymes))
+	for name, site := range bigseqkit.RestrictionEnzymes {
+		sites[strings.ToLower(name)] = name + "\t" + site
+	}
+	if *this.opts.EnzymeFile != "" {
+		data, err := os.ReadFile(*this.opts.EnzymeFile)
+		if err != nil {
+			return fmt.Errorf("read enzyme file: %s", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimRight(line, "\r")
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			items := strings.Split(line, "\t")
+			if len(items) < 2 {
+				return fmt.Errorf("enzyme file should have two tab-delimited columns (name, site): %s", line)
+			}
+			sites[strings.ToLower(items[0])] = items[0] + "\t" + items[1]
+		}
+	}
+
+	for _, name := range *this.opts.Enzymes {
+		entry, ok := sites[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("unknown enzyme: %s", name)
+		}
+		items := strings.Split(entry, "\t")
+		enzyme, err := parseDigestSite(items[0], items[1])
+		if err != nil {
+			return err
+		}
+		this.enzymes = append(this.enzymes, enzyme)
+	}
+	return nil
+}
+
+// digestSep separates the fields of the chunks and pieces of the sequences.
+const digestSep = "\x00"
+
+// margin returns the number of bases around a chunk needed to find the sites of the cuts inside the chunk.
+func (this *digestBase) margin() int {
+	margin := 0
+	for _, enzyme := range this.enzymes {
+		for _, cut := range []int{enzyme.cut, enzyme.cutRC} {
+			if cut < 0 {
+				cut = -cut
+			}
+			if enzyme.size+cut > margin {
+				margin = enzyme.size + cut
+			}
+		}
+	}
+	return margin
+}
+
+// cuts returns the cuts in [from, to) of the sites of s (in upper case), which starts at the position offset
+// of a sequence of length l. The cuts of circular sequences are wrapped around the origin.
+func (this *digestBase) cuts(s []byte, offset, from, to, l int) map[int][]string {
+	circular := *this.opts.Circular
+	cuts := make(map[int][]string)
+	for _, enzyme := range this.enzymes {
+		for _, strand := range []*regexp.Regexp{enzyme.re, enzyme.reRC} {
+			if strand == nil {
+				continue
+			}
+			cut := enzyme.cut
+			if strand == enzyme.reRC {
+				cut = enzyme.cutRC
+			}
+		MATCH:
+			for _, match := range patternLocate(strand, nil, s, false) {
+				c := offset + match[0] + cut
+				if circular {
+					c = (c%l + l) % l
+				} else if c <= 0 || c >= l {
+					continue
+				}
+				if c < from || c >= to {
+					continue
+				}
+				for _, n := range cuts[c] {
+					if n == enzyme.name {
+						continue MATCH
+					}
+				}
+				cuts[c] = append(cuts[c], enzyme.name)
+			}
+		}
+	}
+	return cuts
+}
+
+// sortedCuts returns the positions of the cuts in order.
+func sortedCuts(cuts map[int][]string) []int {
+	positions := make([]int, 0, len(cuts))
+	for c := range cuts {
+		positions = append(positions, c)
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// fragments returns the fragments of a whole sequence of length l from its cuts.
+func (this *digestBase) fragments(cuts map[int][]string, l int) []digestFragment {
+	positions := sortedCuts(cuts)
+	names := func(c int) string {
+		return strings.Join(cuts[c], "/")
+	}
+
+	fragments := make([]digestFragment, 0, len(positions)+1)
+	if len(positions) == 0 {
+		fragments = append(fragments, digestFragment{0, l, ".", "."})
+	} else if *this.opts.Circular {
+		for i, c := range positions {
+			next := positions[0] + l
+			if i+1 < len(positions) {
+				next = positions[i+1]
+			}
+			fragments = append(fragments, digestFragment{c, next, names(c), names(next % l)})
+		}
+	} else {
+		fragments = append(fragments, digestFragment{0, positions[0], ".", names(positions[0])})
+		for i := 1; i < len(positions); i++ {
+			fragments = append(fragments, digestFragment{positions[i-1], positions[i], names(positions[i-1]), names(positions[i])})
+		}
+		last := positions[len(positions)-1]
+		fragments = append(fragments, digestFragment{last, l, names(last), "."})
+	}
+	return fragments
+}
+
+// keep returns whether the length of a fragment is in the length range.
+func (this *digestBase) keep(f digestFragment) bool {
+	size := f.end - f.start
+	return size >= *this.opts.MinLen && (*this.opts.MaxLen == 0 || size <= *this.opts.MaxLen)
+}
+
+// format returns a fragment of a sequence of length l, with its bases and qualities, as a BED line or a
+// FASTA/Q record.
+func (this *digestBase) format(id string, l int, f digestFragment, s, q []byte) (string, error) {
+	name := f.left + "-" + f.right
+	// fragments crossing the origin of circular sequences end after the sequence length in BED
+	if *this.opts.Bed {
+		return fmt.Sprintf("%s\t%d\t%d\t%s\t%d\t+", id, f.start, f.end, name, f.end-f.start), nil
+	}
+
+	e := f.end
+	if e > l {
+		e -= l
+	}
+	fragmentID := fmt.Sprintf("%s_%d-%d", id, f.start+1, e)
+	var newRecord *fastx.Record
+	var err error
+	if len(q) > 0 {
+		newRecord, err = fastx.NewRecordWithQualWithoutValidation(seq.Unlimit, []byte(fragmentID),
+			[]byte(fragmentID+" "+name), nil, s, q)
+	} else {
+		newRecord, err = fastx.NewRecordWithoutValidation(seq.Unlimit, []byte(fragmentID),
+			[]byte(fragmentID+" "+name), nil, s)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(newRecord.Format(*this.opts.Config.LineWidth)), nil
+}
+
+// digestSlice returns the bases [from, to) of a sequence and the position of the first one. The bases are
+// wrapped around the origin of circular sequences, and clipped to the sequence otherwise.
+func digestSlice(s []byte, from, to int, circular bool) ([]byte, int) {
+	l := len(s)
+	if l == 0 {
+		return nil, from
+	}
+	if !circular {
+		if from < 0 {
+			from = 0
+		}
+		if to > l {
+			to = l
+		}
+		return s[from:to], from
+	}
+	result := make([]byte, 0, to-from)
+	for i := from; i < to; i++ {
+		result = append(result, s[(i%l+l)%l])
+	}
+	return result, from
+}
+
+func NewDigestSplit() any {
+	return &DigestSplit{}
+}
+
+type DigestSplit struct {
+	base.IMapPartitions[string, string]
+	function.IAfterNone
+	digestBase
+}
+
+func (this *DigestSplit) Before(context api.IContext) (err error) {
+	return this.before(context)
+}
+
+// Call splits the records longer than SplitLen into chunks: ID, sequence length, chunk start and end, position
+// of the first base, sequence and quality, separated by digestSep. The chunks include the bases around them
+// needed to find the sites of their cuts. Shorter records are a single chunk without those bases.
+func (this *DigestSplit) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	splitLen, margin := *this.opts.SplitLen, this.margin()
+	result := make([]string, 0, 100)
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		s, q := record.Seq.Seq, record.Seq.Qual
+		l := len(s)
+		if splitLen == 0 || l <= splitLen {
+			result = append(result, strings.Join([]string{string(record.ID), strconv.Itoa(l), "0", strconv.Itoa(l),
+				"0", string(s), string(q)}, digestSep))
+			continue
+		}
+		for start := 0; start < l; start += splitLen {
+			end := start + splitLen
+			if end > l {
+				end = l
+			}
+			chunkSeq, offset := digestSlice(s, start-margin, end+margin, *this.opts.Circular)
+			chunkQual, _ := digestSlice(q, start-margin, end+margin, *this.opts.Circular)
+			result = append(result, strings.Join([]string{string(record.ID), strconv.Itoa(l), strconv.Itoa(start),
+				strconv.Itoa(end), strconv.Itoa(offset), string(chunkSeq), string(chunkQual)}, digestSep))
+		}
+	}
+
+	return result, nil
+}
+
+// digestChunk is a chunk of DigestSplit, or a piece of a fragment crossing the ends of the chunks, the
+// bases and qualities [start, end) of a sequence of length l that begin at offset.
+type digestChunk struct {
+	id            string
+	l, start, end int
+	offset        int
+	s, q          []byte
+	left, right   string // pieces only, the cuts of the ends, "" when they are inside another fragment
+}
+
+func parseDigestChunk(v string, piece bool) (*digestChunk, error) {
+	fields := strings.Split(v, digestSep)
+	if !piece && len(fields) != 7 || piece && len(fields) != 8 {
+		return nil, fmt.Errorf("invalid digest chunk")
+	}
+	chunk := &digestChunk{id: fields[0], s: []byte(fields[len(fields)-2]), q: []byte(fields[len(fields)-1])}
+	chunk.l, _ = strconv.Atoi(fields[1])
+	chunk.start, _ = strconv.Atoi(fields[2])
+	chunk.end, _ = strconv.Atoi(fields[3])
+	if piece {
+		chunk.offset = chunk.start
+		chunk.left, chunk.right = fields[4], fields[5]
+	} else {
+		chunk.offset, _ = strconv.Atoi(fields[4])
+	}
+	return chunk, nil
+}
+
+func NewDigest() any {
+	return &Digest{}
+}
+
+type Digest struct {
+	base.IMapPartitions[string, ipair.IPair[string, string]]
+	function.IAfterNone
+	digestBase
+}
+
+func (this *Digest) Before(context api.IContext) (err error) {
+	return this.before(context)
+}
+
+// Call cuts the chunks of DigestSplit. The fragments are returned with an empty key, and the pieces of the
+// fragments crossing the ends of the chunks are returned by sequence ID to be joined by DigestJoin.
+func (this *Digest) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]ipair.IPair[string, string], error) {
+	result := make([]ipair.IPair[string, string], 0, 100)
+	for v1.HasNext() {
+		v, err := v1.Next()
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := parseDigestChunk(v, false)
+		if err != nil {
+			return nil, err
+		}
+
+		l, s, q := chunk.l, chunk.s, chunk.q
+		if chunk.start == 0 && chunk.end == l { // whole sequence
+			if *this.opts.Circular { // concat two copies of sequence
+				s = append(append(make([]byte, 0, 2*l), s...), s...)
+				if len(q) > 0 {
+					q = append(append(make([]byte, 0, 2*l), q...), q...)
+				}
+			}
+			for _, f := range this.fragments(this.cuts(bytes.ToUpper(s), 0, 0, l, l), l) {
+				if !this.keep(f) {
+					continue
+				}
+				var fq []byte
+				if len(q) > 0 {
+					fq = q[f.start:f.end]
+				}
+				text, err := this.format(chunk.id, l, f, s[f.start:f.end], fq)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, *ipair.New("", text))
+			}
+			continue
+		}
+
+		// the fragments between the cuts of the chunk are complete, the ends of the chunk are pieces
+		cuts := this.cuts(bytes.ToUpper(s), chunk.offset, chunk.start, chunk.end, l)
+		left := ""
+		if chunk.start == 0 && !*this.opts.Circular {
+			left = "."
+		}
+		start := chunk.start
+		positions := append(sortedCuts(cuts), chunk.end)
+		for i, c := range positions {
+			right := ""
+			if i < len(positions)-1 {
+				right = strings.Join(cuts[c], "/")
+			} else if chunk.end == l && !*this.opts.Circular {
+				right = "."
+			}
+			f := digestFragment{start, c, left, right}
+			fs := s[f.start-chunk.offset : f.end-chunk.offset]
+			var fq []byte
+			if len(q) > 0 {
+				fq = q[f.start-chunk.offset : f.end-chunk.offset]
+			}
+			if left != "" && right != "" {
+				if this.keep(f) {
+					text, err := this.format(chunk.id, l, f, fs, fq)
+					if err != nil {
+						return nil, err
+					}
+					result = append(result, *ipair.New("", text))
+				}
+			} else {
+				result = append(result, *ipair.New(chunk.id, strings.Join([]string{chunk.id, strconv.Itoa(l),
+					strconv.Itoa(f.start), strconv.Itoa(f.end), left, right, string(fs), string(fq)}, digestSep)))
+			}
+			start, left = c, right
+		}
+	}
+
+	return result, nil
+}
+
+func NewDigestFragments() any {
+	return &DigestFragments{}
+}
+
+type DigestFragments struct {
+	base.IFlatmap[ipair.IPair[string, string], string]
+	function.IOnlyCall
+}
+
+func (this *DigestFragments) Call(v ipair.IPair[string, string], context api.IContext) ([]string, error) {
+	if v.First != "" {
+		return nil, nil
+	}
+	return []string{v.Second}, nil
+}
+
+func NewDigestPieces() any {
+	return &DigestPieces{}
+}
+
+type DigestPieces struct {
+	base.IFlatmap[ipair.IPair[string, string], ipair.IPair[string, string]]
+	function.IOnlyCall
+}
+
+func (this *DigestPieces) Call(v ipair.IPair[string, string], context api.IContext) ([]ipair.IPair[string, string], error) {
+	if v.First == "" {
+		return nil, nil
+	}
+	return []ipair.IPair[string, string]{v}, nil
+}
+
+func NewDigestJoin() any {
+	return &DigestJoin{}
+}
+
+type DigestJoin struct {
+	base.IFlatmap[ipair.IPair[string, []string], string]
+	function.IAfterNone
+	digestBase
+}
+
+func (this *DigestJoin) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.DigestOptions](context.Vars()["opts"].(string))
+	return nil
+}
+
+// Call joins the pieces of the fragments of a sequence crossing the ends of the chunks.
+func (this *DigestJoin) Call(v ipair.IPair[string, []string], context api.IContext) ([]string, error) {
+	pieces := make([]*digestChunk, 0, len(v.Second))
+	for _, e := range v.Second {
+		piece, err := parseDigestChunk(e, true)
+		if err != nil {
+			return nil, err
+		}
+		pieces = append(pieces, piece)
+	}
+	sort.Slice(pieces, func(i, j int) bool { // the empty piece of a cut at the start of a chunk goes first
+		return pieces[i].start < pieces[j].start || pieces[i].start == pieces[j].start && pieces[i].end < pieces[j].end
+	})
+	l := pieces[0].l
+
+	if *this.opts.Circular {
+		// the pieces before the first cut end the fragment that crosses the origin
+		k := 0
+		for k < len(pieces) && pieces[k].left == "" {
+			k++
+		}
+		if k == len(pieces) { // no cuts
+			pieces[0].left, pieces[len(pieces)-1].right = ".", "."
+		} else {
+			for _, piece := range pieces[:k] {
+				piece.start, piece.end = piece.start+l, piece.end+l
+			}
+			pieces = append(pieces[k:], pieces[:k]...)
+		}
+	}
+
+	result := make([]string, 0, 1)
+	var f *digestFragment
+	var s, q []byte
+	for _, piece := range pieces {
+		if piece.left != "" {
+			f, s, q = &digestFragment{start: piece.start, left: piece.left}, nil, nil
+		} else if f == nil {
+			return nil, fmt.Errorf("missing digest pieces of sequence: %s", v.First)
+		}
+		s, q = append(s, piece.s...), append(q, piece.q...)
+		if piece.right == "" {
+			continue
+		}
+		f.end, f.right = piece.end, piece.right
+		if this.keep(*f) {
+			text, err := this.format(v.First, l, *f, s, q)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, text)
+		}
+		f = nil
+	}
+	if f != nil {
+		return nil, fmt.Errorf("missing digest pieces of sequence: %s", v.First)
+	}
+
+	return result, nil
+}
+
+func NewDigestHistogram() any {
+	return &DigestHistogram{}
+}
+
+type DigestHistogram struct {
+	base.IMapPartitions[string, map[int64]int64]
+	function.IAfterNone
+	opts     bigseqkit.DigestOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *DigestHistogram) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.DigestOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call counts the fragments of Digest, FASTA/Q records or BED lines, by length.
+func (this *DigestHistogram) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]map[int64]int64, error) {
+	bin := int64(*this.opts.HistBin)
+	counts := make(map[int64]int64)
+	if *this.opts.Bed {
+		for v1.HasNext() {
+			line, err := v1.Next()
+			if err != nil {
+				return nil, err
+			}
+			fields := strings.Split(line, "\t")
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("invalid BED line of digest: %s", line)
+			}
+			start, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			end, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			counts[(end-start)/bin*bin]++
+		}
+		return []map[int64]int64{counts}, nil
+	}
+
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		counts[int64(len(record.Seq.Seq))/bin*bin]++
+	}
+
+	return []map[int64]int64{counts}, nil
+}
@@ -28,6 +28,9 @@ type Fa2Fq struct {
 
 func (this *Fa2Fq) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.Fa2FqOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	if err != nil {
 		return err
@@ -36,9 +39,6 @@ func (this *Fa2Fq) Before(context api.IContext) (err error) {
 	seq.ValidateSeq = false
 	//fai.MapWholeFile = false
 	fileFasta := *this.opts.FastaFile
-	if fileFasta == "" {
-		return fmt.Errorf("flag -f (--fasta-file) needed")
-	}
 
 	this.records, err = fastx.GetSeqsMap(fileFasta, seq.Unlimit, context.Threads(), 10, "")
 	if err != nil {
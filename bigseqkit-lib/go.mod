@@ -3,13 +3,14 @@ module bigseqkit-lib
 go 1.20
 
 require (
+	bigseqkit v0.0.0
 	github.com/cespare/xxhash/v2 v2.1.2
 	github.com/shenwei356/bio v0.7.0
 	github.com/shenwei356/breader v0.3.1
 	github.com/shenwei356/bwt v0.6.0
 	github.com/shenwei356/natsort v0.0.0-20190418160752-600d539c017d
+	github.com/shenwei356/util v0.5.0
 	ignis v0.0.0
-	bigseqkit v0.0.0
 )
 
 require (
@@ -21,12 +22,12 @@ require (
 	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/pierrec/xxHash v0.1.5 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
-	github.com/shenwei356/util v0.5.0 // indirect
 	github.com/shenwei356/xopen v0.2.1 // indirect
 	github.com/tatsushid/go-prettytable v0.0.0-20141013043238-ed2d14c29939 // indirect
 	github.com/twotwotwo/sorts v0.0.0-20160814051341-bf5c1f2b8553 // indirect
 	github.com/ulikunitz/xz v0.5.10 // indirect
 	golang.org/x/sys v0.0.0-20210927094055-39ccf1dd6fa6 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
 
 replace bigseqkit => ../bigseqkit
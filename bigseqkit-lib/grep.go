@@ -27,12 +27,12 @@ func NewGrep() any {
 
 type Grep struct {
 	base.IMapPartitionsWithIndex[string, string]
-	function.IAfterNone
 	opts        bigseqkit.GrepOptions
 	alphabet    *seq.Alphabet
 	limitRegion bool
 	patterns    map[string]*regexp.Regexp
 	start, end  int
+	metrics     *metrics
 }
 
 var reUnquotedComma = regexp.MustCompile(`\{[^\}]*$|^[^\{]*\}`)
@@ -40,20 +40,20 @@ var helpUnquotedComma = `possible unquoted comma detected, please use double quo
 
 func (this *Grep) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.GrepOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	if err != nil {
 		return err
 	}
 	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
 	seq.ValidateSeq = false
+	this.metrics = newMetrics(context, "Grep")
 	//fai.MapWholeFile = false ¿?
 	usingDefaultIDRegexp := *this.opts.Config.IDRegexp == fastx.DefaultIDRegexp
 	bwt.CheckEndSymbol = false
 
-	if len(*this.opts.Pattern) == 0 && *this.opts.PatternFile == "" {
-		return fmt.Errorf("one of flags -p (--pattern) and -f (--pattern-file) needed")
-	}
-
 	// check pattern with unquoted comma
 	hasUnquotedComma := false
 	for _, _pattern := range *this.opts.Pattern {
@@ -73,9 +73,6 @@ func (this *Grep) Before(context api.IContext) (err error) {
 
 	//var sfmi *fmi.FMIndex
 	if *this.opts.MaxMismatch > 0 {
-		if *this.opts.UseRegexp || *this.opts.Degenerate {
-			return fmt.Errorf("flag -r (--use-regexp) or -d (--degenerate) not allowed when giving flag -m (--max-mismatch)")
-		}
 		if !*this.opts.BySeq {
 			log.Info("when value of flag -m (--max-mismatch) > 0, flag -s (--by-seq) is automatically on")
 			*this.opts.BySeq = true
@@ -86,21 +83,13 @@ func (this *Grep) Before(context api.IContext) (err error) {
 		}
 	}
 
-	if *this.opts.UseRegexp && *this.opts.Degenerate {
-		return fmt.Errorf("could not give both flags -d (--degenerate) and -r (--use-regexp)")
-	}
-
 	if *this.opts.Region != "" {
 		this.limitRegion = true
 		if !*this.opts.BySeq {
 			log.Info("when flag -R (--region) given, flag -s (--by-seq) is automatically on")
 			*this.opts.BySeq = true
 		}
-		region := *this.opts.Region
-		if !reRegion.MatchString(region) {
-			return fmt.Errorf(`invalid region: %s. type "seqkit grep -h" for more examples`, region)
-		}
-		r := strings.Split(region, ":")
+		r := strings.Split(*this.opts.Region, ":")
 		this.start, err = strconv.Atoi(r[0])
 		if err != nil {
 			return err
@@ -109,12 +98,6 @@ func (this *Grep) Before(context api.IContext) (err error) {
 		if err != nil {
 			return err
 		}
-		if this.start == 0 || this.end == 0 {
-			return fmt.Errorf("both start and end should not be 0")
-		}
-		if this.start < 0 && this.end > 0 {
-			return fmt.Errorf("when start < 0, end should not > 0")
-		}
 	}
 
 	// prepare pattern
@@ -252,6 +235,11 @@ func (this *Grep) Before(context api.IContext) (err error) {
 	return nil
 }
 
+func (this *Grep) After(context api.IContext) (err error) {
+	this.metrics.save()
+	return nil
+}
+
 func (this *Grep) grepBySeqMismatches(pid int64, it iterator.IReadIterator[string], context api.IContext) ([]string, error) {
 	// only for searching with sequences and mismatch > 0, were FMI is very slow
 	result := make([]string, 0, 100)
@@ -260,6 +248,8 @@ func (this *Grep) grepBySeqMismatches(pid int64, it iterator.IReadIterator[strin
 	count := int64(0)
 
 	justCount := *this.opts.Count
+	counters := make(map[string]int64)
+	defer this.metrics.add(counters)
 
 	var err error
 	fastxReader, err := NewSeqParser(this.alphabet, it, *this.opts.Config.IDRegexp)
@@ -276,6 +266,7 @@ func (this *Grep) grepBySeqMismatches(pid int64, it iterator.IReadIterator[strin
 			}
 			return nil, err
 		}
+		counters["records_in"]++
 
 		if checkAlphabet {
 			if fastxReader.Alphabet() == seq.Unlimit || fastxReader.Alphabet() == seq.Protein {
@@ -342,13 +333,16 @@ func (this *Grep) grepBySeqMismatches(pid int64, it iterator.IReadIterator[strin
 
 		if *this.opts.InvertMatch {
 			if hit {
+				counters["dropped_match"]++
 				continue
 			}
 		} else {
 			if !hit {
+				counters["dropped_no_match"]++
 				continue
 			}
 		}
+		counters["records_out"]++
 
 		if justCount {
 			count++
@@ -382,6 +376,8 @@ func (this *Grep) grepGeneral(pid int64, it iterator.IReadIterator[string], cont
 	}
 
 	justCount := *this.opts.Count
+	counters := make(map[string]int64)
+	defer this.metrics.add(counters)
 
 	fastxReader, err := NewSeqParser(this.alphabet, it, *this.opts.Config.IDRegexp)
 	if err != nil {
@@ -400,6 +396,7 @@ func (this *Grep) grepGeneral(pid int64, it iterator.IReadIterator[string], cont
 			}
 			break
 		}
+		counters["records_in"]++
 
 		if checkAlphabet {
 			if fastxReader.Alphabet() == seq.Unlimit || fastxReader.Alphabet() == seq.Protein {
@@ -515,13 +512,16 @@ func (this *Grep) grepGeneral(pid int64, it iterator.IReadIterator[string], cont
 
 		if *this.opts.InvertMatch {
 			if hit {
+				counters["dropped_match"]++
 				continue
 			}
 		} else {
 			if !hit {
+				counters["dropped_no_match"]++
 				continue
 			}
 		}
+		counters["records_out"]++
 
 		if justCount {
 			count++
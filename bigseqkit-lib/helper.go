@@ -29,17 +29,24 @@ func NewReadFixer() any {
 
 type ReadFixer struct {
 	base.IMapPartitions[string, string]
-	function.IAfterNone
-	delim string
+	delim   string
+	metrics *metrics
 }
 
 func (this *ReadFixer) Before(context api.IContext) (err error) {
 	this.delim = context.Vars()["delim"].(string)
+	this.metrics = newMetrics(context, "ReadFixer")
+	return nil
+}
+
+func (this *ReadFixer) After(context api.IContext) (err error) {
+	this.metrics.save()
 	return nil
 }
 
 func (this *ReadFixer) Call(it iterator.IReadIterator[string], context api.IContext) ([]string, error) {
 	result := make([]string, 0, 100)
+	bytes := int64(0)
 	for it.HasNext() {
 		v, err := it.Next()
 		if err != nil {
@@ -48,6 +55,7 @@ func (this *ReadFixer) Call(it iterator.IReadIterator[string], context api.ICont
 		if len(v) == 0 {
 			continue
 		}
+		bytes += int64(len(v))
 		if v[len(v)-1] == '\n' {
 			if v[0] != this.delim[0] {
 				result = append(result, this.delim+v[:len(v)-1])
@@ -62,6 +70,7 @@ func (this *ReadFixer) Call(it iterator.IReadIterator[string], context api.ICont
 			}
 		}
 	}
+	this.metrics.add(map[string]int64{"records_in": int64(len(result)), "bytes_in": bytes})
 	return result, nil
 }
 
@@ -135,6 +144,33 @@ func parseQualityEncoding(s string) (seq.QualityEncoding, error) {
 	}
 }
 
+// recordKey returns the bytes used to compare records by ID, full name or sequence.
+// When comparing by sequence on both strands, the canonical form min(seq, revcom(seq))
+// is returned, so a sequence and its reverse complement always share the same key.
+func recordKey(record *fastx.Record, bySeq, byName, ignoreCase, bothStrands bool) []byte {
+	var subject []byte
+	if bySeq {
+		subject = record.Seq.Seq
+	} else if byName {
+		subject = record.Name
+	} else { // byID
+		subject = record.ID
+	}
+	if ignoreCase {
+		subject = bytes.ToLower(subject)
+	}
+	if !bySeq || !bothStrands || record.Seq.Alphabet == seq.Protein || record.Seq.Alphabet == seq.Unlimit {
+		return subject
+	}
+
+	strand := &seq.Seq{Alphabet: record.Seq.Alphabet, Seq: subject}
+	revcom := strand.RevCom().Seq
+	if bytes.Compare(revcom, subject) < 0 {
+		return revcom
+	}
+	return subject
+}
+
 func NewArrayIterator(array []string) *ArrayIterator {
 	return &ArrayIterator{array, 0}
 }
@@ -381,19 +417,21 @@ func NewFileStore() any {
 
 type FileStore struct {
 	base.IMapPartitionsWithIndex[string, string]
-	path  string
-	execs int
-	id    int
-	f     *os.File
-	buff  *bufio.Writer
-	sync  []chan int
-	part  int64
-	err   error
+	path    string
+	execs   int
+	id      int
+	f       *os.File
+	buff    *bufio.Writer
+	sync    []chan int
+	part    int64
+	err     error
+	metrics *metrics
 }
 
 func (this *FileStore) Before(context api.IContext) (err error) {
 	this.err = nil
 	this.path = context.Vars()["path"].(string)
+	this.metrics = newMetrics(context, "FileStore")
 	this.execs = context.Executors()
 	this.id = context.ExecutorId()
 	if this.id > 0 {
@@ -422,6 +460,7 @@ func (this *FileStore) After(context api.IContext) (err error) {
 		return err
 	}
 	this.f.Close()
+	this.metrics.save()
 	if this.id < this.execs-1 {
 		n := impi.C_int(this.part)
 		if err := impi.MPI_Send(impi.P(&n), 1, impi.MPI_INT, impi.C_int(this.id+1), 0, context.MpiGroup()); err != nil {
@@ -438,17 +477,22 @@ func (this *FileStore) Call(pid int64, it iterator.IReadIterator[string], contex
 			break
 		}
 	}
+	records, written := int64(0), int64(0)
 	for it.HasNext() {
 		e, err := it.Next()
 		if err != nil {
 			this.err = err
 			return []string{""}, nil
 		}
-		if _, err := this.buff.WriteString(e + "\n"); err != nil {
+		n, err := this.buff.WriteString(e + "\n")
+		if err != nil {
 			this.err = err
 			return []string{""}, nil
 		}
+		records++
+		written += int64(n)
 	}
+	this.metrics.add(map[string]int64{"records_out": records, "bytes_written": written})
 
 	this.part++
 	for i := 0; i < context.Threads(); i++ {
@@ -458,3 +502,20 @@ func (this *FileStore) Call(pid int64, it iterator.IReadIterator[string], contex
 	}
 	return []string{""}, nil
 }
+
+func NewMapSumReduce() any {
+	return &MapSumReduce{}
+}
+
+// MapSumReduce sums the counters of the partitions by key, e.g. of histograms.
+type MapSumReduce struct {
+	base.IReduce[map[int64]int64]
+	function.IOnlyCall
+}
+
+func (this *MapSumReduce) Call(v1 map[int64]int64, v2 map[int64]int64, context api.IContext) (map[int64]int64, error) {
+	for k, v := range v2 {
+		v1[k] += v
+	}
+	return v1, nil
+}
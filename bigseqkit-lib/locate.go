@@ -14,6 +14,7 @@ import (
 	log "ignis/executor/core/logger"
 	"io"
 	"regexp"
+	"sort"
 )
 
 func NewLocate() any {
@@ -32,6 +33,9 @@ type Locate struct {
 
 func (this *Locate) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.LocateOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
 	seq.ValidateSeq = false
@@ -42,10 +46,6 @@ func (this *Locate) Before(context api.IContext) (err error) {
 		this.onlyPositiveStrand = true
 	}
 
-	if len(*this.opts.Pattern) == 0 && *this.opts.PatternFile == "" {
-		return fmt.Errorf("one of flags -p (--pattern) and -f (--pattern-file) needed")
-	}
-
 	// check pattern with unquoted comma
 	hasUnquotedComma := false
 	for _, _pattern := range *this.opts.Pattern {
@@ -58,25 +58,8 @@ func (this *Locate) Before(context api.IContext) (err error) {
 		log.Warn(helpUnquotedComma)
 	}
 
-	if *this.opts.MaxMismatch > 0 {
-		if *this.opts.Degenerate {
-			return fmt.Errorf("flag -d (--degenerate) not allowed when giving flag -m (--max-mismatch)")
-		}
-		if *this.opts.UseRegexp {
-			return fmt.Errorf("flag -r (--use-regexp) not allowed when giving flag -m (--use-regexp)")
-		}
-		if *this.opts.NonGreedy && !*this.opts.Config.Quiet {
-			log.Info("flag -G (--non-greedy) ignored when giving flag -m (--max-mismatch)")
-		}
-
-	}
-	if *this.opts.UseFmi {
-		if *this.opts.Degenerate {
-			return fmt.Errorf("flag -d (--degenerate) ignored when giving flag -F (--use-fmi)")
-		}
-		if *this.opts.UseRegexp {
-			return fmt.Errorf("flag -r (--use-regexp) ignored when giving flag -F (--use-fmi)")
-		}
+	if *this.opts.MaxMismatch > 0 && *this.opts.NonGreedy && !*this.opts.Config.Quiet {
+		log.Info("flag -G (--non-greedy) ignored when giving flag -m (--max-mismatch)")
 	}
 
 	// prepare pattern
@@ -251,7 +234,7 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 			}
 
 			for pName, pSeq := range this.patterns {
-				loc, err := sfmi.Locate(pSeq, *this.opts.MaxMismatch)
+				loc, err := fmiLocate(sfmi, pSeq, *this.opts.MaxMismatch)
 				if err != nil {
 					return nil, fmt.Errorf("fail to search pattern '%s' on seq '%s': %s", pName, record.Name, err)
 				}
@@ -264,9 +247,6 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 					begin = i + 1
 
 					end = i + len(pSeq)
-					if i+len(pSeq) > len(record.Seq.Seq) {
-						continue
-					}
 					if *this.opts.Gtf {
 						result = append(result,
 							fmt.Sprintf("%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\tgene_id \"%s\"; \n",
@@ -324,7 +304,7 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 			}
 
 			for pName, pSeq := range this.patterns {
-				loc, err := sfmi.Locate(pSeq, *this.opts.MaxMismatch)
+				loc, err := fmiLocate(sfmi, pSeq, *this.opts.MaxMismatch)
 				if err != nil {
 					return nil, fmt.Errorf("fail to search pattern '%s' on seq '%s': %s", pName, record.Name, err)
 				}
@@ -336,9 +316,6 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 
 					begin = l - i - len(pSeq) + 1
 					end = l - i
-					if i+len(pSeq) > len(record.Seq.Seq) {
-						continue
-					}
 					if *this.opts.Gtf {
 						result = append(result,
 							fmt.Sprintf("%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\tgene_id \"%s\"; \n",
@@ -393,12 +370,13 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 	// -------------------------------------------------------------------
 
 	var seqRP *seq.Seq
-	var offset, l, lpatten int
+	var l int
 	var loc []int
 	var locs, locsNeg [][2]int
+	var match [2]int
 	var i, begin, end int
 	var flag bool
-	var pSeq, p []byte
+	var pSeq []byte
 	var pName string
 	var re *regexp.Regexp
 	var sfmi *fmi.FMIndex
@@ -445,7 +423,7 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 			}
 
 			for pName, pSeq = range this.patterns {
-				loc, err = sfmi.Locate(pSeq, *this.opts.MaxMismatch)
+				loc, err = fmiLocate(sfmi, pSeq, *this.opts.MaxMismatch)
 				if err != nil {
 					return nil, fmt.Errorf("fail to search pattern '%s' on seq '%s': %s", pName, record.Name, err)
 				}
@@ -457,9 +435,6 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 					begin = i + 1
 
 					end = i + len(pSeq)
-					if i+len(pSeq) > len(record.Seq.Seq) {
-						continue
-					}
 					if *this.opts.Gtf {
 						result = append(result, fmt.Sprintf("%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\tgene_id \"%s\"; \n",
 							record.ID,
@@ -513,7 +488,7 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 				return nil, fmt.Errorf("fail to build FMIndex for reverse complement sequence: %s", record.Name)
 			}
 			for pName, pSeq = range this.patterns {
-				loc, err = sfmi.Locate(pSeq, *this.opts.MaxMismatch)
+				loc, err = fmiLocate(sfmi, pSeq, *this.opts.MaxMismatch)
 				if err != nil {
 					return nil, fmt.Errorf("fail to search pattern '%s' on seq '%s': %s", pName, record.Name, err)
 				}
@@ -524,9 +499,6 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 
 					begin = l - i - len(pSeq) + 1
 					end = l - i
-					if i+len(pSeq) > len(record.Seq.Seq) {
-						continue
-					}
 					if *this.opts.Gtf {
 						result = append(result, fmt.Sprintf("%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\tgene_id \"%s\"; \n",
 							record.ID,
@@ -574,34 +546,16 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 
 		for pName = range this.patterns {
 			locs = make([][2]int, 0, 1000)
+			re = this.regexps[pName]
 
-			offset = 0
-			if !(*this.opts.UseRegexp || *this.opts.Degenerate) {
-				p = this.patterns[pName]
-				lpatten = len(p)
-			}
-			for {
-				if *this.opts.UseRegexp || *this.opts.Degenerate {
-					re = this.regexps[pName]
-					loc = re.FindSubmatchIndex(record.Seq.Seq[offset:])
-					if loc == nil {
-						break
-					}
-
-				} else {
-					i = bytes.Index(record.Seq.Seq[offset:], p)
-					if i < 0 {
-						break
-					}
-					loc = []int{i, i + lpatten}
-				}
-				begin = offset + loc[0] + 1
+			for _, match = range patternLocate(re, this.patterns[pName], record.Seq.Seq, *this.opts.NonGreedy) {
+				begin = match[0] + 1
 
 				if *this.opts.Circular && begin > l { // 2nd clone of original part
 					break
 				}
 
-				end = offset + loc[1]
+				end = match[1]
 
 				flag = true // check "duplicated" region
 				if *this.opts.UseRegexp || *this.opts.Degenerate {
@@ -655,15 +609,6 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 					}
 					locs = append(locs, [2]int{begin, end})
 				}
-
-				if *this.opts.NonGreedy {
-					offset = offset + loc[1] + 1
-				} else {
-					offset = offset + loc[0] + 1
-				}
-				if offset >= len(record.Seq.Seq) {
-					break
-				}
 			}
 
 			if *this.opts.OnlyPositiveStrand {
@@ -674,30 +619,14 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 
 			locsNeg = make([][2]int, 0, 1000)
 
-			offset = 0
-
-			for {
-				if *this.opts.UseRegexp || *this.opts.Degenerate {
-					re = this.regexps[pName]
-					loc = re.FindSubmatchIndex(seqRP.Seq[offset:])
-					if loc == nil {
-						break
-					}
-				} else {
-					i = bytes.Index(seqRP.Seq[offset:], p)
-					if i < 0 {
-						break
-					}
-					loc = []int{i, i + lpatten}
-				}
-
-				if *this.opts.Circular && offset+loc[0]+1 > l { // 2nd clone of original part
+			for _, match = range patternLocate(re, this.patterns[pName], seqRP.Seq, *this.opts.NonGreedy) {
+				if *this.opts.Circular && match[0]+1 > l { // 2nd clone of original part
 					break
 				}
 
-				begin = l - offset - loc[1] + 1
-				end = l - offset - loc[0]
-				if offset+loc[1] > l {
+				begin = l - match[1] + 1
+				end = l - match[0]
+				if match[1] > l {
 					begin += l
 					end += l
 				}
@@ -749,20 +678,11 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 								"-",
 								begin,
 								end,
-								seqRP.Seq[offset+loc[0]:offset+loc[1]]))
+								seqRP.Seq[match[0]:match[1]]))
 						}
 					}
 					locsNeg = append(locsNeg, [2]int{begin, end})
 				}
-
-				if *this.opts.NonGreedy {
-					offset = offset + loc[1] + 1
-				} else {
-					offset = offset + loc[0] + 1
-				}
-				if offset >= len(record.Seq.Seq) {
-					break
-				}
 			}
 		}
 
@@ -770,3 +690,50 @@ func (this *Locate) Call(pid int64, it iterator.IReadIterator[string], context a
 
 	return result, nil
 }
+
+// fmiLocate returns the sorted start positions of a pattern, with at most mismatches mismatches, in the
+// sequence indexed by sfmi. Matches running past the end of the sequence are dropped.
+func fmiLocate(sfmi *fmi.FMIndex, pattern []byte, mismatches int) ([]int, error) {
+	loc, err := sfmi.Locate(pattern, mismatches)
+	if err != nil {
+		return nil, err
+	}
+	n := len(sfmi.BWT) - 1 // without the end symbol
+	result := loc[:0]
+	for _, i := range loc {
+		if i+len(pattern) <= n {
+			result = append(result, i)
+		}
+	}
+	sort.Ints(result)
+	return result, nil
+}
+
+// patternLocate returns the [start, end) of the matches in s of re, or of the exact pattern if re is nil.
+// Every search starts after the start of the previous match, or after its end if nonGreedy.
+func patternLocate(re *regexp.Regexp, pattern []byte, s []byte, nonGreedy bool) [][2]int {
+	result := make([][2]int, 0, 1)
+	for offset := 0; offset < len(s); {
+		var loc [2]int
+		if re != nil {
+			found := re.FindIndex(s[offset:])
+			if found == nil {
+				break
+			}
+			loc = [2]int{offset + found[0], offset + found[1]}
+		} else {
+			i := bytes.Index(s[offset:], pattern)
+			if i < 0 {
+				break
+			}
+			loc = [2]int{offset + i, offset + i + len(pattern)}
+		}
+		result = append(result, loc)
+		if nonGreedy {
+			offset = loc[1] + 1
+		} else {
+			offset = loc[0] + 1
+		}
+	}
+	return result
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bigseqkit"
+	"bytes"
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/iterator"
+	"io"
+	"math"
+	"sort"
+)
+
+// maskTriplets returns the index (0-63) of the trinucleotide starting at every position, -1 for ambiguous
+// bases.
+func maskTriplets(s []byte) []int {
+	if len(s) < 3 {
+		return nil
+	}
+	codes := make([]int, len(s))
+	for i, b := range s {
+		switch b {
+		case 'A', 'a':
+			codes[i] = 0
+		case 'C', 'c':
+			codes[i] = 1
+		case 'G', 'g':
+			codes[i] = 2
+		case 'T', 't', 'U', 'u':
+			codes[i] = 3
+		default:
+			codes[i] = -1
+		}
+	}
+	triplets := make([]int, len(s)-2)
+	for i := range triplets {
+		if codes[i] < 0 || codes[i+1] < 0 || codes[i+2] < 0 {
+			triplets[i] = -1
+		} else {
+			triplets[i] = codes[i]<<4 | codes[i+1]<<2 | codes[i+2]
+		}
+	}
+	return triplets
+}
+
+// dustIntervals returns the low-complexity regions found with the DUST algorithm: windows are moved by half
+// of their size, and the sub-interval with the highest score of every window is masked when its score is
+// over the level. The score of an interval of l triplets is 10 * sum(c_t * (c_t - 1) / 2) / (l - 1).
+func dustIntervals(s []byte, window int, level float64) [][2]int {
+	triplets := maskTriplets(s)
+	intervals := make([][2]int, 0)
+	var counts [64]int
+	for a := 0; a < len(triplets); a += window / 2 {
+		end := a + window - 2
+		if end > len(triplets) {
+			end = len(triplets)
+		}
+		best, bestStart, bestEnd := 0.0, 0, 0
+		for j := a; j < end; j++ {
+			counts = [64]int{}
+			sum, valid := 0, 0
+			for k := j; k < end; k++ {
+				t := triplets[k]
+				if t < 0 {
+					continue
+				}
+				sum += counts[t]
+				counts[t]++
+				if valid++; valid >= 2 {
+					if score := 10 * float64(sum) / float64(valid-1); score > best {
+						best, bestStart, bestEnd = score, j, k+3
+					}
+				}
+			}
+		}
+		if best > level {
+			intervals = append(intervals, [2]int{bestStart, bestEnd})
+		}
+		if end == len(triplets) {
+			break
+		}
+	}
+	return mergeIntervals(intervals)
+}
+
+// entropyIntervals returns the windows whose Shannon entropy of trinucleotides, normalized by its maximum
+// for the number of triplets of the window, is under the minimum. Sequences shorter than the window are a
+// single window.
+func entropyIntervals(s []byte, window int, minEntropy float64) [][2]int {
+	triplets := maskTriplets(s)
+	intervals := make([][2]int, 0)
+	if len(triplets) == 0 {
+		return intervals
+	}
+	size := window - 2
+	if size > len(triplets) {
+		size = len(triplets)
+	}
+
+	var counts [64]int
+	valid := 0
+	sumCLogC := 0.0 // sum(c_t * log2(c_t)), entropy = log2(n) - sumCLogC / n
+	update := func(t, delta int) {
+		if t < 0 {
+			return
+		}
+		c := counts[t]
+		if c > 0 {
+			sumCLogC -= float64(c) * math.Log2(float64(c))
+		}
+		c += delta
+		if c > 0 {
+			sumCLogC += float64(c) * math.Log2(float64(c))
+		}
+		counts[t] = c
+		valid += delta
+	}
+
+	for k := 0; k < size; k++ {
+		update(triplets[k], 1)
+	}
+	for i := 0; ; i++ {
+		if valid >= 2 {
+			n := float64(valid)
+			entropy := math.Log2(n) - sumCLogC/n
+			if entropy/math.Log2(math.Min(64, n)) < minEntropy {
+				intervals = append(intervals, [2]int{i, i + size + 2})
+			}
+		}
+		if i+size >= len(triplets) {
+			break
+		}
+		update(triplets[i], -1)
+		update(triplets[i+size], 1)
+	}
+	return mergeIntervals(intervals)
+}
+
+// mergeIntervals merges overlapping and adjacent intervals.
+func mergeIntervals(intervals [][2]int) [][2]int {
+	if len(intervals) == 0 {
+		return intervals
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0] < intervals[j][0] })
+	merged := intervals[:1]
+	for _, in := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if in[0] <= last[1] {
+			if in[1] > last[1] {
+				last[1] = in[1]
+			}
+		} else {
+			merged = append(merged, in)
+		}
+	}
+	return merged
+}
+
+func NewMask() any {
+	return &Mask{}
+}
+
+type Mask struct {
+	base.IMapPartitions[string, string]
+	function.IAfterNone
+	opts     bigseqkit.MaskOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *Mask) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.MaskOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+func (this *Mask) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, 100)
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		s := record.Seq.Seq
+		var intervals [][2]int
+		if *this.opts.Method == "dust" {
+			intervals = dustIntervals(s, *this.opts.Window, *this.opts.DustLevel)
+		} else {
+			intervals = entropyIntervals(s, *this.opts.Window, *this.opts.MinEntropy)
+		}
+
+		if *this.opts.Bed {
+			for _, in := range intervals {
+				result = append(result, fmt.Sprintf("%s\t%d\t%d", record.ID, in[0], in[1]))
+			}
+			continue
+		}
+
+		masked := 0
+		for _, in := range intervals {
+			masked += in[1] - in[0]
+		}
+		if len(s) > 0 && float64(masked)/float64(len(s)) > *this.opts.MaxMaskedFrac {
+			continue
+		}
+
+		for _, in := range intervals {
+			switch *this.opts.Mode {
+			case "soft":
+				copy(s[in[0]:in[1]], bytes.ToLower(s[in[0]:in[1]]))
+			case "hard":
+				for i := in[0]; i < in[1]; i++ {
+					s[i] = 'N'
+				}
+			}
+		}
+		result = append(result, string(record.Format(*this.opts.Config.LineWidth)))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bigseqkit"
+	"encoding/json"
+	"fmt"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/iterator"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// metrics are the counters of an executor function, enabled by the driver with the vars "metrics" (the
+// directory where they are saved) and "stage". The partitions add their counters when they end, the
+// function saves them in After. Meanwhile, if the var "progress" (an interval in nanoseconds) is set, a
+// snapshot is saved at most every interval as the progress of the function. The metrics are informative, a
+// failed write never fails the job.
+type metrics struct {
+	record   bigseqkit.MetricsRecord
+	dir      string
+	interval time.Duration
+	progress time.Time
+	lock     sync.Mutex
+}
+
+// newMetrics returns nil if the metrics are disabled, the methods of a nil metrics do nothing.
+func newMetrics(context api.IContext, function string) *metrics {
+	dir, ok := context.Vars()["metrics"].(string)
+	if !ok || dir == "" {
+		return nil
+	}
+	stage, _ := context.Vars()["stage"].(string)
+	interval, _ := context.Vars()["progress"].(int64)
+	return &metrics{
+		record: bigseqkit.MetricsRecord{
+			Stage:    stage,
+			Function: function,
+			Executor: context.ExecutorId(),
+			Start:    time.Now().UnixNano(),
+			Counters: make(map[string]int64),
+		},
+		dir:      dir,
+		interval: time.Duration(interval),
+		progress: time.Now(),
+	}
+}
+
+// add is called when a partition ends.
+func (this *metrics) add(counters map[string]int64) {
+	if this == nil {
+		return
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for name, n := range counters {
+		this.record.Counters[name] += n
+	}
+	this.record.Partitions++
+	if this.interval > 0 && time.Since(this.progress) >= this.interval {
+		this.progress = time.Now()
+		_ = this.write(this.path(bigseqkit.MetricsProgressExt))
+	}
+}
+
+// save is called in After, the record replaces the progress.
+func (this *metrics) save() {
+	if this == nil {
+		return
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.record.End = time.Now().UnixNano()
+	_ = this.write(this.path(bigseqkit.MetricsExt))
+	os.Remove(this.path(bigseqkit.MetricsProgressExt))
+}
+
+func (this *metrics) path(ext string) string {
+	name := fmt.Sprintf("%s-%d-%d%s", this.record.Function, this.record.Executor, this.record.Start, ext)
+	return filepath.Join(this.dir, name)
+}
+
+// write replaces the file with the record atomically, the driver can read it at any time.
+func (this *metrics) write(path string) error {
+	data, err := json.Marshal(this.record)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func NewRecordCounter() any {
+	return &RecordCounter{}
+}
+
+// RecordCounter counts the records of the partitions as the counter "counter" of the metrics and returns them
+// unchanged, e.g. the records in and out of the filters without counters of their own.
+type RecordCounter struct {
+	base.IMapPartitions[string, string]
+	counter string
+	metrics *metrics
+}
+
+func (this *RecordCounter) Before(context api.IContext) (err error) {
+	this.counter = context.Vars()["counter"].(string)
+	this.metrics = newMetrics(context, "RecordCounter")
+	return nil
+}
+
+func (this *RecordCounter) After(context api.IContext) (err error) {
+	this.metrics.save()
+	return nil
+}
+
+func (this *RecordCounter) Call(it iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	result := make([]string, 0, 100)
+	for it.HasNext() {
+		v, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	this.metrics.add(map[string]int64{this.counter: int64(len(result))})
+	return result, nil
+}
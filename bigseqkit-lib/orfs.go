@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bigseqkit"
+	"bytes"
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"github.com/shenwei356/bio/seqio/fastx"
+	"github.com/shenwei356/util/byteutil"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/iterator"
+	"io"
+)
+
+func NewOrfs() any {
+	return &Orfs{}
+}
+
+type Orfs struct {
+	base.IMapPartitions[string, string]
+	function.IAfterNone
+	opts     bigseqkit.OrfsOptions
+	alphabet *seq.Alphabet
+	frames   []int
+	table    *seq.CodonTable
+	starts   map[string]struct{}
+}
+
+func (this *Orfs) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.OrfsOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	if err != nil {
+		return err
+	}
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidSeqThreads = 1
+	seq.ComplementThreads = 1
+
+	this.table = seq.CodonTables[*this.opts.TranslTable]
+	switch *this.opts.Start {
+	case "atg":
+		this.starts = map[string]struct{}{"ATG": {}}
+	case "alt":
+		this.starts = this.table.InitCodons
+	case "none":
+		this.starts = nil
+	}
+
+	this.frames, err = parseTranslateFrames(*this.opts.Frame)
+	return err
+}
+
+// orf is an open reading frame [start, end) of a strand, the stop codon is included.
+type orf struct {
+	start, end int
+}
+
+// findOrfs finds the ORFs of a frame (1, 2 or 3) of a strand. Codons are matched in upper case with 'U' as 'T'.
+// Without start codons, ORFs span from stop to stop. Only the longest ORF of every stop codon is kept unless
+// nested ORFs are requested.
+func (this *Orfs) findOrfs(s []byte, frame int) []orf {
+	result := make([]orf, 0, 4)
+	starts := make([]int, 0, 4)
+	from := frame - 1
+	codon := make([]byte, 3)
+	for i := frame - 1; i+3 <= len(s); i += 3 {
+		for j := 0; j < 3; j++ {
+			b := s[i+j] &^ 0x20 // upper case
+			if b == 'U' {
+				b = 'T'
+			}
+			codon[j] = b
+		}
+		if _, ok := this.table.StopCodons[string(codon)]; ok {
+			if this.starts == nil {
+				starts = append(starts, from)
+			}
+			for _, start := range starts {
+				if i-start >= *this.opts.MinLen {
+					result = append(result, orf{start, i + 3})
+				}
+			}
+			starts = starts[:0]
+			from = i + 3
+			continue
+		}
+		if _, ok := this.starts[string(codon)]; ok && (*this.opts.Nested || len(starts) == 0) {
+			starts = append(starts, i)
+		}
+	}
+	return result
+}
+
+func (this *Orfs) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+	var record *fastx.Record
+
+	result := make([]string, 0, 100)
+	var outfh bytes.Buffer
+	once := true
+	for {
+		record, err = fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if once {
+			if !(record.Seq.Alphabet == seq.DNA || record.Seq.Alphabet == seq.DNAredundant ||
+				record.Seq.Alphabet == seq.RNA || record.Seq.Alphabet == seq.RNAredundant) {
+				return nil, fmt.Errorf(`command 'seqkit orfs' only apply to DNA/RNA sequences`)
+			}
+			once = false
+		}
+
+		n := len(record.Seq.Seq)
+		var revcom []byte
+		for _, frame := range this.frames {
+			s, strand, f := record.Seq.Seq, "+", frame
+			if frame < 0 {
+				if revcom == nil {
+					revcom = record.Seq.RevCom().Seq
+				}
+				s, strand, f = revcom, "-", -frame
+			}
+			for _, o := range this.findOrfs(s, f) {
+				// coordinates on the source record
+				start, end := o.start, o.end
+				if strand == "-" {
+					start, end = n-o.end, n-o.start
+				}
+				name := fmt.Sprintf("%s_%d-%d:%s", record.ID, start+1, end, strand)
+
+				outfh.Reset()
+				switch *this.opts.OutFormat {
+				case "bed":
+					outfh.WriteString(fmt.Sprintf("%s\t%d\t%d\t%s\t0\t%s", record.ID, start, end, name, strand))
+				case "gtf":
+					outfh.WriteString(fmt.Sprintf("%s\tbigseqkit\tCDS\t%d\t%d\t.\t%s\t0\tgene_id \"%s\"; transcript_id \"%s\";",
+						record.ID, start+1, end, strand, name, name))
+				case "protein":
+					aa, err := this.table.Translate(s[o.start:o.end], 1, false, false, true, this.starts != nil)
+					if err != nil {
+						return nil, err
+					}
+					outfh.WriteString(fmt.Sprintf(">%s frame=%d\n", name, frame))
+					outfh.Write(byteutil.WrapByteSlice(bytes.TrimRight(aa, "*"), *this.opts.Config.LineWidth))
+				default:
+					outfh.WriteString(fmt.Sprintf(">%s frame=%d\n", name, frame))
+					outfh.Write(byteutil.WrapByteSlice(s[o.start:o.end], *this.opts.Config.LineWidth))
+				}
+				result = append(result, outfh.String())
+			}
+		}
+	}
+
+	return result, nil
+}
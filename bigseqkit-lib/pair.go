@@ -13,7 +13,7 @@ import (
 )
 
 func NewPairPrepare() any {
-	return &ConcatPrepare{}
+	return &PairPrepare{}
 }
 
 type PairPrepare struct {
@@ -131,7 +131,7 @@ type PairI struct {
 }
 
 func (this *PairI) Before(context api.IContext) (err error) {
-	this.i = context.Vars()["id"].(int)
+	this.i = context.Vars()["i"].(int)
 	return nil
 }
 
@@ -143,7 +143,7 @@ func (this *PairI) Call(v ipair.IPair[string, string], context api.IContext) (st
 }
 
 func NewPairF() any {
-	return &PairI{}
+	return &PairF{}
 }
 
 type PairF struct {
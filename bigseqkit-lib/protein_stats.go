@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/iterator"
+	"io"
+)
+
+func NewProteinStats() any {
+	return &ProteinStats{}
+}
+
+type ProteinStats struct {
+	base.IMapPartitions[string, map[int64]int64]
+	function.IAfterNone
+	opts     bigseqkit.ProteinStatsOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *ProteinStats) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.ProteinStatsOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+func (this *ProteinStats) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]map[int64]int64, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	residues := make(map[byte]int64, 25)
+	records := int64(0)
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		records++
+		bigseqkit.CountResidues(record.Seq.Seq, residues)
+	}
+
+	counts := make(map[int64]int64, len(residues)+1)
+	counts[bigseqkit.ProteinStatsRecords] = records
+	for aa, c := range residues {
+		counts[int64(aa)] = c
+	}
+	return []map[int64]int64{counts}, nil
+}
+
+func NewProteinStatsRecords() any {
+	return &ProteinStatsRecords{}
+}
+
+type ProteinStatsRecords struct {
+	base.IMapPartitions[string, string]
+	function.IAfterNone
+	opts     bigseqkit.ProteinStatsOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *ProteinStatsRecords) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.ProteinStatsOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call outputs the ID, length, molecular weight, isoelectric point and GRAVY of every record.
+func (this *ProteinStatsRecords) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, 100)
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		info := &bigseqkit.ProteinInfo{Records: 1, Counts: make(map[byte]int64, 25)}
+		bigseqkit.CountResidues(record.Seq.Seq, info.Counts)
+		result = append(result, fmt.Sprintf("%s\t%d\t%.2f\t%.2f\t%.4f", record.ID, info.Length(),
+			info.MolecularWeight(), info.IsoelectricPoint(), info.Gravy()))
+	}
+
+	return result, nil
+}
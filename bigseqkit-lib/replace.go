@@ -35,15 +35,15 @@ type Replace struct {
 
 func (this *Replace) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.ReplaceOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
 	seq.ValidateSeq = false
 	fai.MapWholeFile = false
 	this.replacement = []byte(*this.opts.Replacement)
 
-	if *this.opts.Pattern == "" {
-		return fmt.Errorf("flags -p (--pattern) needed")
-	}
 	p := *this.opts.Pattern
 	if *this.opts.IgnoreCase {
 		p = "(?i)" + p
@@ -53,30 +53,12 @@ func (this *Replace) Before(context api.IContext) (err error) {
 		return err
 	}
 
-	if *this.opts.KvFile != "" {
-		if len(*this.opts.Replacement) == 0 {
-			return fmt.Errorf("flag -r (--replacement) needed when given flag -k (--kv-file)")
-		}
-		if !reKV.Match(this.replacement) {
-			return fmt.Errorf(`replacement symbol "{kv}"/"{KV}" not found in value of flag -r (--replacement) when flag -k (--kv-file) given`)
-		}
-	}
-
 	if reNR.Match(this.replacement) {
 		this.replaceWithNR = true
 	}
 
 	if reKV.Match(this.replacement) {
 		this.replaceWithKV = true
-		if !regexp.MustCompile(`\(.+\)`).MatchString(*this.opts.Pattern) {
-			return fmt.Errorf(`value of -p (--pattern) must contains "(" and ")" to capture data which is used specify the KEY`)
-		}
-		if *this.opts.BySeq {
-			return fmt.Errorf(`replaceing with key-value pairs was not supported for sequence`)
-		}
-		if *this.opts.KvFile == "" {
-			return fmt.Errorf(`since replacement symbol "{kv}"/"{KV}" found in value of flag -r (--replacement), tab-delimited key-value file should be given by flag -k (--kv-file)`)
-		}
 		if !*this.opts.Config.Quiet {
 			log.Info(fmt.Sprintf("read key-value file: %s", *this.opts.KvFile))
 		}
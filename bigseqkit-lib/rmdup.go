@@ -3,6 +3,7 @@ package main
 import (
 	"bigseqkit"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/cespare/xxhash/v2"
 	"github.com/shenwei356/bio/seq"
@@ -15,6 +16,8 @@ import (
 	"io"
 	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -51,6 +54,8 @@ func (this *RmDupPrepare) Call(v1 iterator.IReadIterator[string], context api.IC
 		return nil, err
 	}
 
+	revcom := !*this.opts.OnlyPositiveStrand
+
 	for {
 		record, err = fastxReader.Read()
 		if err != nil {
@@ -64,25 +69,7 @@ func (this *RmDupPrepare) Call(v1 iterator.IReadIterator[string], context api.IC
 			fastx.ForcelyOutputFastq = true
 		}
 
-		if *this.opts.BySeq {
-			if *this.opts.IgnoreCase {
-				subject = xxhash.Sum64(bytes.ToLower(record.Seq.Seq))
-			} else {
-				subject = xxhash.Sum64(record.Seq.Seq)
-			}
-		} else if *this.opts.ByName {
-			if *this.opts.IgnoreCase {
-				subject = xxhash.Sum64(bytes.ToLower(record.Name))
-			} else {
-				subject = xxhash.Sum64(record.Name)
-			}
-		} else { // byID
-			if *this.opts.IgnoreCase {
-				subject = xxhash.Sum64(bytes.ToLower(record.ID))
-			} else {
-				subject = xxhash.Sum64(record.ID)
-			}
-		}
+		subject = xxhash.Sum64(recordKey(record, *this.opts.BySeq, *this.opts.ByName, *this.opts.IgnoreCase, revcom))
 		result = append(result, *ipair.New(int64(subject), string(record.Format(*this.opts.Config.LineWidth))))
 	}
 
@@ -157,25 +144,7 @@ func (this *RmDupCheck) Call(v ipair.IPair[int64, []string], context api.IContex
 			fastx.ForcelyOutputFastq = true
 		}
 
-		if *this.opts.BySeq {
-			if *this.opts.IgnoreCase {
-				subject = string(bytes.ToLower(record.Seq.Seq))
-			} else {
-				subject = string(record.Seq.Seq)
-			}
-		} else if *this.opts.ByName {
-			if *this.opts.IgnoreCase {
-				subject = string(bytes.ToLower(record.Name))
-			} else {
-				subject = string(record.Name)
-			}
-		} else { // byID
-			if *this.opts.IgnoreCase {
-				subject = string(bytes.ToLower(record.ID))
-			} else {
-				subject = string(record.ID)
-			}
-		}
+		subject = string(recordKey(record, *this.opts.BySeq, *this.opts.ByName, *this.opts.IgnoreCase, revcom))
 
 		if _, ok := counter[subject]; ok { // duplicated
 			counter[subject]++
@@ -190,25 +159,6 @@ func (this *RmDupCheck) Call(v ipair.IPair[int64, []string], context api.IContex
 			continue
 		}
 
-		if *this.opts.BySeq && revcom {
-			if *this.opts.IgnoreCase {
-				subject = string(bytes.ToLower(record.Seq.RevCom().Seq))
-			} else {
-				subject = string(record.Seq.RevCom().Seq)
-			}
-
-			if _, ok := counter[subject]; ok { // duplicated
-				counter[subject]++
-				removed++
-				if len(*this.opts.DupSeqsFile) > 0 {
-					dups = append(dups, string(record.Format(*this.opts.Config.LineWidth)))
-				}
-				if len(*this.opts.DupNumFile) > 0 {
-					names[subject] = append(names[subject], string(record.ID))
-				}
-				continue
-			}
-		}
 		counter[subject]++
 
 		bb := record.Format(*this.opts.Config.LineWidth)
@@ -277,3 +227,499 @@ func (this *RmDupCheck) After(context api.IContext) (err error) {
 	}
 	return nil
 }
+
+type rmDupEntry struct {
+	record *fastx.Record
+	ids    []string
+}
+
+// rmDupSplit separates the records of a hash group by their real key, as different keys may share the hash.
+// The entries keep the order of the first occurrence of every key.
+func rmDupSplit(alphabet *seq.Alphabet, opts *bigseqkit.RmDupOptions, group []string) ([]*rmDupEntry, error) {
+	revcom := !*opts.OnlyPositiveStrand
+	entries := make([]*rmDupEntry, 0, 1)
+	index := make(map[string]*rmDupEntry)
+
+	fastxReader, err := NewSeqParser(alphabet, NewArrayIterator(group), *opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		subject := string(recordKey(record, *opts.BySeq, *opts.ByName, *opts.IgnoreCase, revcom))
+		if entry, ok := index[subject]; ok {
+			entry.ids = append(entry.ids, string(record.ID))
+			continue
+		}
+		entry := &rmDupEntry{record.Clone(), []string{string(record.ID)}}
+		index[subject] = entry
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func NewRmDupReport() any {
+	return &RmDupReport{}
+}
+
+// RmDupReport returns the lines of the duplicated keys as ("report", line) and, once per partition, the
+// duplication-level histogram of its keys as ("histogram", JSON), so both come from the same grouped records.
+type RmDupReport struct {
+	base.IMapPartitions[ipair.IPair[int64, []string], ipair.IPair[string, string]]
+	function.IAfterNone
+	opts     bigseqkit.RmDupOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *RmDupReport) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.RmDupOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+func (this *RmDupReport) Call(v1 iterator.IReadIterator[ipair.IPair[int64, []string]], context api.IContext) ([]ipair.IPair[string, string], error) {
+	result := make([]ipair.IPair[string, string], 0)
+	histogram := make(map[int64]int64)
+
+	for v1.HasNext() {
+		v, err := v1.Next()
+		if err != nil {
+			return nil, err
+		}
+		if len(v.Second) == 1 {
+			histogram[1]++
+			continue
+		}
+		entries, err := rmDupSplit(this.alphabet, &this.opts, v.Second)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			histogram[int64(len(entry.ids))]++
+			if len(entry.ids) == 1 {
+				continue
+			}
+			line := fmt.Sprintf("%s\t%d\t%s\t%s", entry.record.Name, len(entry.ids),
+				strings.Join(entry.ids[1:], ","), entry.record.Seq.Seq)
+			if len(entry.record.Seq.Qual) > 0 {
+				line += "\t" + string(entry.record.Seq.Qual)
+			}
+			result = append(result, *ipair.New("report", line))
+		}
+	}
+
+	levels, err := json.Marshal(histogram)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, *ipair.New("histogram", string(levels))), nil
+}
+
+const (
+	opticalKept = iota
+	opticalDup
+	pcrDup
+)
+
+// opticalRead is a read (or read pair) of an optical duplicate group, value is the text sent through the shuffle.
+type opticalRead struct {
+	value   string
+	name    string
+	lane    string
+	tile    string
+	x, y    int
+	located bool
+	class   int
+}
+
+// opticalKey returns the key of a read for optical duplicate detection, the first prefix bases of the sequence
+// (the whole sequence when prefix is 0). Copies of the same cluster are read on the same strand, so the
+// sequence is never canonicalized.
+func opticalKey(record *fastx.Record, prefix int, ignoreCase bool) []byte {
+	subject := record.Seq.Seq
+	if prefix > 0 && len(subject) > prefix {
+		subject = subject[:prefix]
+	}
+	if ignoreCase {
+		return bytes.ToLower(subject)
+	}
+	return append([]byte(nil), subject...)
+}
+
+// opticalLocate parses lane, tile and coordinates from an Illumina read name.
+func opticalLocate(re *regexp.Regexp, name []byte, read *opticalRead) {
+	found := re.FindSubmatch(name)
+	if found == nil {
+		return
+	}
+	x, err := strconv.Atoi(string(found[3]))
+	if err != nil {
+		return
+	}
+	y, err := strconv.Atoi(string(found[4]))
+	if err != nil {
+		return
+	}
+	read.lane, read.tile, read.x, read.y, read.located = string(found[1]), string(found[2]), x, y, true
+}
+
+// opticalClassify marks the reads sharing a key: the first read is kept, a read on the same lane and tile
+// within distance pixels of a previous copy is an optical duplicate and any other copy is a PCR duplicate.
+// The reads are sorted first by lane, tile, x, y and name (the unlocated reads last), so the kept read and the
+// classes of chained copies do not depend on the order of the shuffle.
+func opticalClassify(reads []*opticalRead, distance int) {
+	sort.Slice(reads, func(i, j int) bool {
+		a, b := reads[i], reads[j]
+		switch {
+		case a.located != b.located:
+			return a.located
+		case a.lane != b.lane:
+			return a.lane < b.lane
+		case a.tile != b.tile:
+			return a.tile < b.tile
+		case a.x != b.x:
+			return a.x < b.x
+		case a.y != b.y:
+			return a.y < b.y
+		}
+		return a.name < b.name
+	})
+	type cell struct {
+		lane, tile string
+		x, y       int
+	}
+	cells := make(map[cell][]*opticalRead)
+	size := distance + 1
+
+	for i, read := range reads {
+		if i == 0 {
+			read.class = opticalKept
+		} else {
+			read.class = pcrDup
+		}
+		if !read.located {
+			continue
+		}
+		cx, cy := read.x/size, read.y/size
+	search:
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				for _, other := range cells[cell{read.lane, read.tile, cx + dx, cy + dy}] {
+					if abs(read.x-other.x) <= distance && abs(read.y-other.y) <= distance {
+						if i > 0 {
+							read.class = opticalDup
+						}
+						break search
+					}
+				}
+			}
+		}
+		c := cell{read.lane, read.tile, cx, cy}
+		cells[c] = append(cells[c], read)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// opticalSplit parses the values of a hash group and returns the reads grouped and classified by their real key.
+func opticalSplit(alphabet *seq.Alphabet, opts *bigseqkit.RmDupOptions, re *regexp.Regexp, group []string) ([][]*opticalRead, error) {
+	paired := len(group) > 0 && strings.IndexByte(group[0], opticalMateSep) >= 0
+	texts := group
+	if paired {
+		texts = make([]string, 0, 2*len(group))
+		for _, v := range group {
+			texts = append(texts, strings.SplitN(v, string(opticalMateSep), 2)...)
+		}
+	}
+
+	fastxReader, err := NewSeqParser(alphabet, NewArrayIterator(texts), *opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, 1)
+	index := make(map[string][]*opticalRead)
+	for _, v := range group {
+		record, err := fastxReader.Read()
+		if err != nil {
+			return nil, err
+		}
+		read := &opticalRead{value: v, name: string(record.Name)}
+		opticalLocate(re, record.Name, read)
+		subject := opticalKey(record, *opts.PrefixLength, *opts.IgnoreCase)
+		if paired {
+			if record, err = fastxReader.Read(); err != nil {
+				return nil, err
+			}
+			subject = append(append(subject, opticalMateSep), opticalKey(record, *opts.PrefixLength, *opts.IgnoreCase)...)
+		}
+		if _, ok := index[string(subject)]; !ok {
+			keys = append(keys, string(subject))
+		}
+		index[string(subject)] = append(index[string(subject)], read)
+	}
+
+	result := make([][]*opticalRead, 0, len(keys))
+	for _, key := range keys {
+		opticalClassify(index[key], *opts.PixelDistance)
+		result = append(result, index[key])
+	}
+	return result, nil
+}
+
+// opticalMateSep joins the mates of a read pair while they are grouped.
+const opticalMateSep = '\x00'
+
+func NewRmDupOpticalJoin() any {
+	return &RmDupOpticalJoin{}
+}
+
+type RmDupOpticalJoin struct {
+	base.IMap[ipair.IPair[string, string], string]
+	function.IOnlyCall
+}
+
+func (this *RmDupOpticalJoin) Call(v ipair.IPair[string, string], context api.IContext) (string, error) {
+	return v.First + string(opticalMateSep) + v.Second, nil
+}
+
+func NewRmDupOpticalSplit() any {
+	return &RmDupOpticalSplit{}
+}
+
+type RmDupOpticalSplit struct {
+	base.IMap[string, ipair.IPair[string, string]]
+	function.IOnlyCall
+}
+
+func (this *RmDupOpticalSplit) Call(v string, context api.IContext) (ipair.IPair[string, string], error) {
+	mates := strings.SplitN(v, string(opticalMateSep), 2)
+	if len(mates) != 2 {
+		return ipair.IPair[string, string]{}, fmt.Errorf("read pair expected")
+	}
+	return *ipair.New(mates[0], mates[1]), nil
+}
+
+// mateIterator returns the mates of the joined read pairs one by one, last keeps the current read pair.
+type mateIterator struct {
+	it     iterator.IReadIterator[string]
+	last   string
+	second *string
+}
+
+func (this *mateIterator) HasNext() bool {
+	return this.second != nil || this.it.HasNext()
+}
+
+func (this *mateIterator) Next() (string, error) {
+	if this.second != nil {
+		second := *this.second
+		this.second = nil
+		return second, nil
+	}
+	v, err := this.it.Next()
+	if err != nil {
+		return "", err
+	}
+	mates := strings.SplitN(v, string(opticalMateSep), 2)
+	if len(mates) != 2 {
+		return "", fmt.Errorf("read pair expected")
+	}
+	this.last = v
+	this.second = &mates[1]
+	return mates[0], nil
+}
+
+func NewRmDupOpticalPrepare() any {
+	return &RmDupOpticalPrepare{}
+}
+
+type RmDupOpticalPrepare struct {
+	base.IMapPartitions[string, ipair.IPair[int64, string]]
+	function.IAfterNone
+	opts     bigseqkit.RmDupOptions
+	alphabet *seq.Alphabet
+	paired   bool
+}
+
+func (this *RmDupOpticalPrepare) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.RmDupOptions](context.Vars()["opts"].(string))
+	this.paired = context.Vars()["paired"].(bool)
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+func (this *RmDupOpticalPrepare) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]ipair.IPair[int64, string], error) {
+	result := make([]ipair.IPair[int64, string], 0, 100)
+
+	if this.paired {
+		mates := &mateIterator{it: v1}
+		fastxReader, err := NewSeqParser(this.alphabet, mates, *this.opts.Config.IDRegexp)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			record, err := fastxReader.Read()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+			subject := opticalKey(record, *this.opts.PrefixLength, *this.opts.IgnoreCase)
+			if record, err = fastxReader.Read(); err != nil {
+				return nil, err
+			}
+			subject = append(append(subject, opticalMateSep), opticalKey(record, *this.opts.PrefixLength, *this.opts.IgnoreCase)...)
+			result = append(result, *ipair.New(int64(xxhash.Sum64(subject)), mates.last))
+		}
+		return result, nil
+	}
+
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if fastxReader.IsFastq {
+			*this.opts.Config.LineWidth = 0
+			fastx.ForcelyOutputFastq = true
+		}
+
+		subject := xxhash.Sum64(opticalKey(record, *this.opts.PrefixLength, *this.opts.IgnoreCase))
+		bb := record.Format(*this.opts.Config.LineWidth)
+		result = append(result, *ipair.New(int64(subject), string(bb[:len(bb)-1])))
+	}
+
+	return result, nil
+}
+
+func NewRmDupOptical() any {
+	return &RmDupOptical{}
+}
+
+type RmDupOptical struct {
+	base.IFlatmap[ipair.IPair[int64, []string], string]
+	function.IAfterNone
+	opts     bigseqkit.RmDupOptions
+	alphabet *seq.Alphabet
+	re       *regexp.Regexp
+}
+
+func (this *RmDupOptical) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.RmDupOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	if err != nil {
+		return err
+	}
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	this.re, err = regexp.Compile(*this.opts.IlluminaRegexp)
+	return err
+}
+
+func (this *RmDupOptical) Call(v ipair.IPair[int64, []string], context api.IContext) ([]string, error) {
+	if len(v.Second) == 1 {
+		return v.Second, nil
+	}
+
+	groups, err := opticalSplit(this.alphabet, &this.opts, this.re, v.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(groups))
+	for _, reads := range groups {
+		for _, read := range reads {
+			switch {
+			case read.class == opticalKept,
+				read.class == opticalDup && *this.opts.RemoveClass == "pcr",
+				read.class == pcrDup && *this.opts.RemoveClass == "optical":
+				result = append(result, read.value)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func NewRmDupOpticalCount() any {
+	return &RmDupOpticalCount{}
+}
+
+type RmDupOpticalCount struct {
+	base.IMapPartitions[ipair.IPair[int64, []string], map[int64]int64]
+	function.IAfterNone
+	opts     bigseqkit.RmDupOptions
+	alphabet *seq.Alphabet
+	re       *regexp.Regexp
+}
+
+func (this *RmDupOpticalCount) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.RmDupOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	if err != nil {
+		return err
+	}
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	this.re, err = regexp.Compile(*this.opts.IlluminaRegexp)
+	return err
+}
+
+func (this *RmDupOpticalCount) Call(v1 iterator.IReadIterator[ipair.IPair[int64, []string]], context api.IContext) ([]map[int64]int64, error) {
+	counts := map[int64]int64{bigseqkit.OpticalDupReads: 0, bigseqkit.OpticalDupOptical: 0, bigseqkit.OpticalDupPCR: 0}
+
+	for v1.HasNext() {
+		v, err := v1.Next()
+		if err != nil {
+			return nil, err
+		}
+		counts[bigseqkit.OpticalDupReads] += int64(len(v.Second))
+		if len(v.Second) == 1 {
+			continue
+		}
+		groups, err := opticalSplit(this.alphabet, &this.opts, this.re, v.Second)
+		if err != nil {
+			return nil, err
+		}
+		for _, reads := range groups {
+			for _, read := range reads {
+				switch read.class {
+				case opticalDup:
+					counts[bigseqkit.OpticalDupOptical]++
+				case pcrDup:
+					counts[bigseqkit.OpticalDupPCR]++
+				}
+			}
+		}
+	}
+
+	return []map[int64]int64{counts}, nil
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/shenwei356/bio/seqio/fastx"
 	"ignis/executor/api"
 	"ignis/executor/api/base"
-	"ignis/executor/api/function"
 	"ignis/executor/api/iterator"
 	log "ignis/executor/core/logger"
 	"io"
@@ -20,34 +19,22 @@ func NewSeqTransform() any {
 
 type SeqTransform struct {
 	base.IMapPartitions[string, string]
-	function.IAfterNone
 	opts     bigseqkit.SeqOptions
 	alphabet *seq.Alphabet
+	metrics  *metrics
 }
 
 func (this *SeqTransform) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.SeqOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	if err != nil {
 		return err
 	}
 	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
-
-	if *this.opts.GapLetters == "" {
-		return fmt.Errorf("value of flag -G (--gap-letters) should not be empty")
-	}
-	for _, c := range *this.opts.GapLetters {
-		if c > 127 {
-			return fmt.Errorf("value of -G (--gap-letters) contains non-ASCII characters")
-		}
-	}
-
-	if *this.opts.MinLen >= 0 && *this.opts.MaxLen >= 0 && *this.opts.MinLen > *this.opts.MaxLen {
-		return fmt.Errorf("value of flag -m (--min-len) should be >= value of flag -M (--max-len)")
-	}
-	if *this.opts.MinQual >= 0 && *this.opts.MaxQual >= 0 && *this.opts.MinQual > *this.opts.MaxQual {
-		return fmt.Errorf("value of flag -Q (--min-qual) should be <= value of flag -R (--max-qual)")
-	}
+	this.metrics = newMetrics(context, "SeqTransform")
 
 	if (*this.opts.MinLen >= 0 || *this.opts.MaxLen >= 0) && !*this.opts.RemoveGaps {
 		log.Warn("you may switch on flag -g/--remove-gaps to remove spaces")
@@ -71,10 +58,11 @@ func (this *SeqTransform) Before(context api.IContext) (err error) {
 		seq.ValidateSeq = true
 	}
 
-	if *this.opts.LowerCase && *this.opts.UpperCase {
-		return fmt.Errorf("could not give both flags -l (--lower-case) and -u (--upper-case)")
-	}
+	return nil
+}
 
+func (this *SeqTransform) After(context api.IContext) (err error) {
+	this.metrics.save()
 	return nil
 }
 
@@ -99,6 +87,8 @@ func (this *SeqTransform) Call(v1 iterator.IReadIterator[string], context api.IC
 	var buffer *bytes.Buffer
 
 	result := make([]string, 0, 100)
+	counters := make(map[string]int64)
+	defer this.metrics.add(counters)
 
 	checkSeqType = true
 	printQual = false
@@ -116,6 +106,7 @@ func (this *SeqTransform) Call(v1 iterator.IReadIterator[string], context api.IC
 			}
 			return nil, err
 		}
+		counters["records_in"]++
 
 		if checkSeqType {
 			isFastq = fastxReader.IsFastq
@@ -131,19 +122,23 @@ func (this *SeqTransform) Call(v1 iterator.IReadIterator[string], context api.IC
 		}
 
 		if filterMinLen && len(record.Seq.Seq) < *this.opts.MinLen {
+			counters["dropped_min_len"]++
 			continue
 		}
 
 		if filterMaxLen && len(record.Seq.Seq) > *this.opts.MaxLen {
+			counters["dropped_max_len"]++
 			continue
 		}
 
 		if filterMinQual || filterMaxQual {
 			avgQual := record.Seq.AvgQual(*this.opts.QualAsciiBase)
 			if filterMinQual && avgQual < *this.opts.MinQual {
+				counters["dropped_min_qual"]++
 				continue
 			}
 			if filterMaxQual && avgQual >= *this.opts.MaxQual {
+				counters["dropped_max_qual"]++
 				continue
 			}
 		}
@@ -263,6 +258,7 @@ func (this *SeqTransform) Call(v1 iterator.IReadIterator[string], context api.IC
 			ss = ss[:len(ss)-1]
 		}
 		result = append(result, ss)
+		counters["records_out"]++
 	}
 
 	return result, nil
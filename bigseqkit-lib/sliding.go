@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bigseqkit"
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"github.com/shenwei356/bio/seqio/fastx"
+	"ignis/executor/api"
+	"ignis/executor/api/base"
+	"ignis/executor/api/function"
+	"ignis/executor/api/iterator"
+	log "ignis/executor/core/logger"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const slidingSep = "\x00"
+
+func NewSlidingSplit() any {
+	return &SlidingSplit{}
+}
+
+type SlidingSplit struct {
+	base.IMapPartitions[string, string]
+	function.IAfterNone
+	opts     bigseqkit.SlidingOptions
+	alphabet *seq.Alphabet
+}
+
+func (this *SlidingSplit) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.SlidingOptions](context.Vars()["opts"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call splits the records into chunks of whole windows: ID, description, sequence length, first window
+// start, number of windows, sequence and quality of the chunk, separated by slidingSep. In circular mode
+// the chunks include the beginning of the sequence needed by the last windows.
+func (this *SlidingSplit) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	step, window := *this.opts.Step, *this.opts.Window
+	result := make([]string, 0, 100)
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		s, q := record.Seq.Seq, record.Seq.Qual
+		l := len(s)
+		if *this.opts.Circular && window > l {
+			log.Warn(fmt.Sprintf("window size (%d) is longer than the circular sequence %s (%d bp), skipped", window, record.ID, l))
+			continue
+		}
+
+		var n int
+		if *this.opts.Circular || *this.opts.Greedy {
+			n = (l + step - 1) / step
+		} else if l >= window {
+			n = (l-window)/step + 1
+		}
+		perChunk := n
+		if *this.opts.SplitLen > 0 {
+			perChunk = *this.opts.SplitLen / step
+			if perChunk < 1 {
+				perChunk = 1
+			}
+		}
+
+		desc := string(record.Desc)
+		for k := 0; k < n; k += perChunk {
+			c := perChunk
+			if n-k < c {
+				c = n - k
+			}
+			start := k * step
+			end := start + (c-1)*step + window
+			chunkSeq, chunkQual := slidingChunk(s, start, end), slidingChunk(q, start, end)
+			if *this.opts.Circular && end > l {
+				chunkSeq += slidingChunk(s, 0, end-l)
+				chunkQual += slidingChunk(q, 0, end-l)
+			}
+			result = append(result, strings.Join([]string{string(record.ID), desc, strconv.Itoa(l),
+				strconv.Itoa(start), strconv.Itoa(c), chunkSeq, chunkQual}, slidingSep))
+		}
+	}
+
+	return result, nil
+}
+
+func slidingChunk(s []byte, start, end int) string {
+	if start >= len(s) {
+		return ""
+	}
+	if end > len(s) {
+		end = len(s)
+	}
+	return string(s[start:end])
+}
+
+func NewSliding() any {
+	return &Sliding{}
+}
+
+type Sliding struct {
+	base.IMapPartitions[string, string]
+	function.IAfterNone
+	opts bigseqkit.SlidingOptions
+}
+
+func (this *Sliding) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.SlidingOptions](context.Vars()["opts"].(string))
+	return nil
+}
+
+// slidingStats returns the GC content, GC skew, AT skew, N fraction and Shannon entropy (bits, of A, C,
+// G and T/U) of a window.
+func slidingStats(s string) (gc, gcSkew, atSkew, nFrac, entropy float64) {
+	var a, c, g, t, n int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case 'A', 'a':
+			a++
+		case 'C', 'c':
+			c++
+		case 'G', 'g':
+			g++
+		case 'T', 't', 'U', 'u':
+			t++
+		case 'N', 'n':
+			n++
+		}
+	}
+	if acgt := a + c + g + t; acgt > 0 {
+		gc = float64(g+c) / float64(acgt)
+		for _, x := range []int{a, c, g, t} {
+			if x > 0 {
+				p := float64(x) / float64(acgt)
+				entropy -= p * math.Log2(p)
+			}
+		}
+	}
+	if g+c > 0 {
+		gcSkew = float64(g-c) / float64(g+c)
+	}
+	if a+t > 0 {
+		atSkew = float64(a-t) / float64(a+t)
+	}
+	if len(s) > 0 {
+		nFrac = float64(n) / float64(len(s))
+	}
+	return
+}
+
+func (this *Sliding) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
+	step, window := *this.opts.Step, *this.opts.Window
+	result := make([]string, 0, 100)
+	for v1.HasNext() {
+		chunk, err := v1.Next()
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Split(chunk, slidingSep)
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid sliding chunk")
+		}
+		id, desc, s, q := fields[0], fields[1], fields[5], fields[6]
+		l, _ := strconv.Atoi(fields[2])
+		first, _ := strconv.Atoi(fields[3])
+		n, _ := strconv.Atoi(fields[4])
+
+		for j := 0; j < n; j++ {
+			r := j * step
+			e := r + window
+			if e > len(s) {
+				e = len(s)
+			}
+			start, end := first+r+1, first+e
+			if end > l {
+				end -= l
+			}
+
+			if *this.opts.Stats {
+				gc, gcSkew, atSkew, nFrac, entropy := slidingStats(s[r:e])
+				result = append(result, fmt.Sprintf("%s\t%d\t%d\t%d\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f",
+					id, start, end, e-r, gc, gcSkew, atSkew, nFrac, entropy))
+				continue
+			}
+
+			windowID := fmt.Sprintf("%s%s:%d-%d", id, *this.opts.Suffix, start, end)
+			name := windowID
+			if desc != "" {
+				name += " " + desc
+			}
+			var record *fastx.Record
+			if q != "" {
+				record, err = fastx.NewRecordWithQualWithoutValidation(seq.Unlimit, []byte(windowID), []byte(name), nil, []byte(s[r:e]), []byte(q[r:e]))
+			} else {
+				record, err = fastx.NewRecordWithoutValidation(seq.Unlimit, []byte(windowID), []byte(name), nil, []byte(s[r:e]))
+			}
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, string(record.Format(*this.opts.Config.LineWidth)))
+		}
+	}
+
+	return result, nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"bigseqkit"
-	"fmt"
 	"github.com/shenwei356/bio/seq"
 	"github.com/shenwei356/bio/seqio/fastx"
 	"github.com/shenwei356/util/byteutil"
@@ -26,6 +25,9 @@ type Stats struct {
 
 func (this *Stats) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.StatsOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	if err != nil {
 		return err
@@ -33,15 +35,6 @@ func (this *Stats) Before(context api.IContext) (err error) {
 	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
 	seq.ValidateSeq = false
 
-	if len(*this.opts.GapLetters) == 0 {
-		return fmt.Errorf("value of flag -G (--gap-letters) should not be empty")
-	}
-	for _, c := range *this.opts.GapLetters {
-		if c > 127 {
-			return fmt.Errorf("value of -G (--gap-letters) contains non-ASCII characters")
-		}
-	}
-
 	return err
 }
 
@@ -126,12 +119,21 @@ type StatsReduce struct {
 }
 
 func (this *StatsReduce) Call(v1 map[int64]int64, v2 map[int64]int64, context api.IContext) (map[int64]int64, error) {
+	T := int64(-4)
 	result := make(map[int64]int64)
 	for k, v := range v1 {
 		result[k] = v
 	}
 	for k, v := range v2 {
-		result[k] = v
+		if k != T {
+			result[k] += v
+		}
+	}
+	// the type of an empty partition is Unlimit, partitions of different types are checked in the file
+	if t1, t2 := v1[T], v2[T]; t1 == int64('U') {
+		result[T] = t2
+	} else if t2 != int64('U') && t1 != t2 {
+		result[T] = int64('F')
 	}
 	return result, nil
 }
@@ -11,10 +11,13 @@ import (
 	"ignis/executor/api"
 	"ignis/executor/api/base"
 	"ignis/executor/api/function"
+	"ignis/executor/api/ipair"
 	"ignis/executor/api/iterator"
 	log "ignis/executor/core/logger"
 	"io"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -26,11 +29,10 @@ func NewSubseqTransform() any {
 type SubseqTransform struct {
 	base.IMapPartitions[string, string]
 	function.IAfterNone
-	opts           bigseqkit.SubseqOptions
-	alphabet       *seq.Alphabet
-	start, end     int
-	gtfFeaturesMap map[string]type2gtfFeatures
-	bedFeatureMap  map[string][]BedFeature
+	subseqFeatures
+	opts       bigseqkit.SubseqOptions
+	alphabet   *seq.Alphabet
+	start, end int
 }
 
 func (this *SubseqTransform) Before(context api.IContext) (err error) {
@@ -43,42 +45,12 @@ func (this *SubseqTransform) Before(context api.IContext) (err error) {
 	seq.ValidateSeq = false
 	fai.MapWholeFile = false
 
-	chrs2 := make([]string, len(*this.opts.Chr))
-	for i, chr := range *this.opts.Chr {
-		chrs2[i] = chr
-	}
-	*this.opts.Chr = chrs2
-	chrsMap := make(map[string]struct{}, len(*this.opts.Chr))
-	for _, chr := range *this.opts.Chr {
-		chrsMap[strings.ToLower(chr)] = struct{}{}
-	}
-
-	choosedFeatures2 := make([]string, len(*this.opts.Feature))
-	for i, f := range *this.opts.Feature {
-		choosedFeatures2[i] = strings.ToLower(f)
-	}
-	*this.opts.Feature = choosedFeatures2
-
-	if *this.opts.OnlyFlank {
-		if *this.opts.UpStream > 0 && *this.opts.DownStream > 0 {
-			return fmt.Errorf("when flag -f (--only-flank) given," +
-				" only one of flags -u (--up-stream) and -d (--down-stream) is allowed")
-		} else if *this.opts.UpStream == 0 && *this.opts.DownStream == 0 {
-			return fmt.Errorf("when flag -f (--only-flank) given," +
-				" one of flags -u (--up-stream) and -d (--down-stream) should be given")
-		}
-	}
-	if *this.opts.Region != "" {
-		if *this.opts.UpStream > 0 || *this.opts.DownStream > 0 || *this.opts.OnlyFlank {
-			return fmt.Errorf("when flag -r (--region) given," +
-				" any of flags -u (--up-stream), -d (--down-stream) and -f (--only-flank) is not allowed")
-		}
+	attributes, err := subseqCheckOptions(&this.opts)
+	if err != nil {
+		return err
 	}
 
 	if *this.opts.Region != "" {
-		if !reRegion.MatchString(*this.opts.Region) {
-			return fmt.Errorf(`invalid region: %s. type "seqkit subseq -h" for more examples`, *this.opts.Region)
-		}
 		r := strings.Split(*this.opts.Region, ":")
 		this.start, err = strconv.Atoi(r[0])
 		if err != nil {
@@ -88,17 +60,10 @@ func (this *SubseqTransform) Before(context api.IContext) (err error) {
 		if err != nil {
 			return err
 		}
-		if this.start == 0 || this.end == 0 {
-			return fmt.Errorf("both start and end should not be 0")
-		}
-		if this.start < 0 && this.end > 0 {
-			return fmt.Errorf("when start < 0, end should not > 0")
-		}
 	} else if *this.opts.Gtf != "" {
 		if !*this.opts.Config.Quiet {
 			log.Info("read GTF file ...")
 		}
-		this.gtfFeaturesMap = make(map[string]type2gtfFeatures)
 
 		gtf.Threads = context.Threads() // threads of gtf.ReadFeatures
 		var features []gtf.Feature
@@ -112,29 +77,27 @@ func (this *SubseqTransform) Before(context api.IContext) (err error) {
 			return err
 		}
 
-		var chr, feat string
-		for _, feature := range features {
-			chr = strings.ToLower(feature.SeqName)
-			if _, ok := this.gtfFeaturesMap[chr]; !ok {
-				this.gtfFeaturesMap[chr] = make(map[string][]gtf.Feature)
-			}
-			feat = strings.ToLower(feature.Feature)
-			if _, ok := this.gtfFeaturesMap[chr][feat]; !ok {
-				this.gtfFeaturesMap[chr][feat] = []gtf.Feature{}
-			}
-			this.gtfFeaturesMap[chr][feat] = append(this.gtfFeaturesMap[chr][feat], feature)
-		}
+		this.loadGtf(features)
 		if !*this.opts.Config.Quiet {
 			log.Info(fmt.Sprintf("%d GTF features loaded", len(features)))
 		}
+	} else if *this.opts.Gff != "" {
+		if !*this.opts.Config.Quiet {
+			log.Info("read GFF3 file ...")
+		}
+		features, err := ReadGff3FilteredFeatures(*this.opts.Gff, *this.opts.Chr, context.Threads())
+		if err != nil {
+			return err
+		}
+
+		this.loadGff3(features, &this.opts, attributes)
+		if !*this.opts.Config.Quiet {
+			log.Info(fmt.Sprintf("%d GFF3 features loaded", len(features)))
+		}
 	} else if *this.opts.Bed != "" {
 		if !*this.opts.Config.Quiet {
 			log.Info("read BED file ...")
 		}
-		if len(*this.opts.Feature) > 0 {
-			return fmt.Errorf("when given flag -b (--bed), flag -f (--feature) is not allowed")
-		}
-		this.bedFeatureMap = make(map[string][]BedFeature)
 
 		var features []BedFeature
 		if len(*this.opts.Chr) > 0 {
@@ -146,19 +109,12 @@ func (this *SubseqTransform) Before(context api.IContext) (err error) {
 			return err
 		}
 
-		var chr string
-		for _, feature := range features {
-			chr = strings.ToLower(feature.Chr)
-			if _, ok := this.bedFeatureMap[chr]; !ok {
-				this.bedFeatureMap[chr] = []BedFeature{}
-			}
-			this.bedFeatureMap[chr] = append(this.bedFeatureMap[chr], feature)
-		}
+		this.loadBed(features, &this.opts)
 		if !*this.opts.Config.Quiet {
 			log.Info(fmt.Sprintf("%d BED features loaded", len(features)))
 		}
 	} else {
-		return fmt.Errorf("one of the options needed: -r/--region, --bed, --gtf")
+		return fmt.Errorf("one of the options needed: -r/--region, --bed, --gtf, --gff")
 	}
 
 	return nil
@@ -188,49 +144,153 @@ func (this *SubseqTransform) Call(v1 iterator.IReadIterator[string], context api
 
 		if *this.opts.Region != "" {
 			result = append(result, string(subseqByRegion(record, *this.opts.Config.LineWidth, this.start, this.end)))
+			continue
+		}
 
-		} else if *this.opts.Gtf != "" {
-			seqname := strings.ToLower(string(record.ID))
-			if _, ok := this.gtfFeaturesMap[seqname]; !ok {
-				continue
-			}
+		r, err := this.subseq(record, 0, &this.opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, bb := range r {
+			result = append(result, string(bb))
+		}
+	}
+	return result, nil
+}
 
-			r, err := subseqByGTFFile(record, *this.opts.Config.LineWidth,
-				this.gtfFeaturesMap, *this.opts.Feature,
-				*this.opts.OnlyFlank, *this.opts.UpStream, *this.opts.DownStream, *this.opts.GtfTag)
-			if err != nil {
-				return nil, err
-			}
-			if r != nil {
-				result = append(result, string(r))
-			}
+// subseqCheckOptions normalizes and checks the options, returning the parsed GFF3 attribute filters.
+func subseqCheckOptions(opts *bigseqkit.SubseqOptions) (map[string]string, error) {
+	chrs2 := make([]string, len(*opts.Chr))
+	for i, chr := range *opts.Chr {
+		chrs2[i] = chr
+	}
+	*opts.Chr = chrs2
 
-		} else if *this.opts.Bed != "" {
-			seqname := strings.ToLower(string(record.ID))
-			if _, ok := this.bedFeatureMap[seqname]; !ok {
-				continue
-			}
-			r, err := subSeqByBEDFile(record, *this.opts.Config.LineWidth,
-				this.bedFeatureMap,
-				*this.opts.OnlyFlank, *this.opts.UpStream, *this.opts.DownStream)
-			if err != nil {
-				return nil, err
-			}
-			if r != nil {
-				result = append(result, string(r))
-			}
+	choosedFeatures2 := make([]string, len(*opts.Feature))
+	for i, f := range *opts.Feature {
+		choosedFeatures2[i] = strings.ToLower(f)
+	}
+	*opts.Feature = choosedFeatures2
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return parseGff3AttributeFilters(*opts.Attribute)
+}
+
+// subseqFeatures holds the annotation features by chromosome (lower case).
+type subseqFeatures struct {
+	gtfFeaturesMap map[string]type2gtfFeatures
+	bedFeatureMap  map[string][]BedFeature
+	splicedMap     map[string][]*splicedFeature
+}
+
+func (this *subseqFeatures) loadGtf(features []gtf.Feature) {
+	this.gtfFeaturesMap = make(map[string]type2gtfFeatures)
+
+	var chr, feat string
+	for _, feature := range features {
+		chr = strings.ToLower(feature.SeqName)
+		if _, ok := this.gtfFeaturesMap[chr]; !ok {
+			this.gtfFeaturesMap[chr] = make(map[string][]gtf.Feature)
 		}
+		feat = strings.ToLower(feature.Feature)
+		if _, ok := this.gtfFeaturesMap[chr][feat]; !ok {
+			this.gtfFeaturesMap[chr][feat] = []gtf.Feature{}
+		}
+		this.gtfFeaturesMap[chr][feat] = append(this.gtfFeaturesMap[chr][feat], feature)
 	}
-	return result, nil
+}
+
+func (this *subseqFeatures) loadGff3(features []Gff3Feature, opts *bigseqkit.SubseqOptions, attributes map[string]string) {
+	if *opts.Extract != "" {
+		this.splicedMap = gff3SplicedFeatures(features, *opts.Extract, *opts.Feature, attributes)
+		return
+	}
+
+	featsMap := make(map[string]struct{}, len(*opts.Feature))
+	for _, f := range *opts.Feature {
+		featsMap[f] = struct{}{}
+	}
+	this.gtfFeaturesMap = make(map[string]type2gtfFeatures)
+	for i := range features {
+		feature := &features[i]
+		feat := strings.ToLower(feature.Type)
+		if _, ok := featsMap[feat]; len(featsMap) > 0 && !ok {
+			continue
+		}
+		if !feature.match(attributes) {
+			continue
+		}
+		chr := strings.ToLower(feature.SeqID)
+		if _, ok := this.gtfFeaturesMap[chr]; !ok {
+			this.gtfFeaturesMap[chr] = make(map[string][]gtf.Feature)
+		}
+		this.gtfFeaturesMap[chr][feat] = append(this.gtfFeaturesMap[chr][feat], feature.toGtf())
+	}
+}
+
+func (this *subseqFeatures) loadBed(features []BedFeature, opts *bigseqkit.SubseqOptions) {
+	if *opts.Extract != "" {
+		this.splicedMap = bedSplicedFeatures(features, *opts.Extract)
+		return
+	}
+
+	this.bedFeatureMap = make(map[string][]BedFeature)
+
+	var chr string
+	for _, feature := range features {
+		chr = strings.ToLower(feature.Chr)
+		if _, ok := this.bedFeatureMap[chr]; !ok {
+			this.bedFeatureMap[chr] = []BedFeature{}
+		}
+		this.bedFeatureMap[chr] = append(this.bedFeatureMap[chr], feature)
+	}
+}
+
+// subseq returns the sequences of the features of the record. The record may be a slice of the sequence,
+// offset is the position before its first base, the feature coordinates are not changed.
+func (this *subseqFeatures) subseq(record *fastx.Record, offset int, opts *bigseqkit.SubseqOptions) ([][]byte, error) {
+	seqname := strings.ToLower(string(record.ID))
+
+	if this.splicedMap != nil {
+		features, ok := this.splicedMap[seqname]
+		if !ok {
+			return nil, nil
+		}
+		return subseqSpliced(record, offset, *opts.Config.LineWidth, features,
+			*opts.Extract, *opts.GroupBy, *opts.TranslTable)
+
+	} else if this.gtfFeaturesMap != nil {
+		if _, ok := this.gtfFeaturesMap[seqname]; !ok {
+			return nil, nil
+		}
+		return subseqByGTFFile(record, offset, *opts.Config.LineWidth,
+			this.gtfFeaturesMap, *opts.Feature,
+			*opts.OnlyFlank, *opts.UpStream, *opts.DownStream, *opts.GtfTag)
+
+	} else if this.bedFeatureMap != nil {
+		if _, ok := this.bedFeatureMap[seqname]; !ok {
+			return nil, nil
+		}
+		return subSeqByBEDFile(record, offset, *opts.Config.LineWidth,
+			this.bedFeatureMap,
+			*opts.OnlyFlank, *opts.UpStream, *opts.DownStream)
+	}
+	return nil, nil
 }
 
 // BedFeature is the gff BedFeature struct
 type BedFeature struct {
-	Chr    string
-	Start  int // 1based
-	End    int // end included
-	Name   *string
-	Strand *string
+	Chr        string
+	Start      int // 1based
+	End        int // end included
+	Name       *string
+	Strand     *string
+	ThickStart int      // 1based, BED12 only
+	ThickEnd   int      // end included, BED12 only
+	Blocks     [][2]int // 1based and end included, BED12 only
 }
 
 // ReadBedFeatures returns gtf BedFeatures of a file
@@ -249,64 +309,588 @@ func ReadBedFilteredFeatures(file string, chrs []string, threads int) ([]BedFeat
 	}
 
 	fn := func(line string) (interface{}, bool, error) {
-		line = strings.TrimRight(line, "\r\n")
+		feature, err := parseBedLine(line, chrsMap)
+		if feature == nil || err != nil {
+			return nil, false, err
+		}
+		return *feature, true, nil
+	}
+	reader, err := breader.NewBufferedReader(file, threads, 100, fn)
+	if err != nil {
+		return nil, err
+	}
+	BedFeatures := make([]BedFeature, 0, 1024)
+	for chunk := range reader.Ch {
+		if chunk.Err != nil {
+			return nil, chunk.Err
+		}
+		for _, data := range chunk.Data {
+			BedFeatures = append(BedFeatures, data.(BedFeature))
+		}
+	}
+	return BedFeatures, nil
+}
 
-		if line == "" || line[0] == '#' || (len(line) > 7 && string(line[0:7]) == "browser") || (len(line) > 5 && string(line[0:5]) == "track") {
-			return nil, false, nil
+// parseBedLine parses a BED line, returning nil for headers, comments and lines of not selected chrs
+func parseBedLine(line string, chrsMap map[string]struct{}) (*BedFeature, error) {
+	line = strings.TrimRight(line, "\r\n")
+
+	if line == "" || line[0] == '#' || (len(line) > 7 && string(line[0:7]) == "browser") || (len(line) > 5 && string(line[0:5]) == "track") {
+		return nil, nil
+	}
+
+	items := strings.Split(line, "\t")
+	n := len(items)
+	if n < 3 {
+		return nil, nil
+	}
+
+	if len(chrsMap) > 0 { // selected chrs
+		if _, ok := chrsMap[items[0]]; !ok {
+			return nil, nil
 		}
+	}
 
-		items := strings.Split(line, "\t")
-		n := len(items)
-		if n < 3 {
-			return nil, false, nil
+	start, err := strconv.Atoi(items[1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad start: %s", items[0], items[1])
+	}
+	end, err := strconv.Atoi(items[2])
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad end: %s", items[0], items[2])
+	}
+	if start >= end {
+		return nil, fmt.Errorf("%s: start (%d) must be <= end (%d)", items[0], start, end)
+	}
+
+	var name *string
+	if n >= 4 {
+		name = &items[3]
+	}
+	var strand *string
+	if n >= 6 {
+		if items[5] != "+" && items[5] != "-" && items[5] != "." {
+			return nil, fmt.Errorf("bad strand: %s", items[5])
 		}
+		strand = &items[5]
+	}
 
-		if len(chrs) > 0 { // selected chrs
-			if _, ok := chrsMap[items[0]]; !ok {
-				return nil, false, nil
-			}
+	feature := BedFeature{Chr: items[0], Start: start + 1, End: end, Name: name, Strand: strand}
+	if n >= 12 {
+		if err = parseBed12Blocks(&feature, items); err != nil {
+			return nil, err
 		}
+	}
+
+	return &feature, nil
+}
 
-		start, err := strconv.Atoi(items[1])
+// parseBed12Blocks parses thickStart, thickEnd and the blocks (columns 7, 8, 10, 11 and 12) of a BED12 line.
+func parseBed12Blocks(feature *BedFeature, items []string) error {
+	thickStart, err := strconv.Atoi(items[6])
+	if err != nil {
+		return fmt.Errorf("%s: bad thickStart: %s", items[0], items[6])
+	}
+	thickEnd, err := strconv.Atoi(items[7])
+	if err != nil {
+		return fmt.Errorf("%s: bad thickEnd: %s", items[0], items[7])
+	}
+	feature.ThickStart, feature.ThickEnd = thickStart+1, thickEnd
+
+	count, err := strconv.Atoi(items[9])
+	if err != nil {
+		return fmt.Errorf("%s: bad blockCount: %s", items[0], items[9])
+	}
+	sizes := strings.Split(strings.TrimRight(items[10], ","), ",")
+	starts := strings.Split(strings.TrimRight(items[11], ","), ",")
+	if len(sizes) != count || len(starts) != count {
+		return fmt.Errorf("%s: blockCount (%d) does not match blockSizes and blockStarts", items[0], count)
+	}
+
+	feature.Blocks = make([][2]int, count)
+	for i := 0; i < count; i++ {
+		size, err := strconv.Atoi(sizes[i])
 		if err != nil {
-			return nil, false, fmt.Errorf("%s: bad start: %s", items[0], items[1])
+			return fmt.Errorf("%s: bad blockSizes: %s", items[0], items[10])
 		}
-		end, err := strconv.Atoi(items[2])
+		start, err := strconv.Atoi(starts[i])
 		if err != nil {
-			return nil, false, fmt.Errorf("%s: bad end: %s", items[0], items[2])
-		}
-		if start >= end {
-			return nil, false, fmt.Errorf("%s: start (%d) must be <= end (%d)", items[0], start, end)
+			return fmt.Errorf("%s: bad blockStarts: %s", items[0], items[11])
 		}
+		feature.Blocks[i] = [2]int{feature.Start + start, feature.Start + start + size - 1}
+	}
+	return nil
+}
 
-		var name *string
-		if n >= 4 {
-			name = &items[3]
-		}
-		var strand *string
-		if n >= 6 {
-			if items[5] != "+" && items[5] != "-" && items[5] != "." {
-				return nil, false, fmt.Errorf("bad strand: %s", items[5])
+// Gff3Feature is a feature of a GFF3 file
+type Gff3Feature struct {
+	SeqID      string
+	Source     string
+	Type       string
+	Start      int // 1based
+	End        int // end included
+	Score      *float64
+	Strand     *string
+	Phase      int // -1 when not given
+	ID         string
+	Parents    []string
+	Attributes map[string][]string
+}
+
+func (this *Gff3Feature) match(filters map[string]string) bool {
+	for key, value := range filters {
+		found := false
+		for _, v := range this.Attributes[key] {
+			if v == value {
+				found = true
+				break
 			}
-			strand = &items[5]
 		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *Gff3Feature) toGtf() gtf.Feature {
+	feature := gtf.Feature{
+		SeqName:    this.SeqID,
+		Source:     this.Source,
+		Feature:    this.Type,
+		Start:      this.Start,
+		End:        this.End,
+		Score:      this.Score,
+		Strand:     this.Strand,
+		Attributes: make([]gtf.Attribute, 0, len(this.Attributes)),
+	}
+	if this.Phase >= 0 {
+		phase := this.Phase
+		feature.Frame = &phase
+	}
+	for tag, values := range this.Attributes {
+		feature.Attributes = append(feature.Attributes, gtf.Attribute{Tag: tag, Value: strings.Join(values, ",")})
+	}
+	return feature
+}
+
+// parseGff3AttributeFilters parses the key=value attribute filters.
+func parseGff3AttributeFilters(filters []string) (map[string]string, error) {
+	result := make(map[string]string, len(filters))
+	for _, filter := range filters {
+		i := strings.IndexByte(filter, '=')
+		if i < 1 {
+			return nil, fmt.Errorf("invalid attribute filter: %s. key=value expected", filter)
+		}
+		result[filter[:i]] = filter[i+1:]
+	}
+	return result, nil
+}
 
-		return BedFeature{items[0], start + 1, end, name, strand}, true, nil
+// ReadGff3FilteredFeatures returns the GFF3 features of selected chrs from file
+func ReadGff3FilteredFeatures(file string, chrs []string, threads int) ([]Gff3Feature, error) {
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, err
 	}
-	reader, err := breader.NewBufferedReader(file, threads, 100, fn)
+	chrsMap := make(map[string]struct{}, len(chrs))
+	for _, chr := range chrs {
+		chrsMap[strings.ToLower(chr)] = struct{}{}
+	}
+
+	fasta := false
+	fn := func(line string) (interface{}, bool, error) {
+		if fasta {
+			return nil, false, nil
+		}
+		if strings.HasPrefix(line, "##FASTA") {
+			fasta = true
+			return nil, false, nil
+		}
+		feature, err := parseGff3Line(line, chrsMap)
+		if feature == nil || err != nil {
+			return nil, false, err
+		}
+		return *feature, true, nil
+	}
+	reader, err := breader.NewBufferedReader(file, 1, 100, fn) // sequential, the ##FASTA directive ends the features
 	if err != nil {
 		return nil, err
 	}
-	BedFeatures := make([]BedFeature, 0, 1024)
+	features := make([]Gff3Feature, 0, 1024)
 	for chunk := range reader.Ch {
 		if chunk.Err != nil {
 			return nil, chunk.Err
 		}
 		for _, data := range chunk.Data {
-			BedFeatures = append(BedFeatures, data.(BedFeature))
+			features = append(features, data.(Gff3Feature))
 		}
 	}
-	return BedFeatures, nil
+	return features, nil
+}
+
+// parseGff3Line parses a GFF3 line, returning nil for directives, comments and lines of not selected chrs
+func parseGff3Line(line string, chrsMap map[string]struct{}) (*Gff3Feature, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" || line[0] == '#' {
+		return nil, nil
+	}
+
+	items := strings.Split(line, "\t")
+	if len(items) != 9 {
+		return nil, fmt.Errorf("bad GFF3 line, 9 columns expected: %s", line)
+	}
+
+	if len(chrsMap) > 0 { // selected chrs
+		if _, ok := chrsMap[strings.ToLower(items[0])]; !ok {
+			return nil, nil
+		}
+	}
+
+	start, err := strconv.Atoi(items[3])
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad start: %s", items[0], items[3])
+	}
+	end, err := strconv.Atoi(items[4])
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad end: %s", items[0], items[4])
+	}
+	if start > end {
+		return nil, fmt.Errorf("%s: start (%d) must be <= end (%d)", items[0], start, end)
+	}
+
+	feature := Gff3Feature{
+		SeqID:      items[0],
+		Source:     items[1],
+		Type:       items[2],
+		Start:      start,
+		End:        end,
+		Phase:      -1,
+		Attributes: make(map[string][]string),
+	}
+	if items[5] != "." {
+		score, err := strconv.ParseFloat(items[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: bad score: %s", items[0], items[5])
+		}
+		feature.Score = &score
+	}
+	if items[6] != "+" && items[6] != "-" && items[6] != "." && items[6] != "?" {
+		return nil, fmt.Errorf("bad strand: %s", items[6])
+	}
+	feature.Strand = &items[6]
+	if items[7] != "." {
+		if feature.Phase, err = strconv.Atoi(items[7]); err != nil || feature.Phase < 0 || feature.Phase > 2 {
+			return nil, fmt.Errorf("%s: bad phase: %s", items[0], items[7])
+		}
+	}
+
+	for _, attribute := range strings.Split(strings.TrimRight(items[8], ";"), ";") {
+		attribute = strings.TrimSpace(attribute)
+		i := strings.IndexByte(attribute, '=')
+		if i < 1 {
+			continue
+		}
+		values := strings.Split(attribute[i+1:], ",")
+		for j := range values {
+			if v, err := url.PathUnescape(values[j]); err == nil {
+				values[j] = v
+			}
+		}
+		feature.Attributes[attribute[:i]] = values
+	}
+	if id, ok := feature.Attributes["ID"]; ok {
+		feature.ID = id[0]
+	}
+	feature.Parents = feature.Attributes["Parent"]
+
+	return &feature, nil
+}
+
+// parseGtfLine parses a GTF line keeping only the attribute tag, returning nil for comments and lines of not
+// selected chrs or features
+func parseGtfLine(line string, chrsMap, featsMap map[string]struct{}, tag string) (*gtf.Feature, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" || line[0] == '#' {
+		return nil, nil
+	}
+
+	items := strings.Split(line, "\t")
+	if len(items) != 9 {
+		return nil, nil
+	}
+
+	if len(chrsMap) > 0 { // selected chrs
+		if _, ok := chrsMap[strings.ToLower(items[0])]; !ok {
+			return nil, nil
+		}
+	}
+	if len(featsMap) > 0 { // selected features
+		if _, ok := featsMap[strings.ToLower(items[2])]; !ok {
+			return nil, nil
+		}
+	}
+
+	start, err := strconv.Atoi(items[3])
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad start: %s", items[0], items[3])
+	}
+	end, err := strconv.Atoi(items[4])
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad end: %s", items[0], items[4])
+	}
+	if start > end {
+		return nil, fmt.Errorf("%s: start (%d) must be < end (%d)", items[0], start, end)
+	}
+
+	feature := gtf.Feature{SeqName: items[0], Source: items[1], Feature: items[2], Start: start, End: end}
+	if items[5] != "." {
+		score, err := strconv.ParseFloat(items[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: bad score: %s", items[0], items[5])
+		}
+		feature.Score = &score
+	}
+	if items[6] != "." {
+		if items[6] != "+" && items[6] != "-" {
+			return nil, fmt.Errorf("%s: illigal strand: %s", items[0], items[6])
+		}
+		feature.Strand = &items[6]
+	}
+	if items[7] != "." {
+		frame, err := strconv.Atoi(items[7])
+		if err != nil || frame < 0 || frame > 2 {
+			return nil, fmt.Errorf("%s: bad frame: %s", items[0], items[7])
+		}
+		feature.Frame = &frame
+	}
+
+	feature.Attributes = []gtf.Attribute{}
+	for _, tagValue := range strings.Split(items[8], ";") {
+		items2 := strings.SplitN(strings.TrimSpace(tagValue), " ", 2)
+		if len(items2) != 2 || items2[0] != tag {
+			continue
+		}
+		feature.Attributes = append(feature.Attributes, gtf.Attribute{Tag: tag, Value: strings.Trim(items2[1], `"`)})
+	}
+	return &feature, nil
+}
+
+// splicedFeature is a transcript (or BED12 feature) whose blocks are joined in a single sequence.
+type splicedFeature struct {
+	ID     string
+	Gene   string
+	Strand string
+	Blocks []splicedBlock
+}
+
+type splicedBlock struct {
+	Start int // 1based
+	End   int // end included
+	Phase int
+}
+
+// gff3SplicedFeatures joins the exons (transcript) or the CDS (cds and protein) of every transcript by
+// their Parent. The transcripts are selected by the type and attributes of the parent feature, or of
+// the children when the parent is not in the file.
+func gff3SplicedFeatures(features []Gff3Feature, extract string, types []string, attributes map[string]string) map[string][]*splicedFeature {
+	childType := "exon"
+	if extract != "transcript" {
+		childType = "cds"
+	}
+	typesMap := make(map[string]struct{}, len(types))
+	for _, t := range types {
+		typesMap[t] = struct{}{}
+	}
+
+	byID := make(map[string]*Gff3Feature)
+	for i := range features {
+		if features[i].ID != "" {
+			byID[features[i].ID] = &features[i]
+		}
+	}
+
+	result := make(map[string][]*splicedFeature)
+	transcripts := make(map[string]*splicedFeature)
+	for i := range features {
+		child := &features[i]
+		if strings.ToLower(child.Type) != childType {
+			continue
+		}
+		parents := child.Parents
+		if len(parents) == 0 {
+			parents = []string{child.ID}
+		}
+		for _, p := range parents {
+			transcript, ok := transcripts[p+"\t"+child.SeqID]
+			if !ok {
+				selector := child
+				gene := p
+				if parent, found := byID[p]; found {
+					selector = parent
+					if len(parent.Parents) > 0 {
+						gene = parent.Parents[0]
+					}
+				}
+				if _, ok := typesMap[strings.ToLower(selector.Type)]; len(typesMap) > 0 && selector != child && !ok {
+					transcripts[p+"\t"+child.SeqID] = nil
+					continue
+				}
+				if !selector.match(attributes) {
+					transcripts[p+"\t"+child.SeqID] = nil
+					continue
+				}
+				transcript = &splicedFeature{ID: p, Gene: gene, Strand: *child.Strand}
+				transcripts[p+"\t"+child.SeqID] = transcript
+				chr := strings.ToLower(child.SeqID)
+				result[chr] = append(result[chr], transcript)
+			}
+			if transcript == nil {
+				continue
+			}
+			phase := child.Phase
+			if phase < 0 {
+				phase = 0
+			}
+			transcript.Blocks = append(transcript.Blocks, splicedBlock{child.Start, child.End, phase})
+		}
+	}
+	for _, transcript := range transcripts {
+		if transcript != nil {
+			sortSplicedBlocks(transcript.Blocks)
+		}
+	}
+	return result
+}
+
+// bedSplicedFeatures joins the blocks of the BED12 features (transcript), clipped to the thick part
+// for cds and protein. BED6 features are a single block.
+func bedSplicedFeatures(features []BedFeature, extract string) map[string][]*splicedFeature {
+	result := make(map[string][]*splicedFeature)
+	for _, feature := range features {
+		spliced := &splicedFeature{Strand: "."}
+		if feature.Name != nil {
+			spliced.ID = *feature.Name
+		} else {
+			spliced.ID = fmt.Sprintf("%s_%d-%d", feature.Chr, feature.Start, feature.End)
+		}
+		spliced.Gene = spliced.ID
+		if feature.Strand != nil {
+			spliced.Strand = *feature.Strand
+		}
+
+		blocks := feature.Blocks
+		if len(blocks) == 0 {
+			blocks = [][2]int{{feature.Start, feature.End}}
+		}
+		for _, block := range blocks {
+			s, e := block[0], block[1]
+			if extract != "transcript" && len(feature.Blocks) > 0 {
+				if feature.ThickStart > s {
+					s = feature.ThickStart
+				}
+				if feature.ThickEnd < e {
+					e = feature.ThickEnd
+				}
+			}
+			if s <= e {
+				spliced.Blocks = append(spliced.Blocks, splicedBlock{s, e, 0})
+			}
+		}
+		if len(spliced.Blocks) == 0 { // non-coding
+			continue
+		}
+		sortSplicedBlocks(spliced.Blocks)
+
+		chr := strings.ToLower(feature.Chr)
+		result[chr] = append(result[chr], spliced)
+	}
+	return result
+}
+
+// sortSplicedBlocks sorts the blocks by position when the features are built, the features are read-only
+// after that because they are shared by the concurrent calls of the executor.
+func sortSplicedBlocks(blocks []splicedBlock) {
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Start < blocks[j].Start })
+}
+
+func subseqSpliced(record *fastx.Record, offset int, lineWidth int, features []*splicedFeature,
+	extract string, groupBy string, translTable int) ([][]byte, error) {
+	type splicedSeq struct {
+		feature *splicedFeature
+		subseq  *seq.Seq
+		s, e    int
+	}
+
+	var genes map[string]int
+	selected := make([]*splicedSeq, 0, len(features))
+	if groupBy == "gene" {
+		genes = make(map[string]int)
+	}
+
+	for _, feature := range features {
+		s, e := feature.Blocks[0].Start, feature.Blocks[len(feature.Blocks)-1].End
+		if s-offset < 1 || e-offset > len(record.Seq.Seq) {
+			log.Warn(fmt.Sprintf("%s: %d-%d out of range of %s", feature.ID, s, e, record.ID))
+			continue
+		}
+
+		seqs := make([][]byte, 0, len(feature.Blocks))
+		quals := make([][]byte, 0, len(feature.Blocks))
+		for _, block := range feature.Blocks {
+			seqs = append(seqs, record.Seq.Seq[block.Start-1-offset:block.End-offset])
+			if len(record.Seq.Qual) > 0 {
+				quals = append(quals, record.Seq.Qual[block.Start-1-offset:block.End-offset])
+			}
+		}
+		subseq := &seq.Seq{Alphabet: record.Seq.Alphabet, Seq: mergeBytes(seqs...), Qual: mergeBytes(quals...)}
+		phase := feature.Blocks[0].Phase
+		if feature.Strand == "-" {
+			subseq.RevComInplace()
+			phase = feature.Blocks[len(feature.Blocks)-1].Phase
+		}
+
+		if extract == "protein" {
+			protein, err := subseq.Translate(translTable, phase+1, true, false, true, false)
+			if err != nil {
+				return nil, err
+			}
+			subseq = protein
+		}
+
+		current := &splicedSeq{feature, subseq, s, e}
+		if genes == nil {
+			selected = append(selected, current)
+		} else if i, ok := genes[feature.Gene]; !ok {
+			genes[feature.Gene] = len(selected)
+			selected = append(selected, current)
+		} else if len(subseq.Seq) > len(selected[i].subseq.Seq) { // longest transcript of the gene
+			selected[i] = current
+		}
+	}
+
+	result := make([][]byte, 0, len(selected))
+	for _, spliced := range selected {
+		var name string
+		if genes == nil {
+			name = fmt.Sprintf("%s %s_%d-%d:%s gene=%s", spliced.feature.ID, record.ID, spliced.s, spliced.e,
+				spliced.feature.Strand, spliced.feature.Gene)
+		} else {
+			name = fmt.Sprintf("%s %s_%d-%d:%s transcript=%s", spliced.feature.Gene, record.ID, spliced.s, spliced.e,
+				spliced.feature.Strand, spliced.feature.ID)
+		}
+		var newRecord *fastx.Record
+		var err error
+		if len(spliced.subseq.Qual) > 0 {
+			newRecord, err = fastx.NewRecordWithQualWithoutValidation(spliced.subseq.Alphabet, []byte(name), []byte(name), []byte{}, spliced.subseq.Seq, spliced.subseq.Qual)
+		} else {
+			newRecord, err = fastx.NewRecordWithoutValidation(spliced.subseq.Alphabet, []byte(name), []byte(name), []byte{}, spliced.subseq.Seq)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, newRecord.Format(lineWidth))
+	}
+	return result, nil
 }
 
 type type2gtfFeatures map[string][]gtf.Feature
@@ -316,15 +900,16 @@ func subseqByRegion(record *fastx.Record, lineWidth int, start, end int) []byte
 	return record.Format(lineWidth)
 }
 
-func subseqByGTFFile(record *fastx.Record, lineWidth int,
+func subseqByGTFFile(record *fastx.Record, offset int, lineWidth int,
 	gtfFeaturesMap map[string]type2gtfFeatures, choosedFeatures []string,
-	onlyFlank bool, upStream int, downStream int, gtfTag string) ([]byte, error) {
+	onlyFlank bool, upStream int, downStream int, gtfTag string) ([][]byte, error) {
 
 	seqname := strings.ToLower(string(record.ID))
 
 	var strand, tag, outname, flankInfo string
 	var s, e int
 	var subseq *seq.Seq
+	result := make([][]byte, 0, 1)
 
 	featsMap := make(map[string]struct{}, len(choosedFeatures))
 	for _, chr := range choosedFeatures {
@@ -355,10 +940,10 @@ func subseqByGTFFile(record *fastx.Record, lineWidth int,
 				if s < 1 {
 					s = 1
 				}
-				if e > len(record.Seq.Seq) {
-					e = len(record.Seq.Seq)
+				if e > offset+len(record.Seq.Seq) {
+					e = offset + len(record.Seq.Seq)
 				}
-				subseq = record.Seq.SubSeq(s, e).RevComInplace()
+				subseq = record.Seq.SubSeq(s-offset, e-offset).RevComInplace()
 			} else {
 				if onlyFlank {
 					if upStream > 0 {
@@ -375,10 +960,10 @@ func subseqByGTFFile(record *fastx.Record, lineWidth int,
 				if s < 1 {
 					s = 1
 				}
-				if e > len(record.Seq.Seq) {
-					e = len(record.Seq.Seq)
+				if e > offset+len(record.Seq.Seq) {
+					e = offset + len(record.Seq.Seq)
 				}
-				subseq = record.Seq.SubSeq(s, e)
+				subseq = record.Seq.SubSeq(s-offset, e-offset)
 			}
 
 			if feature.Strand == nil {
@@ -423,20 +1008,21 @@ func subseqByGTFFile(record *fastx.Record, lineWidth int,
 			if err != nil {
 				return nil, err
 			}
-			return newRecord.Format(lineWidth), nil
+			result = append(result, newRecord.Format(lineWidth))
 		}
 	}
-	return nil, nil
+	return result, nil
 }
 
-func subSeqByBEDFile(record *fastx.Record, lineWidth int,
+func subSeqByBEDFile(record *fastx.Record, offset int, lineWidth int,
 	bedFeatureMap map[string][]BedFeature,
-	onlyFlank bool, upStream, downStream int) ([]byte, error) {
+	onlyFlank bool, upStream, downStream int) ([][]byte, error) {
 	seqname := strings.ToLower(string(record.ID))
 
 	var strand, geneID, outname, flankInfo string
 	var s, e int
 	var subseq *seq.Seq
+	result := make([][]byte, 0, 1)
 	for _, feature := range bedFeatureMap[seqname] {
 		s, e = feature.Start, feature.End
 		if feature.Strand != nil && *feature.Strand == "-" {
@@ -455,10 +1041,10 @@ func subSeqByBEDFile(record *fastx.Record, lineWidth int,
 			if s < 1 {
 				s = 1
 			}
-			if e > len(record.Seq.Seq) {
-				e = len(record.Seq.Seq)
+			if e > offset+len(record.Seq.Seq) {
+				e = offset + len(record.Seq.Seq)
 			}
-			subseq = record.Seq.SubSeq(s, e).RevComInplace()
+			subseq = record.Seq.SubSeq(s-offset, e-offset).RevComInplace()
 		} else {
 			if onlyFlank {
 				if upStream > 0 {
@@ -475,10 +1061,10 @@ func subSeqByBEDFile(record *fastx.Record, lineWidth int,
 			if s < 1 {
 				s = 1
 			}
-			if e > len(record.Seq.Seq) {
-				e = len(record.Seq.Seq)
+			if e > offset+len(record.Seq.Seq) {
+				e = offset + len(record.Seq.Seq)
 			}
-			subseq = record.Seq.SubSeq(s, e)
+			subseq = record.Seq.SubSeq(s-offset, e-offset)
 		}
 
 		if feature.Strand == nil {
@@ -520,7 +1106,362 @@ func subSeqByBEDFile(record *fastx.Record, lineWidth int,
 		if err != nil {
 			return nil, err
 		}
-		return newRecord.Format(lineWidth), nil
+		result = append(result, newRecord.Format(lineWidth))
 	}
-	return nil, nil
+	return result, nil
+}
+
+// subseqBinSize is the size of the bins of a chromosome in SubseqJoin, the features are grouped by the bin
+// where they start so the features of a big chromosome are spread over several groups.
+const subseqBinSize = 1000000
+
+func subseqBinKey(chr string, bin int) string {
+	return chr + "\t" + strconv.Itoa(bin)
+}
+
+// subseqLineSpan returns the 1-based and end included coordinates of an annotation line.
+func subseqLineSpan(line string, format string) (int, int, error) {
+	items := strings.SplitN(line, "\t", 6)
+	col := 3
+	if format == "bed" {
+		col = 1
+	}
+	if len(items) < col+2 {
+		return 0, 0, fmt.Errorf("bad %s line: %s", format, line)
+	}
+	start, err := strconv.Atoi(items[col])
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad %s line: %s", format, line)
+	}
+	end, err := strconv.Atoi(items[col+1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad %s line: %s", format, line)
+	}
+	if format == "bed" {
+		start++
+	}
+	return start, end, nil
+}
+
+func NewSubseqKeyRecords() any {
+	return &SubseqKeyRecords{}
+}
+
+type SubseqKeyRecords struct {
+	base.IMapPartitions[string, ipair.IPair[string, string]]
+	function.IAfterNone
+	opts     bigseqkit.SubseqOptions
+	alphabet *seq.Alphabet
+	reach    map[string]int64
+}
+
+func (this *SubseqKeyRecords) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.SubseqOptions](context.Vars()["opts"].(string))
+	this.reach = bigseqkit.StringToOptions[map[string]int64](context.Vars()["reach"].(string))
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+	return err
+}
+
+// Call keys the slices of the records by their chromosome (ID in lower case) and bin, tagged with "0" and
+// prefixed by the offset of the slice. The slice of a bin starts at the bin and ends at the reach of the
+// features of the bin, bins without features are dropped.
+func (this *SubseqKeyRecords) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]ipair.IPair[string, string], error) {
+	result := make([]ipair.IPair[string, string], 0, 100)
+
+	fastxReader, err := NewSeqParser(this.alphabet, v1, *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if fastxReader.IsFastq {
+			*this.opts.Config.LineWidth = 0
+			fastx.ForcelyOutputFastq = true
+		}
+
+		chr := strings.ToLower(string(record.ID))
+		for offset := 0; offset < len(record.Seq.Seq); offset += subseqBinSize {
+			key := subseqBinKey(chr, offset/subseqBinSize)
+			reach, ok := this.reach[key]
+			if !ok {
+				continue
+			}
+			end := len(record.Seq.Seq)
+			if int(reach) < end {
+				end = int(reach)
+			}
+			slice := &fastx.Record{ID: record.ID, Name: record.Name, Desc: record.Desc,
+				Seq: &seq.Seq{Alphabet: record.Seq.Alphabet, Seq: record.Seq.Seq[offset:end]}}
+			if len(record.Seq.Qual) > 0 {
+				slice.Seq.Qual = record.Seq.Qual[offset:end]
+			}
+			result = append(result, *ipair.New(key, "0"+strconv.Itoa(offset)+"\t"+string(slice.Format(0))))
+		}
+	}
+
+	return result, nil
+}
+
+func NewSubseqKeyFeatures() any {
+	return &SubseqKeyFeatures{}
+}
+
+type SubseqKeyFeatures struct {
+	base.IMapPartitions[string, ipair.IPair[string, string]]
+	function.IAfterNone
+	opts    bigseqkit.SubseqOptions
+	format  string
+	chrsMap map[string]struct{}
+	flank   int
+}
+
+func (this *SubseqKeyFeatures) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.SubseqOptions](context.Vars()["opts"].(string))
+	this.format = context.Vars()["format"].(string)
+	this.chrsMap = make(map[string]struct{}, len(*this.opts.Chr))
+	for _, chr := range *this.opts.Chr {
+		this.chrsMap[strings.ToLower(chr)] = struct{}{}
+	}
+	this.flank = *this.opts.UpStream
+	if *this.opts.DownStream > this.flank {
+		this.flank = *this.opts.DownStream
+	}
+	return nil
+}
+
+// Call keys the annotation lines by their chromosome in lower case and the bin where the feature (with
+// its flanks) starts, tagged with "1". The transcripts of GFF3 files are joined from several lines, so
+// with Extract all the lines of a chromosome are keyed to the first bin. Comments, headers, the FASTA
+// section of GFF3 files and the lines of not selected chrs are dropped.
+func (this *SubseqKeyFeatures) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]ipair.IPair[string, string], error) {
+	result := make([]ipair.IPair[string, string], 0, 100)
+
+	for v1.HasNext() {
+		line, err := v1.Next()
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" || line[0] == '#' || strings.HasPrefix(line, "browser") || strings.HasPrefix(line, "track") {
+			continue
+		}
+		i := strings.IndexByte(line, '\t')
+		if i < 1 { // FASTA section of GFF3 files
+			if this.format == "gff" {
+				continue
+			}
+			return nil, fmt.Errorf("bad %s line: %s", this.format, line)
+		}
+		chr := strings.ToLower(line[:i])
+		if _, ok := this.chrsMap[chr]; len(this.chrsMap) > 0 && !ok {
+			continue
+		}
+		start, _, err := subseqLineSpan(line, this.format)
+		if err != nil {
+			return nil, err
+		}
+		bin := 0
+		if start -= this.flank; start > 1 && (this.format != "gff" || *this.opts.Extract == "") {
+			bin = (start - 1) / subseqBinSize
+		}
+		result = append(result, *ipair.New(subseqBinKey(chr, bin), "1"+line))
+	}
+
+	return result, nil
+}
+
+func NewSubseqReach() any {
+	return &SubseqReach{}
+}
+
+type SubseqReach struct {
+	base.IMapPartitions[ipair.IPair[string, string], map[string]int64]
+	function.IAfterNone
+	opts   bigseqkit.SubseqOptions
+	format string
+	flank  int
+}
+
+func (this *SubseqReach) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.SubseqOptions](context.Vars()["opts"].(string))
+	this.format = context.Vars()["format"].(string)
+	this.flank = *this.opts.UpStream
+	if *this.opts.DownStream > this.flank {
+		this.flank = *this.opts.DownStream
+	}
+	return nil
+}
+
+// Call returns the reach of every bin keyed by SubseqKeyFeatures, the last position (flanks included)
+// of its features.
+func (this *SubseqReach) Call(v1 iterator.IReadIterator[ipair.IPair[string, string]], context api.IContext) ([]map[string]int64, error) {
+	reach := make(map[string]int64)
+	for v1.HasNext() {
+		v, err := v1.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, end, err := subseqLineSpan(v.Second[1:], this.format)
+		if err != nil {
+			return nil, err
+		}
+		if e := int64(end + this.flank); e > reach[v.First] {
+			reach[v.First] = e
+		}
+	}
+	return []map[string]int64{reach}, nil
+}
+
+func NewSubseqReachReduce() any {
+	return &SubseqReachReduce{}
+}
+
+type SubseqReachReduce struct {
+	base.IReduce[map[string]int64]
+	function.IOnlyCall
+}
+
+func (this *SubseqReachReduce) Call(v1 map[string]int64, v2 map[string]int64, context api.IContext) (map[string]int64, error) {
+	for k, v := range v2 {
+		if v > v1[k] {
+			v1[k] = v
+		}
+	}
+	return v1, nil
+}
+
+func NewSubseqJoin() any {
+	return &SubseqJoin{}
+}
+
+type SubseqJoin struct {
+	base.IFlatmap[ipair.IPair[string, []string], string]
+	function.IAfterNone
+	opts       bigseqkit.SubseqOptions
+	alphabet   *seq.Alphabet
+	format     string
+	attributes map[string]string
+	featsMap   map[string]struct{}
+}
+
+func (this *SubseqJoin) Before(context api.IContext) (err error) {
+	this.opts = bigseqkit.StringToOptions[bigseqkit.SubseqOptions](context.Vars()["opts"].(string))
+	this.format = context.Vars()["format"].(string)
+	this.alphabet, err = this.opts.Config.GetAlphabet()
+	if err != nil {
+		return err
+	}
+	seq.AlphabetGuessSeqLengthThreshold = *this.opts.Config.AlphabetGuessSeqLength
+	seq.ValidateSeq = false
+
+	this.attributes, err = subseqCheckOptions(&this.opts)
+	if err != nil {
+		return err
+	}
+	this.featsMap = make(map[string]struct{}, len(*this.opts.Feature))
+	for _, f := range *this.opts.Feature {
+		this.featsMap[f] = struct{}{}
+	}
+	return nil
+}
+
+// Call extracts the features of a bin from the slices of the records, the annotation lines are grouped with them.
+func (this *SubseqJoin) Call(v ipair.IPair[string, []string], context api.IContext) ([]string, error) {
+	records := make([]string, 0, 1)
+	offsets := make([]int, 0, 1)
+	lines := make([]string, 0, len(v.Second))
+	for _, e := range v.Second {
+		if e[0] == '0' {
+			i := strings.IndexByte(e, '\t')
+			offset, err := strconv.Atoi(e[1:i])
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, e[i+1:])
+			offsets = append(offsets, offset)
+		} else {
+			lines = append(lines, e[1:])
+		}
+	}
+	if len(records) == 0 || len(lines) == 0 {
+		return nil, nil
+	}
+
+	var features subseqFeatures
+	switch this.format {
+	case "gtf":
+		gtfFeatures := make([]gtf.Feature, 0, len(lines))
+		for _, line := range lines {
+			feature, err := parseGtfLine(line, nil, this.featsMap, *this.opts.GtfTag)
+			if err != nil {
+				return nil, err
+			}
+			if feature != nil {
+				gtfFeatures = append(gtfFeatures, *feature)
+			}
+		}
+		features.loadGtf(gtfFeatures)
+	case "gff":
+		gff3Features := make([]Gff3Feature, 0, len(lines))
+		for _, line := range lines {
+			feature, err := parseGff3Line(line, nil)
+			if err != nil {
+				return nil, err
+			}
+			if feature != nil {
+				gff3Features = append(gff3Features, *feature)
+			}
+		}
+		features.loadGff3(gff3Features, &this.opts, this.attributes)
+	default:
+		bedFeatures := make([]BedFeature, 0, len(lines))
+		for _, line := range lines {
+			feature, err := parseBedLine(line, nil)
+			if err != nil {
+				return nil, err
+			}
+			if feature != nil {
+				bedFeatures = append(bedFeatures, *feature)
+			}
+		}
+		features.loadBed(bedFeatures, &this.opts)
+	}
+
+	fastxReader, err := NewSeqParser(this.alphabet, NewArrayIterator(records), *this.opts.Config.IDRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(lines))
+	for i := 0; ; i++ {
+		record, err := fastxReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if fastxReader.IsFastq {
+			*this.opts.Config.LineWidth = 0
+			fastx.ForcelyOutputFastq = true
+		}
+
+		r, err := features.subseq(record, offsets[i], &this.opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, bb := range r {
+			result = append(result, string(bb))
+		}
+	}
+	return result, nil
 }
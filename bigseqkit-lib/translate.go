@@ -13,6 +13,8 @@ import (
 	"ignis/executor/api/iterator"
 	log "ignis/executor/core/logger"
 	"io"
+	"os"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,10 +30,16 @@ type Translate struct {
 	opts     bigseqkit.TranslateOptions
 	alphabet *seq.Alphabet
 	frames   []int
+	tables   map[int]*seq.CodonTable
+	reTable  *regexp.Regexp
+	idTables map[string]string
 }
 
 func (this *Translate) Before(context api.IContext) (err error) {
 	this.opts = bigseqkit.StringToOptions[bigseqkit.TranslateOptions](context.Vars()["opts"].(string))
+	if err = this.opts.Validate(); err != nil {
+		return err
+	}
 	this.alphabet, err = this.opts.Config.GetAlphabet()
 	if err != nil {
 		return err
@@ -40,27 +48,151 @@ func (this *Translate) Before(context api.IContext) (err error) {
 	seq.ValidSeqThreads = 1
 	seq.ComplementThreads = 1
 
-	if _, ok := seq.CodonTables[*this.opts.TranslTable]; !ok {
+	this.tables = make(map[int]*seq.CodonTable, len(seq.CodonTables))
+	for id, table := range seq.CodonTables {
+		this.tables[id] = table
+	}
+	if *this.opts.CodonTableFile != "" {
+		tables, err := readCodonTables(*this.opts.CodonTableFile)
+		if err != nil {
+			return fmt.Errorf("read codon table file: %s", err)
+		}
+		for _, table := range tables {
+			this.tables[table.ID] = table
+		}
+		if !*this.opts.Config.Quiet {
+			log.Info(fmt.Sprintf("%d codon tables loaded from %s", len(tables), *this.opts.CodonTableFile))
+		}
+	}
+	if _, ok := this.tables[*this.opts.TranslTable]; !ok {
 		return fmt.Errorf("invalid translate table: %d", *this.opts.TranslTable)
 	}
-	_frames := *this.opts.Frame
-	this.frames = make([]int, 0, len(_frames))
+
+	switch *this.opts.TranslTableFrom {
+	case "":
+	case "header":
+		this.reTable = regexp.MustCompile(*this.opts.TranslTableRegexp)
+	default:
+		if this.idTables, err = readKVs(*this.opts.TranslTableFrom, false); err != nil {
+			return fmt.Errorf("read translate table file: %s", err)
+		}
+		for id, table := range this.idTables {
+			if _, err = this.recordTable(table); err != nil {
+				return fmt.Errorf("%s (%s)", err, id)
+			}
+		}
+	}
+	this.frames, err = parseTranslateFrames(*this.opts.Frame)
+	return err
+}
+
+// recordTable returns the codon table of a table ID, the default table is used if the ID is empty.
+func (this *Translate) recordTable(id string) (*seq.CodonTable, error) {
+	if id == "" {
+		return this.tables[*this.opts.TranslTable], nil
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid translate table: %s", id)
+	}
+	table, ok := this.tables[n]
+	if !ok {
+		return nil, fmt.Errorf("invalid translate table: %d", n)
+	}
+	return table, nil
+}
+
+// readCodonTables reads codon tables in the NCBI format. Every table starts with a line with its ID and name,
+// e.g., "100 My Code", followed by the lines AAs, Starts, Base1, Base2 and Base3. Empty lines and lines
+// starting with '#' are ignored. Codons with ambiguous bases are translated to 'X'.
+func readCodonTables(file string) ([]*seq.CodonTable, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]*seq.CodonTable, 0, 1)
+	var table *seq.CodonTable
+	fields := make(map[string]string, 5)
+	build := func() error {
+		if table == nil {
+			return nil
+		}
+		for _, key := range []string{"AAs", "Starts", "Base1", "Base2", "Base3"} {
+			if len(fields[key]) != 64 {
+				return fmt.Errorf("table %d: %s must have 64 characters", table.ID, key)
+			}
+		}
+		for i := 0; i < 64; i++ {
+			codon := []byte{fields["Base1"][i], fields["Base2"][i], fields["Base3"][i]}
+			if err := table.Set(codon, fields["AAs"][i]); err != nil {
+				return fmt.Errorf("table %d: %s", table.ID, err)
+			}
+			if fields["Starts"][i] == 'M' {
+				table.InitCodons[strings.ToUpper(string(codon))] = struct{}{}
+			} else if fields["AAs"][i] == '*' {
+				table.StopCodons[strings.ToUpper(string(codon))] = struct{}{}
+			}
+		}
+		tables = append(tables, table)
+		for key := range fields {
+			delete(fields, key)
+		}
+		return nil
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if i := strings.IndexByte(line, '='); i > 0 {
+			key := strings.TrimSpace(line[:i])
+			switch key {
+			case "AAs", "Starts", "Base1", "Base2", "Base3":
+				if table == nil {
+					return nil, fmt.Errorf("codon table ID and name line needed before: %s", line)
+				}
+				fields[key] = strings.TrimSpace(line[i+1:])
+				continue
+			}
+		}
+		if err = build(); err != nil {
+			return nil, err
+		}
+		items := strings.SplitN(line, " ", 2)
+		id, err := strconv.Atoi(strings.TrimRight(items[0], ".:"))
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("invalid codon table ID and name line: %s", line)
+		}
+		name := ""
+		if len(items) > 1 {
+			name = strings.TrimSpace(items[1])
+		}
+		table = seq.NewCodonTable(id, name)
+	}
+	if err = build(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// parseTranslateFrames parses the frames to translate, 6 selects all six frames.
+func parseTranslateFrames(_frames []string) ([]int, error) {
+	frames := make([]int, 0, len(_frames))
 	for _, _frame := range _frames {
 		frame, err := strconv.Atoi(_frame)
 		if err != nil {
-			return fmt.Errorf("invalid frame(s): %s. available: 1, 2, 3, -1, -2, -3, and 6 for all. multiple frames should be separated by comma", _frame)
+			return nil, fmt.Errorf("invalid frame(s): %s. available: 1, 2, 3, -1, -2, -3, and 6 for all. multiple frames should be separated by comma", _frame)
 		}
 		if !(frame == 1 || frame == 2 || frame == 3 || frame == -1 || frame == -2 || frame == -3 || frame == 6) {
-			return fmt.Errorf("invalid frame: %d. available: 1, 2, 3, -1, -2, -3, and 6 for all", frame)
+			return nil, fmt.Errorf("invalid frame: %d. available: 1, 2, 3, -1, -2, -3, and 6 for all", frame)
 		}
 		if frame == 6 {
-			this.frames = []int{1, 2, 3, -1, -2, -3}
-			break
+			return []int{1, 2, 3, -1, -2, -3}, nil
 		}
-		this.frames = append(this.frames, frame)
+		frames = append(frames, frame)
 	}
-
-	return nil
+	return frames, nil
 }
 
 func (this *Translate) Call(v1 iterator.IReadIterator[string], context api.IContext) ([]string, error) {
@@ -76,24 +208,24 @@ func (this *Translate) Call(v1 iterator.IReadIterator[string], context api.ICont
 	listTable := *this.opts.ListTranslTable
 
 	if listTableAmb == 0 || listTable == 0 {
-		ks := make([]int, len(seq.CodonTables))
+		ks := make([]int, len(this.tables))
 		i := 0
-		for k := range seq.CodonTables {
+		for k := range this.tables {
 			ks[i] = k
 			i++
 		}
 		sort.Ints(ks)
 		for _, i = range ks {
-			result = append(result, fmt.Sprintf("%d\t%s", seq.CodonTables[i].ID, seq.CodonTables[i].Name))
+			result = append(result, fmt.Sprintf("%d\t%s", this.tables[i].ID, this.tables[i].Name))
 		}
 		return result, nil
 	} else if listTableAmb > 0 {
-		if table, ok := seq.CodonTables[listTableAmb]; ok {
+		if table, ok := this.tables[listTableAmb]; ok {
 			return strings.Split(table.StringWithAmbiguousCodons(), "\n"), nil
 		}
 		return []string{}, nil
 	} else if listTable > 0 {
-		if table, ok := seq.CodonTables[listTable]; ok {
+		if table, ok := this.tables[listTable]; ok {
 			return strings.Split(table.String(), "\n"), nil
 		}
 		return []string{}, nil
@@ -121,14 +253,30 @@ func (this *Translate) Call(v1 iterator.IReadIterator[string], context api.ICont
 			once = false
 		}
 
+		tableID := ""
+		if this.reTable != nil {
+			if found := this.reTable.FindSubmatch(record.Name); found != nil {
+				tableID = string(found[1])
+			}
+		} else if this.idTables != nil {
+			tableID = this.idTables[string(record.ID)]
+		}
+		table, err := this.recordTable(tableID)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s)", err, record.ID)
+		}
+
 		for _, frame = range this.frames {
-			_seq, err = record.Seq.Translate(*this.opts.TranslTable, frame, *this.opts.Trim, *this.opts.Clean, *this.opts.AllowUnknownCodon, *this.opts.InitCodonAsM)
+			aa, err := table.Translate(record.Seq.Seq, frame, *this.opts.Trim, *this.opts.Clean, *this.opts.AllowUnknownCodon, *this.opts.InitCodonAsM)
 			if err != nil {
 				if err == seq.ErrUnknownCodon {
 					log.Error("unknown codon detected, you can use flag -x/--allow-unknown-codon to translate it to 'X'.")
 				}
 				return nil, err
 			}
+			if _seq, err = seq.NewSeqWithoutValidation(seq.Protein, aa); err != nil {
+				return nil, err
+			}
 
 			if *this.opts.AppendFrame {
 				outfh.WriteString(fmt.Sprintf(">%s_frame=%d %s\n", record.ID, frame, record.Desc))
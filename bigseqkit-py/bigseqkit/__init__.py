@@ -1,6 +1,12 @@
-from bigseqkit.helper import SeqKitConfig, readFASTA, readFASTQ, StoreFASTX, StoreFASTXN
+from bigseqkit.helper import SeqKitConfig, OptionError, readFASTA, readFASTQ, readAnnotation, StoreFASTX, StoreFASTXN
+from bigseqkit.amplicon import SeqKitAmpliconOptions, amplicon
+from bigseqkit.cardinality import SeqKitCardinalityOptions, cardinality
+from bigseqkit.codon_usage import SeqKitCodonUsageOptions, codonUsage, codonUsagePerRecord
 from bigseqkit.common import SeqKitCommonOptions, common
-from bigseqkit.concat import SeqKitConcatOptions, concat
+from bigseqkit.concat import SeqKitConcatOptions, concat, concatN, concatPartitions
+from bigseqkit.consensus import SeqKitConsensusOptions, consensus
+from bigseqkit.digest import SeqKitDigestOptions, digest, digestHistogram
+from bigseqkit.export import toPandas, toArrow, iterPandas, iterArrow, to_pandas, to_arrow
 from bigseqkit.duplicate import SeqKitDuplicateOptions, duplicate
 from bigseqkit.fa2fq import SeqKitFa2FqOptions, fa2fq
 from bigseqkit.faidx import SeqKitFaidxOptions, faidx
@@ -9,13 +15,20 @@ from bigseqkit.grep import SeqKitGrepOptions, grep
 from bigseqkit.head import SeqKitHeadOptions, head
 from bigseqkit.head_genome import SeqKitHeadGenomeOptions, headGenome
 from bigseqkit.locate import SeqKitLocateOptions, locate
+from bigseqkit.mask import SeqKitMaskOptions, mask
+from bigseqkit.orfs import SeqKitOrfsOptions, orfs
+from bigseqkit.pair import SeqKitPairOptions, PairResult, pair, pairIndex, unpairedId
+from bigseqkit.protein_stats import SeqKitProteinStatsOptions, proteinStats, proteinStatsPerRecord
 from bigseqkit.range import SeqKitRangeOptions, range
 from bigseqkit.rename import SeqKitRenameOptions, rename
 from bigseqkit.replace import SeqKitReplaceOptions, replace
-from bigseqkit.rmdup import SeqKitRmDupOptions, rmDup
+from bigseqkit.rmdup import SeqKitRmDupOptions, rmDup, dupReport, rmDupOptical, rmDupOpticalPairs
 from bigseqkit.sample import SeqKitSampleOptions, sample
 from bigseqkit.seq import SeqKitSeqOptions, seq
+from bigseqkit.sliding import SeqKitSlidingOptions, sliding
 from bigseqkit.sort import SeqKitSortOptions, sort
-from bigseqkit.subseq import SeqKitSubseqOptions, subSeq
-from bigseqkit.suffle import SeqKitShuffleOptions, suffle
+from bigseqkit.stats import SeqKitStatsOptions, StatInfo, stats, statsString
+from bigseqkit.subseq import SeqKitSubseqOptions, subSeq, subSeqJoin
+from bigseqkit.shuffle import SeqKitShuffleOptions, shuffle
+from bigseqkit.suffle import suffle
 from bigseqkit.translate import SeqKitTranslateOptions, translate
@@ -0,0 +1,103 @@
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+
+
+class SeqKitAmpliconOptions:
+
+    def __init__(self):
+        self.__inner = AmpliconOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def forward(self, v: str):
+        self.__inner.Forward = v
+
+    def reverse(self, v: str):
+        self.__inner.Reverse = v
+
+    def primerFile(self, v: str):
+        self.__inner.PrimerFile = v
+
+    def maxMismatch(self, v: int):
+        self.__inner.MaxMismatch = v
+
+    def onlyPositiveStrand(self, v: bool):
+        self.__inner.OnlyPositiveStrand = v
+
+    def onlyInner(self, v: bool):
+        self.__inner.OnlyInner = v
+
+    def bed(self, v: bool):
+        self.__inner.Bed = v
+
+    def minLen(self, v: int):
+        self.__inner.MinLen = v
+
+    def maxLen(self, v: int):
+        self.__inner.MaxLen = v
+
+    def circular(self, v: bool):
+        self.__inner.Circular = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _run(self, input: IDataFrame, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+
+        libprepare = _libSource("Amplicon").addParam("opts", _optionsToString(opts))
+        return input.mapPartitions(libprepare)
+
+
+class AmpliconOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.Forward = None  # str
+        self.Reverse = None  # str
+        self.PrimerFile = None  # str
+        self.MaxMismatch = None  # int
+        self.OnlyPositiveStrand = None  # bool
+        self.OnlyInner = None  # bool
+        self.Bed = None  # bool
+        self.MinLen = None  # int
+        self.MaxLen = None  # int
+        self.Circular = None  # bool
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "Forward", "")
+        _setDefault(self, "Reverse", "")
+        _setDefault(self, "PrimerFile", "")
+        _setDefault(self, "MaxMismatch", 0)
+        _setDefault(self, "OnlyPositiveStrand", False)
+        _setDefault(self, "OnlyInner", False)
+        _setDefault(self, "Bed", False)
+        _setDefault(self, "MinLen", 0)
+        _setDefault(self, "MaxLen", 0)
+        _setDefault(self, "Circular", False)
+
+    def validate(self):
+        self.Config.validate()
+        if self.PrimerFile == "" and (self.Forward == "" or self.Reverse == ""):
+            raise OptionError("flags -F (--forward) and -R (--reverse), or -p (--primer-file) needed")
+        if self.MaxMismatch < 0:
+            raise OptionError("value of flag -m (--max-mismatch) should not be negative", "MaxMismatch")
+        if self.MaxMismatch > 0:
+            for field, primer in (("Forward", self.Forward), ("Reverse", self.Reverse)):
+                if any(c not in "ACGTU" for c in primer.upper()):
+                    raise OptionError("degenerate primer not allowed when giving flag -m (--max-mismatch): " + primer,
+                                      field)
+        if self.MinLen < 0 or self.MaxLen < 0:
+            raise OptionError("values of flags --min-len and --max-len should not be negative", "MinLen")
+        if 0 < self.MaxLen < self.MinLen:
+            raise OptionError("value of flag --max-len should not be lower than --min-len", "MaxLen")
+
+
+def amplicon(input: IDataFrame, o: SeqKitAmpliconOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitAmpliconOptions()
+    return o._run(input, **kwargs)
@@ -0,0 +1,102 @@
+import math
+
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+
+
+class SeqKitCardinalityOptions:
+
+    def __init__(self):
+        self.__inner = CardinalityOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def byName(self, v: bool):
+        self.__inner.ByName = v
+
+    def bySeq(self, v: bool):
+        self.__inner.BySeq = v
+
+    def ignoreCase(self, v: bool):
+        self.__inner.IgnoreCase = v
+
+    def onlyPositiveStrand(self, v: bool):
+        self.__inner.OnlyPositiveStrand = v
+
+    def precision(self, v: int):
+        self.__inner.Precision = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _run(self, input: IDataFrame, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+
+        libSketch = _libSource("CardinalitySketch").addParam("opts", _optionsToString(opts))
+        sketch = input.mapPartitions(libSketch).reduce(_libSource("CardinalityMerge"))
+
+        m = 1 << opts.Precision
+        return CardinalityInfo(sketch[0], _hllEstimate(sketch[1:]), 1.04 / math.sqrt(m))
+
+
+class CardinalityOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.ByName = None  # bool
+        self.BySeq = None  # bool
+        self.IgnoreCase = None  # bool
+        self.OnlyPositiveStrand = None  # bool
+        self.Precision = None  # int
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "ByName", False)
+        _setDefault(self, "BySeq", False)
+        _setDefault(self, "IgnoreCase", False)
+        _setDefault(self, "OnlyPositiveStrand", False)
+        _setDefault(self, "Precision", 14)
+
+    def validate(self):
+        self.Config.validate()
+        if self.BySeq and self.ByName:
+            raise OptionError("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
+        if self.OnlyPositiveStrand and not self.BySeq:
+            raise OptionError("flag -s (--by-seq) needed when using -P (--only-positive-strand)", "OnlyPositiveStrand")
+        if self.Precision < 4 or self.Precision > 18:
+            raise OptionError("value of flag -p (--precision) should be in range [4, 18]", "Precision")
+
+
+class CardinalityInfo:
+
+    def __init__(self, records, distinct, stdError):
+        self.records = records
+        self.distinct = distinct
+        self.stdError = stdError
+
+    def duplicationRate(self):
+        if self.records == 0 or self.distinct >= self.records:
+            return 0.0
+        return 1 - self.distinct / self.records
+
+    def errorBound(self):
+        return math.ceil(2 * self.stdError * self.distinct)
+
+
+def _hllEstimate(registers):
+    m = len(registers)
+    alpha = {16: 0.673, 32: 0.697, 64: 0.709}.get(m, 0.7213 / (1 + 1.079 / m))
+    estimate = alpha * m * m / sum(2.0 ** -r for r in registers)
+    zeros = registers.count(0)
+    if estimate <= 2.5 * m and zeros > 0:
+        estimate = m * math.log(m / zeros)
+    return round(estimate)
+
+
+def cardinality(input: IDataFrame, o: SeqKitCardinalityOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitCardinalityOptions()
+    return o._run(input, **kwargs)
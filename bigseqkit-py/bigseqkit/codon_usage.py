@@ -0,0 +1,176 @@
+import math
+
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+
+# amino acids of the codons of the NCBI translate tables, in the TCAG order of CodonIndex
+_CODON_TABLES = {
+    1: "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    2: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSS**VVVVAAAADDEEGGGG",
+    3: "FFLLSSSSYY**CCWWTTTTPPPPHHQQRRRRIIMMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    4: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    5: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSSSVVVVAAAADDEEGGGG",
+    6: "FFLLSSSSYYQQCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    9: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+    10: "FFLLSSSSYY**CCCWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    11: "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    12: "FFLLSSSSYY**CC*WLLLSPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    13: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSGGVVVVAAAADDEEGGGG",
+    14: "FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+    16: "FFLLSSSSYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    21: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+    22: "FFLLSS*SYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    23: "FF*LSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    24: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+    25: "FFLLSSSSYY**CCGWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    26: "FFLLSSSSYY**CC*WLLLAPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    27: "FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    28: "FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    29: "FFLLSSSSYYYYCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    30: "FFLLSSSSYYEECC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+    31: "FFLLSSSSYYEECCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+}
+
+
+_CODON_BASES = "TCAG"
+
+
+class SeqKitCodonUsageOptions:
+
+    def __init__(self):
+        self.__inner = CodonUsageOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def translTable(self, v: int):
+        self.__inner.TranslTable = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _opts(self, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+        return opts
+
+    def _run(self, input: IDataFrame, **kwargs):
+        opts = self._opts(**kwargs)
+        libCount = _libSource("CodonUsage").addParam("opts", _optionsToString(opts))
+        total = input.mapPartitions(libCount).reduce(_libSource("MapSumReduce"))
+        return CodonUsageInfo(total, opts.TranslTable)
+
+    def _perRecord(self, input: IDataFrame, **kwargs):
+        opts = self._opts(**kwargs)
+        libRecords = _libSource("CodonUsageRecords").addParam("opts", _optionsToString(opts))
+        return input.mapPartitions(libRecords)
+
+
+class CodonUsageOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.TranslTable = None  # int
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "TranslTable", 1)
+
+    def validate(self):
+        self.Config.validate()
+        if self.TranslTable not in _CODON_TABLES:
+            raise OptionError("invalid translate table: " + str(self.TranslTable), "TranslTable")
+
+
+def codonString(idx):
+    return _CODON_BASES[idx // 16] + _CODON_BASES[idx // 4 % 4] + _CODON_BASES[idx % 4]
+
+
+class CodonUsageInfo:
+
+    def __init__(self, counts, translTable):
+        self.records = counts.get(-1, 0)
+        self.ambiguous = counts.get(-2, 0)
+        self.incomplete = counts.get(-3, 0)
+        self.counts = [counts.get(i, 0) for i in range(64)]
+        self.translTable = translTable
+        self.__aminoAcids = _CODON_TABLES[translTable]
+
+    def aminoAcid(self, idx):
+        return self.__aminoAcids[idx]
+
+    def codons(self):
+        return sum(c for i, c in enumerate(self.counts) if self.__aminoAcids[i] != "*")
+
+    def __synonymous(self):
+        groups = dict()
+        for i, aa in enumerate(self.__aminoAcids):
+            if aa != "*":
+                groups.setdefault(aa, []).append(i)
+        return groups
+
+    def rscu(self):
+        rscu = [0.0] * 64
+        for codons in self.__synonymous().values():
+            total = sum(self.counts[i] for i in codons)
+            if total == 0:
+                continue
+            for i in codons:
+                rscu[i] = self.counts[i] * len(codons) / total
+        return rscu
+
+    def enc(self):
+        classes = dict()  # degeneracy -> [amino acids, sum of F, amino acids with F]
+        senseCodons = 0
+        fixed = 0
+        for codons in self.__synonymous().values():
+            k = len(codons)
+            senseCodons += k
+            if k == 1:
+                fixed += 1
+                continue
+            c = classes.setdefault(k, [0, 0.0, 0])
+            c[0] += 1
+            total = sum(self.counts[i] for i in codons)
+            if total < 2:
+                continue
+            s = sum((self.counts[i] / total) ** 2 for i in codons)
+            c[1] += (total * s - 1) / (total - 1)
+            c[2] += 1
+
+        known = [c[1] / c[2] for c in classes.values() if c[2] > 0 and c[1] > 0]
+        if len(known) == 0:
+            return math.nan
+        meanF = sum(known) / len(known)
+
+        enc = float(fixed)
+        for c in classes.values():
+            f = c[1] / c[2] if c[2] > 0 and c[1] > 0 else meanF
+            enc += c[0] / f
+        return min(enc, float(senseCodons))
+
+    def gc3(self):
+        gc, n = 0, 0
+        for i, c in enumerate(self.counts):
+            if self.__aminoAcids[i] == "*":
+                continue
+            n += c
+            if i % 4 == 1 or i % 4 == 3:
+                gc += c
+        return gc / n if n > 0 else 0.0
+
+
+def codonUsage(input: IDataFrame, o: SeqKitCodonUsageOptions = None, **kwargs):
+    """Codon usage of all the records, cache the input to also call codonUsagePerRecord"""
+    if o is None:
+        o = SeqKitCodonUsageOptions()
+    return o._run(input, **kwargs)
+
+
+def codonUsagePerRecord(input: IDataFrame, o: SeqKitCodonUsageOptions = None, **kwargs):
+    """A line per record: ID, sense codons, GC3, ENC, codon counts and RSCU, separated by tabs. The counts and
+    the RSCU of the 64 codons are separated by commas, in the order of codonString"""
+    if o is None:
+        o = SeqKitCodonUsageOptions()
+    return o._perRecord(input, **kwargs)
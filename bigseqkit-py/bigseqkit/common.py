@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitCommonOptions:
@@ -21,6 +21,9 @@ class SeqKitCommonOptions:
     def onlyPositiveStrand(self, v: bool):
         self.__inner.OnlyPositiveStrand = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _prepareCommon(self, inputA: IDataFrame, id: str) -> IDataFrame:
         libprepare = _libSource("SeqTransform")\
             .addParam("opts", _optionsToString(self.__inner))\
@@ -31,6 +34,7 @@ class SeqKitCommonOptions:
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
 
         u = self._prepareCommon(inputA, "1")
 
@@ -67,6 +71,13 @@ class CommonOptions:
 
         return self
 
+    def validate(self):
+        self.Config.validate()
+        if self.BySeq and self.ByName:
+            raise OptionError("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
+        if self.OnlyPositiveStrand and not self.BySeq:
+            raise OptionError("flag -s (--by-seq) needed when using -P (--only-positive-strand)", "OnlyPositiveStrand")
+
 
 def common(inputA: IDataFrame, inputB: IDataFrame, *args: IDataFrame, o: SeqKitCommonOptions = None, **kwargs):
     if o is None:
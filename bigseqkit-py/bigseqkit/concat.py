@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitConcatOptions:
@@ -15,39 +15,98 @@ class SeqKitConcatOptions:
     def separator(self, v: str):
         self.__inner.Separator = v
 
+    def fill(self, v: str):
+        self.__inner.Fill = v
+
+    def partitionType(self, v: str):
+        self.__inner.PartitionType = v
+
+    def validate(self):
+        _validate(self.__inner)
+
     def _prepareConcat(self, inputA: IDataFrame, id: str) -> IDataFrame:
         libprepare = _libSource("ConcatPrepare")\
             .addParam("opts", _optionsToString(self.__inner))\
             .addParam("id", id)
         return inputA.mapPartitions(libprepare)
-    def _run(self, inputA: IDataFrame, inputB: IDataFrame, **kwargs):
+    def _run(self, inputs, withLengths=False, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
+
+        if len(inputs) < 2:
+            raise RuntimeError("at least 2 inputs needed")
+
+        u = self._prepareConcat(inputs[0], "0")
+        for i in range(1, len(inputs)):
+            u = u.union(self._prepareConcat(inputs[i], str(i)), preserveOrder=False)
 
-        p1 = self._prepareConcat(inputA, "1")
-        p2 = self._prepareConcat(inputB, "2")
+        lengths = [0] * len(inputs)
+        if withLengths or opts.Full:
+            u.cache()
+            libLengths = _libSource("ConcatLengths").addParam("opts", _optionsToString(opts))
+            maxLengths = u.mapPartitions(libLengths).reduce(_libSource("ConcatLengthsReduce"))
+            lengths = [maxLengths.get(i, 0) for i in range(len(inputs))]
 
-        u = p1.union(p2, preserveOrder=False)
         grouped = u.toPair().groupByKey()
 
-        join = _libSource("SeqTransform").addParam("opts", _optionsToString(opts))
-        return grouped.flatmap(join)
+        join = _libSource("ConcatJoin")\
+            .addParam("opts", _optionsToString(opts))\
+            .addParam("lengths", _optionsToString(lengths))
+        return grouped.flatmap(join), lengths
+
+    def _partitions(self, inputs, names, **kwargs):
+        if len(names) != len(inputs):
+            raise RuntimeError("one name per input is required for the partition file")
+        self.__inner.Full = True  # the missing records are always filled, so the blocks match the output
+        result, lengths = self._run(inputs, True, **kwargs)
+        partitions = ""
+        start = 1
+        for name, length in zip(names, lengths):
+            partitions += "%s, %s = %d-%d\n" % (self.__inner.PartitionType, name, start, start + length - 1)
+            start += length
+        return result, partitions
+
 
 class ConcatOptions:
 
     def __init__(self):
         self.Config = None  # KitConfig
         self.Full = None  # bool
-        self.Separator = None # str
+        self.Separator = None  # str
+        self.Fill = None  # str
+        self.PartitionType = None  # str
 
     def setDefaults(self):
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
         _setDefault(self, "Full", False)
         _setDefault(self, "Separator", "|")
+        _setDefault(self, "Fill", "")
+        _setDefault(self, "PartitionType", "DNA")
+
+        if self.Full and self.Fill == "":
+            self.Fill = "-"
+
+    def validate(self):
+        self.Config.validate()
+        if len(self.Fill) > 1:
+            raise OptionError("fill must be a single character", "Fill")
 
 
 def concat(inputA: IDataFrame, inputB: IDataFrame, o: SeqKitConcatOptions = None, **kwargs):
     if o is None:
         o = SeqKitConcatOptions()
-    return o._run(inputA, inputB, **kwargs)
+    return o._run([inputA, inputB], **kwargs)[0]
+
+
+def concatN(inputs, o: SeqKitConcatOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitConcatOptions()
+    return o._run(inputs, **kwargs)[0]
+
+
+def concatPartitions(inputs, names, o: SeqKitConcatOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitConcatOptions()
+    return o._partitions(inputs, names, **kwargs)
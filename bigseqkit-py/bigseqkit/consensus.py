@@ -0,0 +1,96 @@
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+
+
+class SeqKitConsensusOptions:
+
+    def __init__(self):
+        self.__inner = ConsensusOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def sample(self, v: str):
+        self.__inner.Sample = v
+
+    def iupac(self, v: bool):
+        self.__inner.Iupac = v
+
+    def passOnly(self, v: bool):
+        self.__inner.PassOnly = v
+
+    def conflict(self, v: str):
+        self.__inner.Conflict = v
+
+    def mapFormat(self, v: str):
+        self.__inner.MapFormat = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _run(self, input: IDataFrame, vcf: IDataFrame, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+
+        sample = -1
+        if opts.Sample != "":
+            header = vcf.filter(_libSource("ConsensusHeader")).collect()
+            if len(header) == 0:
+                raise RuntimeError("#CHROM header line not found in VCF")
+            columns = header[0].rstrip("\r\n").split("\t")
+            for i in range(9, len(columns)):
+                if columns[i] == opts.Sample:
+                    sample = i
+                    break
+            if sample < 0:
+                raise RuntimeError("sample not found in VCF: " + opts.Sample)
+
+        libRecords = _libSource("ConsensusKeyRecords").addParam("opts", _optionsToString(opts))
+        records = input.mapPartitions(libRecords)
+        variants = vcf.mapPartitions(_libSource("ConsensusKeyVariants"))
+
+        grouped = records.union(variants, preserveOrder=False).toPair().groupByKey()
+
+        apply = _libSource("ConsensusApply").addParam("opts", _optionsToString(opts)).addParam("sample", sample)
+        applied = grouped.flatmap(apply)
+        applied.cache()
+
+        mapping = applied.flatmap(_libSource("ConsensusMapping"))
+        if opts.MapFormat == "chain":
+            mapping = mapping.mapWithIndex(_libSource("ConsensusChainId"))
+        return applied.map(_libSource("ConsensusRecord")), mapping
+
+
+class ConsensusOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.Sample = None  # str
+        self.Iupac = None  # bool
+        self.PassOnly = None  # bool
+        self.Conflict = None  # str
+        self.MapFormat = None  # str
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "Sample", "")
+        _setDefault(self, "Iupac", False)
+        _setDefault(self, "PassOnly", False)
+        _setDefault(self, "Conflict", "error")
+        _setDefault(self, "MapFormat", "chain")
+
+    def validate(self):
+        self.Config.validate()
+        if self.Conflict not in ("error", "skip"):
+            raise OptionError("invalid value of flag --conflict: " + self.Conflict + ". available values: 'error', 'skip'",
+                              "Conflict")
+        if self.MapFormat not in ("chain", "offsets"):
+            raise OptionError("invalid value of flag --map-format: " + self.MapFormat +
+                              ". available values: 'chain', 'offsets'", "MapFormat")
+
+
+def consensus(input: IDataFrame, vcf: IDataFrame, o: SeqKitConsensusOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitConsensusOptions()
+    return o._run(input, vcf, **kwargs)
@@ -0,0 +1,136 @@
+from typing import List
+
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+
+
+# names of the built-in restriction enzymes of the executors, the enzymes of an EnzymeFile are only known by them
+_ENZYMES = {name.lower() for name in (
+    "AatII", "AgeI", "AluI", "ApaI", "ApeKI", "ApoI", "AscI", "AseI", "AvaI", "BamHI", "BbsI", "BfaI", "BglII",
+    "BsaI", "BsmBI", "BsrGI", "BstYI", "ClaI", "Csp6I", "CviAII", "DdeI", "DpnII", "EagI", "EcoRI", "EcoRV", "FatI",
+    "HaeIII", "HhaI", "HindIII", "HinfI", "HpaII", "HpyCH4IV", "KpnI", "MboI", "MluCI", "MluI", "MmeI", "MseI",
+    "MspI", "NcoI", "NdeI", "NheI", "NlaIII", "NotI", "NspI", "PacI", "PmeI", "PstI", "PvuII", "RsaI", "SacI",
+    "SacII", "SalI", "SapI", "Sau3AI", "Sau96I", "SbfI", "ScaI", "SfiI", "SmaI", "SpeI", "SphI", "StuI", "SwaI",
+    "TaqI", "XbaI", "XhoI", "XmaI"
+)}
+
+
+class SeqKitDigestOptions:
+
+    def __init__(self):
+        self.__inner = DigestOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def enzymes(self, v: List[str]):
+        self.__inner.Enzymes = v
+
+    def enzymeFile(self, v: str):
+        self.__inner.EnzymeFile = v
+
+    def circular(self, v: bool):
+        self.__inner.Circular = v
+
+    def bed(self, v: bool):
+        self.__inner.Bed = v
+
+    def minLen(self, v: int):
+        self.__inner.MinLen = v
+
+    def maxLen(self, v: int):
+        self.__inner.MaxLen = v
+
+    def histBin(self, v: int):
+        self.__inner.HistBin = v
+
+    def splitLen(self, v: int):
+        self.__inner.SplitLen = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _prepare(self, kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+        return opts
+
+    def _run(self, input: IDataFrame, **kwargs):
+        opts = self._prepare(kwargs)
+        libSplit = _libSource("DigestSplit").addParam("opts", _optionsToString(opts))
+        chunks = input.mapPartitions(libSplit)
+
+        if opts.SplitLen > 0:
+            chunks = chunks.repartition(chunks.partitions(), True, True)
+
+        libDigest = _libSource("Digest").addParam("opts", _optionsToString(opts))
+        digested = chunks.mapPartitions(libDigest)
+        if opts.SplitLen == 0:
+            return digested.flatmap(_libSource("DigestFragments"))
+
+        digested.cache()
+        fragments = digested.flatmap(_libSource("DigestFragments"))
+        pieces = digested.flatmap(_libSource("DigestPieces")).toPair().groupByKey()
+        libJoin = _libSource("DigestJoin").addParam("opts", _optionsToString(opts))
+        return fragments.union(pieces.flatmap(libJoin), preserveOrder=True)
+
+    def _histogram(self, fragments: IDataFrame, **kwargs):
+        opts = self._prepare(kwargs)
+        libprepare = _libSource("DigestHistogram").addParam("opts", _optionsToString(opts))
+        return fragments.mapPartitions(libprepare).reduce(_libSource("MapSumReduce"))
+
+
+class DigestOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.Enzymes = None  # List[str]
+        self.EnzymeFile = None  # str
+        self.Circular = None  # bool
+        self.Bed = None  # bool
+        self.MinLen = None  # int
+        self.MaxLen = None  # int
+        self.HistBin = None  # int
+        self.SplitLen = None  # int
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "Enzymes", [])
+        _setDefault(self, "EnzymeFile", "")
+        _setDefault(self, "Circular", False)
+        _setDefault(self, "Bed", False)
+        _setDefault(self, "MinLen", 0)
+        _setDefault(self, "MaxLen", 0)
+        _setDefault(self, "HistBin", 100)
+        _setDefault(self, "SplitLen", 1000000)
+
+    def validate(self):
+        self.Config.validate()
+        if len(self.Enzymes) == 0:
+            raise OptionError("flag -e (--enzyme) needed", "Enzymes")
+        if self.EnzymeFile == "":
+            for name in self.Enzymes:
+                if name.lower() not in _ENZYMES:
+                    raise OptionError("unknown enzyme: " + name, "Enzymes")
+        if self.MinLen < 0:
+            raise OptionError("value of flag --min-len should not be negative", "MinLen")
+        if 0 < self.MaxLen < self.MinLen:
+            raise OptionError("value of flag --max-len should not be lower than --min-len", "MaxLen")
+        if self.HistBin <= 0:
+            raise OptionError("value of flag --hist-bin should be greater than 0", "HistBin")
+        if self.SplitLen < 0:
+            raise OptionError("value of flag --split-len should not be negative", "SplitLen")
+
+
+def digest(input: IDataFrame, o: SeqKitDigestOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitDigestOptions()
+    return o._run(input, **kwargs)
+
+
+def digestHistogram(fragments: IDataFrame, o: SeqKitDigestOptions = None, **kwargs):
+    """Number of fragments by length of the output of digest with the same options, which should be cached"""
+    if o is None:
+        o = SeqKitDigestOptions()
+    return o._histogram(fragments, **kwargs)
@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitDuplicateOptions:
@@ -12,10 +12,14 @@ class SeqKitDuplicateOptions:
     def times(self, v: int):
         self.__inner.Times = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
         libprepare = _libSource("Duplicate").addParam("times", opts.Times)
         return input.mapPartitions(libprepare)
 
@@ -29,6 +33,11 @@ class DuplicateOptions:
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
         _setDefault(self, "Times", 1)
 
+    def validate(self):
+        self.Config.validate()
+        if self.Times <= 0:
+            raise OptionError("value of flag -n (--times) should be greater than 0", "Times")
+
 
 def duplicate(input: IDataFrame, o: SeqKitDuplicateOptions = None, **kwargs):
     if o is None:
@@ -0,0 +1,213 @@
+import csv
+import os
+import re
+import shutil
+import tempfile
+from contextlib import contextmanager
+from dataclasses import asdict
+from typing import Iterator, List, Union
+
+from bigseqkit.helper import _config, OptionError, SeqKitConfig, IDataFrame, StoreFASTXN
+from bigseqkit.stats import StatInfo
+
+RECORD_COLUMNS = ["id", "name", "seq", "qual", "length", "gc"]
+
+_KINDS = ("records", "table")
+
+
+def _pandas():
+    try:
+        import pandas
+    except ImportError:
+        raise ImportError("pandas is required to export to pandas: pip install bigseqkit[pandas]") from None
+    return pandas
+
+
+def _arrow():
+    try:
+        import pyarrow
+        import pyarrow.csv
+    except ImportError:
+        raise ImportError("pyarrow is required to export to Arrow: pip install bigseqkit[arrow]") from None
+    return pyarrow
+
+
+def _recordSchema(pyarrow):
+    return pyarrow.schema([("id", pyarrow.string()), ("name", pyarrow.string()),
+                           ("seq", pyarrow.large_string()), ("qual", pyarrow.large_string()),
+                           ("length", pyarrow.int64()), ("gc", pyarrow.float64())])
+
+
+def _partition(name):
+    """Returns the number of the partition of a file stored by saveAsTextFile, the last number of its name"""
+    numbers = re.findall(r'\d+', name)
+    return int(numbers[-1]) if len(numbers) > 0 else -1
+
+
+@contextmanager
+def _store(input: IDataFrame, kind: str, dir: str):
+    """Stores the partitions in parallel, a file per partition, to a temporary directory of dir, which must be
+    shared with the executors, and returns the paths of the non-empty files in order. The files are removed on
+    exit, or once they are read"""
+    if kind not in _KINDS:
+        raise OptionError("invalid kind: " + kind + ", available value: " + "|".join(_KINDS), "kind")
+    tmp = tempfile.mkdtemp(prefix="bigseqkit-export-", dir=os.path.abspath(dir or "."))
+    try:
+        path = os.path.join(tmp, "partitions")
+        StoreFASTXN(input, path)
+        names = sorted((name for name in os.listdir(path) if not name.startswith(".")), key=_partition)
+        files = [os.path.join(path, name) for name in names]
+        yield [file for file in files if os.path.getsize(file) > 0]
+    finally:
+        shutil.rmtree(tmp, ignore_errors=True)
+
+
+def _header(path: str):
+    """Returns the columns of the header line of the file and its size in bytes"""
+    with open(path, "rb") as f:
+        line = f.readline()
+    return line.decode().rstrip("\r\n").split("\t"), len(line)
+
+
+def _records(paths: List[str], config: SeqKitConfig, chunkSize: int) -> Iterator[dict]:
+    """Parses the FASTA/FASTQ records of the files, chunkSize at a time, as columns. The records never span
+    two partitions, every file is removed once it is read"""
+    inner = _config(config if config is not None else SeqKitConfig())
+    inner.setDefaults()
+    inner.validate()
+    idRegexp = re.compile(inner.IDRegexp)
+
+    chunk = {c: [] for c in RECORD_COLUMNS}
+
+    def add(head, seq, qual):
+        m = idRegexp.search(head)
+        chunk["id"].append(m.group(1) if m is not None else head)
+        chunk["name"].append(head)
+        chunk["seq"].append(seq)
+        chunk["qual"].append(qual)
+        chunk["length"].append(len(seq))
+        gc = sum(seq.count(b) for b in "GCgc")
+        chunk["gc"].append(gc / len(seq) * 100 if len(seq) > 0 else 0.0)
+
+    for path in paths:
+        with open(path) as f:
+            head, seq = None, []
+            for line in f:
+                line = line.rstrip("\r\n")
+                if line.startswith(">"):
+                    if head is not None:
+                        add(head, "".join(seq), None)
+                    head, seq = line[1:], []
+                elif line.startswith("@") and head is None:
+                    s = f.readline().rstrip("\r\n")
+                    f.readline()
+                    q = f.readline().rstrip("\r\n")
+                    add(line[1:], s, q)
+                elif head is not None:
+                    seq.append(line)
+                if len(chunk["id"]) >= chunkSize:
+                    yield chunk
+                    chunk = {c: [] for c in RECORD_COLUMNS}
+            if head is not None:
+                add(head, "".join(seq), None)
+        os.remove(path)
+        if len(chunk["id"]) >= chunkSize:
+            yield chunk
+            chunk = {c: [] for c in RECORD_COLUMNS}
+    if len(chunk["id"]) > 0:
+        yield chunk
+
+
+def _tables(paths: List[str], header: bool):
+    """Yields the files of a table with the lines to skip (the header line of the first file if header) and the
+    columns of the header, or None. The files without rows are skipped, every file is removed once it is read"""
+    columns, size = _header(paths[0]) if header and len(paths) > 0 else (None, 0)
+    for i, path in enumerate(paths):
+        skip = 1 if header and i == 0 else 0
+        if skip == 0 or os.path.getsize(path) > size:
+            yield path, skip, columns
+        os.remove(path)
+
+
+def iterPandas(input: IDataFrame, kind: str = "records", chunkSize: int = 100000, header: bool = True,
+               config: SeqKitConfig = None, dir: str = None):
+    """Yields pandas DataFrames of at most chunkSize rows, reading the partitions in order, one at a time.
+    The kind "records" are sequences, with the columns RECORD_COLUMNS (gc in %, qual is None in FASTA), and
+    "table" are tab-separated lines, e.g. of locate, with a header line if header. The partitions are stored in
+    dir (default the current directory), which must be shared with the executors"""
+    pandas = _pandas()
+    with _store(input, kind, dir) as paths:
+        if kind == "records":
+            for chunk in _records(paths, config, chunkSize):
+                yield pandas.DataFrame(chunk, columns=RECORD_COLUMNS)
+        else:
+            for path, skip, columns in _tables(paths, header):
+                with pandas.read_csv(path, sep="\t", header=None, names=columns, skiprows=skip,
+                                     quoting=csv.QUOTE_NONE, chunksize=chunkSize) as reader:
+                    yield from reader
+
+
+def iterArrow(input: IDataFrame, kind: str = "records", chunkSize: int = 100000, header: bool = True,
+              config: SeqKitConfig = None, dir: str = None):
+    """Yields Arrow RecordBatches, see iterPandas. The sequences and qualities are large strings"""
+    pyarrow = _arrow()
+    with _store(input, kind, dir) as paths:
+        if kind == "records":
+            schema = _recordSchema(pyarrow)
+            for chunk in _records(paths, config, chunkSize):
+                yield pyarrow.RecordBatch.from_pydict(chunk, schema=schema)
+        else:
+            # the types of the first partition are kept, so the batches of all the partitions share the schema
+            schema = None
+            for path, skip, columns in _tables(paths, header):
+                reader = pyarrow.csv.open_csv(
+                    path,
+                    read_options=pyarrow.csv.ReadOptions(column_names=columns, skip_rows=skip,
+                                                         autogenerate_column_names=columns is None),
+                    parse_options=pyarrow.csv.ParseOptions(delimiter="\t", quote_char=False),
+                    convert_options=pyarrow.csv.ConvertOptions(column_types=schema))
+                if schema is None:
+                    schema = reader.schema
+                for batch in reader:
+                    for i in range(0, batch.num_rows, chunkSize):
+                        yield batch.slice(i, chunkSize)
+
+
+def toPandas(input: Union[IDataFrame, StatInfo, List[StatInfo]], kind: str = "records", header: bool = True,
+             config: SeqKitConfig = None, dir: str = None):
+    """Collects a frame to a pandas DataFrame, see iterPandas, or the stats to a DataFrame of a row per
+    StatInfo"""
+    pandas = _pandas()
+    stats = _statsRows(input)
+    if stats is not None:
+        return pandas.DataFrame(stats)
+    chunks = list(iterPandas(input, kind, header=header, config=config, dir=dir))
+    if len(chunks) == 0:
+        return pandas.DataFrame(columns=RECORD_COLUMNS if kind == "records" else None)
+    return pandas.concat(chunks, ignore_index=True)
+
+
+def toArrow(input: Union[IDataFrame, StatInfo, List[StatInfo]], kind: str = "records", header: bool = True,
+            config: SeqKitConfig = None, dir: str = None):
+    """Collects a frame to an Arrow Table, see iterArrow, or the stats to a Table of a row per StatInfo"""
+    pyarrow = _arrow()
+    stats = _statsRows(input)
+    if stats is not None:
+        return pyarrow.Table.from_pylist(stats)
+    batches = list(iterArrow(input, kind, header=header, config=config, dir=dir))
+    if len(batches) == 0:
+        return _recordSchema(pyarrow).empty_table() if kind == "records" else pyarrow.table({})
+    return pyarrow.Table.from_batches(batches)
+
+
+def _statsRows(input):
+    if isinstance(input, StatInfo):
+        input = [input]
+    if isinstance(input, list) and all(isinstance(info, StatInfo) for info in input):
+        return [asdict(info) for info in input]
+    return None
+
+
+# names of the pandas and Arrow APIs
+to_pandas = toPandas
+to_arrow = toArrow
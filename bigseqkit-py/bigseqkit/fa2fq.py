@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitFa2FqOptions:
@@ -16,10 +16,14 @@ class SeqKitFa2FqOptions:
     def onlyPositiveStrand(self, v: bool):
         self.__inner.OnlyPositiveStrand = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
         libprepare = _libSource("Fa2Fq").addParam("opts", _optionsToString(opts))
         return input.mapPartitions(libprepare)
 
@@ -36,6 +40,11 @@ class Fa2FqOptions:
         _setDefault(self, "FastaFile", "")
         _setDefault(self, "OnlyPositiveStrand", False)
 
+    def validate(self):
+        self.Config.validate()
+        if self.FastaFile == "":
+            raise OptionError("flag -f (--fasta-file) needed", "FastaFile")
+
 
 def fa2fq(input: IDataFrame, o: SeqKitFa2FqOptions = None, **kwargs):
     if o is None:
@@ -1,6 +1,6 @@
 from typing import List
 
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, _compile, SeqKitConfig, IDataFrame
 
 
 class SeqKitFaidxOptions:
@@ -26,10 +26,14 @@ class SeqKitFaidxOptions:
     def regions(self, v: List[str]):
         self.__inner.Regions = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
 
         offsets = input.mapPartitions(_libSource("FaidxOffset"))
         offsetsArray = offsets.collect()
@@ -67,6 +71,12 @@ class SeqOptions:
         _setDefault(self, "RegionFile", "")
         _setDefault(self, "Regions", [])
 
+    def validate(self):
+        self.Config.validate()
+        if self.UseRegexp:
+            for query in self.Regions:
+                _compile("Regions", query, "invalid regular expression")
+
 
 def faidx(input: IDataFrame, o: SeqKitFaidxOptions = None, **kwargs):
     if o is None:
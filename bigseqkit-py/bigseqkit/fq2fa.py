@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, SeqKitConfig, IDataFrame
 
 
 class SeqKitFq2FaOptions:
@@ -9,10 +9,14 @@ class SeqKitFq2FaOptions:
     def config(self, v: SeqKitConfig):
         self.__inner.Config = _config(v)
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
         libprepare = _libSource("Fq2Fa").addParam("opts", _optionsToString(opts))
         return input.mapPartitions(libprepare)
 
@@ -25,6 +29,9 @@ class Fq2FaOptions:
     def setDefaults(self):
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
 
+    def validate(self):
+        self.Config.validate()
+
 
 def fq2fa(input: IDataFrame, o: SeqKitFq2FaOptions = None, **kwargs):
     if o is None:
@@ -1,6 +1,6 @@
 from typing import List
 
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, _validateRegion, _noPatterns, _compile, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitGrepOptions:
@@ -53,10 +53,14 @@ class SeqKitGrepOptions:
     def count(self, v: bool):
         self.__inner.Count = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
 
         grep = _libSource("GrepPairMatched").addParam("opts", _optionsToString(opts))
         results = input.mapPartitionsWithIndex(grep)
@@ -110,6 +114,23 @@ class GrepOptions:
         _setDefault(self, "Circular", False)
         _setDefault(self, "Count", False)
 
+    def validate(self):
+        self.Config.validate()
+        if _noPatterns(self.Pattern) and self.PatternFile == "":
+            raise OptionError("one of flags -p (--pattern) and -f (--pattern-file) needed")
+        if self.MaxMismatch < 0:
+            raise OptionError("value of flag -m (--max-mismatch) should not be negative", "MaxMismatch")
+        if self.MaxMismatch > 0 and (self.UseRegexp or self.Degenerate):
+            raise OptionError("flag -r (--use-regexp) or -d (--degenerate) not allowed when giving flag -m (--max-mismatch)",
+                              "MaxMismatch")
+        if self.UseRegexp and self.Degenerate:
+            raise OptionError("could not give both flags -d (--degenerate) and -r (--use-regexp)")
+        if self.UseRegexp:
+            for p in self.Pattern:
+                _compile("Pattern", p, "invalid regular expression")
+        if self.Region != "":
+            _validateRegion("grep", self.Region)
+
 
 def grep(input: IDataFrame, o: SeqKitGrepOptions = None, **kwargs):
     if o is None:
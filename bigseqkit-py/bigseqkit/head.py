@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 from bigseqkit.range import SeqKitRangeOptions, range
 
 class SeqKitHeadOptions:
@@ -12,10 +12,14 @@ class SeqKitHeadOptions:
     def n(self, v: int):
         self.__inner.N = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
 
         oRange = SeqKitRangeOptions().range("1:"+str(opts.N))
         oRange._SeqKitRangeOptions__inner = opts.Config
@@ -32,6 +36,11 @@ class HeadOptions:
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
         _setDefault(self, "N", 10)
 
+    def validate(self):
+        self.Config.validate()
+        if self.N <= 0:
+            raise OptionError("value of flag -n (--number) should be greater than 0", "N")
+
 
 def head(input: IDataFrame, o: SeqKitHeadOptions = None, **kwargs):
     if o is None:
@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitHeadGenomeOptions:
@@ -12,10 +12,14 @@ class SeqKitHeadGenomeOptions:
     def headGenomeOptions(self, v: int):
         self.__inner.HeadGenomeOptions = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
 
         firstSeq = input.take(1)
 
@@ -39,6 +43,11 @@ class HeadGenomeOptions:
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
         _setDefault(self, "HeadGenomeOptions", 1)
 
+    def validate(self):
+        self.Config.validate()
+        if self.HeadGenomeOptions <= 0:
+            raise OptionError("value of flag -m (--mini-common-words) should be greater than 0", "HeadGenomeOptions")
+
 
 def headGenome(input: IDataFrame, o: SeqKitHeadGenomeOptions = None, **kwargs):
     if o is None:
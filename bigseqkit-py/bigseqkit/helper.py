@@ -1,4 +1,6 @@
 import os
+import re
+import copy
 import json
 import ignis.driver.api.ISource
 from ignis.driver.api.IDataFrame import IDataFrame
@@ -8,6 +10,14 @@ lib_prefix = os.path.join(os.getenv("IGNIS_HOME"), "core", "go", "bigseqkit.so:"
 defaultIDRegexp = r'^(\S+)\s?'
 
 
+class OptionError(RuntimeError):
+    """Error of an invalid option, raised before any job is submitted. option is the name of the field, if any"""
+
+    def __init__(self, msg, option=None):
+        super().__init__(msg)
+        self.option = option
+
+
 def _libSource(name):
     return ignis.driver.api.ISource.ISource(lib_prefix + name, native=False)
 
@@ -18,7 +28,8 @@ def _setDefault(self, name, val):
     attr = getattr(self, name)
     if type(attr) != type(val):
         fname = name[0].lower() + name[1:]
-        raise RuntimeError(fname + ": Invalid type. Expected " + type(attr).__name__ + " but got " + type(val).__name__)
+        raise OptionError(fname + ": Invalid type. Expected " + type(val).__name__ + " but got " + type(attr).__name__,
+                          name)
     return attr
 
 
@@ -31,16 +42,61 @@ def _optionsToString(v):
     return json.dumps(v, cls=_OptionsEncoder)
 
 
+def _kargField(obj, name):
+    key = name.replace("_", "").lower()
+    for field in vars(obj):
+        if field[0].isupper() and field.lower() == key:
+            return field
+    return None
+
+
 def _parseKargs(obj, kwargs):
-    if len(kwargs) > 0:
-        if obj.Config is None:
-            obj.Config = KitConfig()
-        for name, value in kwargs.items():
-            cname = name.capitalize()
-            if hasattr(obj, cname):
-                setattr(obj, cname, value)
-            elif hasattr(obj.Config, cname):
-                setattr(obj.Config, cname, value)
+    """Sets the fields of the options, or of its KitConfig, named as the keyword arguments in any case and with
+    or without underscores, e.g. minLen, min_len or MinLen for MinLen. Unknown names raise an OptionError"""
+    for name, value in kwargs.items():
+        field = _kargField(obj, name)
+        if field is not None:
+            if field == "Config" and isinstance(value, SeqKitConfig):
+                value = _config(value)
+            setattr(obj, field, value)
+            continue
+        config = obj.Config if obj.Config is not None else KitConfig()
+        field = _kargField(config, name)
+        if field is None:
+            raise OptionError("unknown option: " + name, name)
+        setattr(config, field, value)
+        obj.Config = config
+
+
+def _validate(inner):
+    opts = copy.copy(inner)
+    opts.setDefaults()
+    opts.validate()
+
+
+def _compile(option, pattern, msg):
+    try:
+        return re.compile(pattern)
+    except re.error:
+        raise OptionError(msg + ": " + pattern, option)
+
+
+def _noPatterns(patterns):
+    return all(p == "" for p in patterns)
+
+
+_reRegion = re.compile(r'^(-?\d+):(-?\d+)$')
+
+
+def _validateRegion(command, region):
+    m = _reRegion.match(region)
+    if m is None:
+        raise OptionError("invalid region: " + region + ". type \"seqkit " + command + " -h\" for more examples", "Region")
+    start, end = int(m.group(1)), int(m.group(2))
+    if start == 0 or end == 0:
+        raise OptionError("both start and end should not be 0", "Region")
+    if start < 0 < end:
+        raise OptionError("when start < 0, end should not > 0", "Region")
 
 
 def _config(sq):
@@ -73,6 +129,10 @@ class SeqKitConfig:
     def validateSeqLength(self, v: int):
         self.__inner.ValidateSeqLength = v
 
+    def validate(self):
+        _validate(self.__inner)
+
+
 class KitConfig:
 
     def __init__(self):
@@ -102,6 +162,22 @@ class KitConfig:
 
         return self
 
+    def validate(self):
+        if self.SeqType.lower() not in ("dna", "rna", "protein", "unlimit", "auto"):
+            raise OptionError("invalid sequence type: " + self.SeqType + ", available value: dna|rna|protein|unlimit|auto",
+                              "SeqType")
+        if self.LineWidth < 0:
+            raise OptionError("value of flag -w (--line-width) should not be negative", "LineWidth")
+        if self.AlphabetGuessSeqLength < 0:
+            raise OptionError("value of flag --alphabet-guess-seq-length should not be negative",
+                              "AlphabetGuessSeqLength")
+        if 0 < self.AlphabetGuessSeqLength < 1000:
+            raise OptionError("value of flag --alphabet-guess-seq-length too small, should >= 1000",
+                              "AlphabetGuessSeqLength")
+        if _compile("IDRegexp", self.IDRegexp, "invalid value of flag --id-regexp").groups < 1:
+            raise OptionError("value of flag --id-regexp must contain \"(\" and \")\" to capture matched ID: " +
+                              self.IDRegexp, "IDRegexp")
+
 
 def _fixer(input: IDataFrame, delim: str):
     fixer = _libSource("ReadFixer").addParam("delim", delim)
@@ -116,6 +192,10 @@ def readFASTQ(path: str, worker: IWorker, minPartitions: int = None) -> IDataFra
     return _fixer(worker.plainFile(path, minPartitions, delim='@'), delim='@')
 
 
+def readAnnotation(path: str, worker: IWorker, minPartitions: int = None) -> IDataFrame:
+    return worker.plainFile(path, minPartitions, delim='\n')
+
+
 def StoreFASTX(input: IDataFrame, path: str):
     store = _libSource("FileStore").addParam("path", path)
     input.foreachPartition(store)
@@ -1,6 +1,6 @@
 from typing import List
 
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, _noPatterns, _compile, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitLocateOptions:
@@ -53,10 +53,14 @@ class SeqKitLocateOptions:
     def circular(self, v: bool):
         self.__inner.Circular = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
         libprepare = _libSource("Locate").addParam("opts", _optionsToString(opts))
         
         return input.mapPartitionsWithIndex(libprepare)
@@ -98,6 +102,26 @@ class LocateOptions:
         _setDefault(self, "HideMatched", False)
         _setDefault(self, "Circular", False)
 
+    def validate(self):
+        self.Config.validate()
+        if _noPatterns(self.Pattern) and self.PatternFile == "":
+            raise OptionError("one of flags -p (--pattern) and -f (--pattern-file) needed")
+        if self.MaxMismatch < 0:
+            raise OptionError("value of flag -m (--max-mismatch) should not be negative", "MaxMismatch")
+        if self.MaxMismatch > 0:
+            if self.Degenerate:
+                raise OptionError("flag -d (--degenerate) not allowed when giving flag -m (--max-mismatch)", "Degenerate")
+            if self.UseRegexp:
+                raise OptionError("flag -r (--use-regexp) not allowed when giving flag -m (--max-mismatch)", "UseRegexp")
+        if self.UseFmi:
+            if self.Degenerate:
+                raise OptionError("flag -d (--degenerate) not allowed when giving flag -F (--use-fmi)", "Degenerate")
+            if self.UseRegexp:
+                raise OptionError("flag -r (--use-regexp) not allowed when giving flag -F (--use-fmi)", "UseRegexp")
+        if self.UseRegexp:
+            for p in self.Pattern:
+                _compile("Pattern", p, "invalid regular expression")
+
 
 def locate(input: IDataFrame, o: SeqKitLocateOptions = None, **kwargs):
     if o is None:
@@ -0,0 +1,85 @@
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+
+
+class SeqKitMaskOptions:
+
+    def __init__(self):
+        self.__inner = MaskOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def method(self, v: str):
+        self.__inner.Method = v
+
+    def window(self, v: int):
+        self.__inner.Window = v
+
+    def dustLevel(self, v: float):
+        self.__inner.DustLevel = v
+
+    def minEntropy(self, v: float):
+        self.__inner.MinEntropy = v
+
+    def mode(self, v: str):
+        self.__inner.Mode = v
+
+    def maxMaskedFrac(self, v: float):
+        self.__inner.MaxMaskedFrac = v
+
+    def bed(self, v: bool):
+        self.__inner.Bed = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _run(self, input: IDataFrame, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+
+        libprepare = _libSource("Mask").addParam("opts", _optionsToString(opts))
+        return input.mapPartitions(libprepare)
+
+
+class MaskOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.Method = None  # str
+        self.Window = None  # int
+        self.DustLevel = None  # float
+        self.MinEntropy = None  # float
+        self.Mode = None  # str
+        self.MaxMaskedFrac = None  # float
+        self.Bed = None  # bool
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "Method", "dust")
+        _setDefault(self, "Window", 64)
+        _setDefault(self, "DustLevel", 20.0)
+        _setDefault(self, "MinEntropy", 0.5)
+        _setDefault(self, "Mode", "soft")
+        _setDefault(self, "MaxMaskedFrac", 1.0)
+        _setDefault(self, "Bed", False)
+
+    def validate(self):
+        self.Config.validate()
+        if self.Method not in ("dust", "entropy"):
+            raise OptionError("invalid method: " + self.Method + ", available: dust, entropy", "Method")
+        if self.Mode not in ("soft", "hard", "none"):
+            raise OptionError("invalid mask mode: " + self.Mode + ", available: soft, hard, none", "Mode")
+        if self.Window < 4:
+            raise OptionError("value of flag -W (--window) should be at least 4", "Window")
+        if self.MinEntropy < 0 or self.MinEntropy > 1:
+            raise OptionError("value of flag --min-entropy should be in range [0, 1]", "MinEntropy")
+        if self.MaxMaskedFrac < 0 or self.MaxMaskedFrac > 1:
+            raise OptionError("value of flag --max-masked-frac should be in range [0, 1]", "MaxMaskedFrac")
+
+
+def mask(input: IDataFrame, o: SeqKitMaskOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitMaskOptions()
+    return o._run(input, **kwargs)
@@ -0,0 +1,83 @@
+from typing import List
+
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+from bigseqkit.codon_usage import _CODON_TABLES
+from bigseqkit.translate import _validateFrames
+
+
+class SeqKitOrfsOptions:
+
+    def __init__(self):
+        self.__inner = OrfsOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def translTable(self, v: int):
+        self.__inner.TranslTable = v
+
+    def frame(self, v: List[str]):
+        self.__inner.Frame = v
+
+    def minLen(self, v: int):
+        self.__inner.MinLen = v
+
+    def start(self, v: str):
+        self.__inner.Start = v
+
+    def nested(self, v: bool):
+        self.__inner.Nested = v
+
+    def outFormat(self, v: str):
+        self.__inner.OutFormat = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _run(self, input: IDataFrame, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+        libprepare = _libSource("Orfs").addParam("opts", _optionsToString(opts))
+        return input.mapPartitions(libprepare)
+
+
+class OrfsOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.TranslTable = None  # int
+        self.Frame = None  # list[str]
+        self.MinLen = None  # int
+        self.Start = None  # str
+        self.Nested = None  # bool
+        self.OutFormat = None  # str
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "TranslTable", 1)
+        _setDefault(self, "Frame", ["6"])
+        _setDefault(self, "MinLen", 90)
+        _setDefault(self, "Start", "atg")
+        _setDefault(self, "Nested", False)
+        _setDefault(self, "OutFormat", "nucl")
+
+    def validate(self):
+        self.Config.validate()
+        if self.TranslTable not in _CODON_TABLES:
+            raise OptionError("invalid translate table: " + str(self.TranslTable), "TranslTable")
+        if self.Start not in ("atg", "alt", "none"):
+            raise OptionError("invalid start codons: " + self.Start + ". available values: 'atg', 'alt', 'none'", "Start")
+        if self.OutFormat not in ("nucl", "protein", "bed", "gtf"):
+            raise OptionError("invalid output format: " + self.OutFormat +
+                              ". available values: 'nucl', 'protein', 'bed', 'gtf'", "OutFormat")
+        if self.MinLen < 0:
+            raise OptionError("value of flag -m (--min-len) should not be negative", "MinLen")
+        _validateFrames(self.Frame)
+
+
+def orfs(input: IDataFrame, o: SeqKitOrfsOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitOrfsOptions()
+    return o._run(input, **kwargs)
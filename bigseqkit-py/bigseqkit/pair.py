@@ -0,0 +1,80 @@
+from dataclasses import dataclass
+from typing import Optional
+
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, SeqKitConfig, IDataFrame
+
+
+class SeqKitPairOptions:
+
+    def __init__(self):
+        self.__inner = PairOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def saveUnpaired(self, v: bool):
+        self.__inner.SaveUnpaired = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _prepare(self, input: IDataFrame, id: str):
+        libprepare = _libSource("PairPrepare"). \
+            addParam("opts", _optionsToString(self.__inner)). \
+            addParam("id", id)
+        return input.mapPartitions(libprepare)
+
+    def _run(self, inputA: IDataFrame, inputB: IDataFrame, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+
+        u = self._prepare(inputA, "1").union(self._prepare(inputB, "2"), preserveOrder=False)
+        cache = u.toPair().groupByKey()
+
+        pairs = cache.flatmap(_libSource("Pair"))
+        unpaired = None
+        if opts.SaveUnpaired:
+            unpaired = cache.flatmap(_libSource("Pair").addParam("unpaired", True))
+        return PairResult(pairs, unpaired, cache)
+
+
+class PairOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.SaveUnpaired = None  # bool
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "SaveUnpaired", False)
+
+    def validate(self):
+        self.Config.validate()
+
+
+@dataclass
+class PairResult:
+    """pairs are the (read 1, read 2) pairs, unpaired the (file id "1" or "2", read) of the reads without mate
+    if SaveUnpaired and cache the reads grouped by id, to be persisted if both are used"""
+    pairs: IDataFrame
+    unpaired: Optional[IDataFrame]
+    cache: IDataFrame
+
+    def __iter__(self):
+        return iter((self.pairs, self.unpaired, self.cache))
+
+
+def pair(inputA: IDataFrame, inputB: IDataFrame, o: SeqKitPairOptions = None, **kwargs) -> PairResult:
+    if o is None:
+        o = SeqKitPairOptions()
+    return o._run(inputA, inputB, **kwargs)
+
+
+def pairIndex(p: IDataFrame, i: int) -> IDataFrame:
+    return p.map(_libSource("PairI").addParam("i", i))
+
+
+def unpairedId(p: IDataFrame, id: str) -> IDataFrame:
+    return pairIndex(p.filter(_libSource("PairF").addParam("id", id)), 1)
@@ -0,0 +1,122 @@
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, SeqKitConfig, IDataFrame
+
+# average masses of the residues (amino acid minus water), in Daltons
+_RESIDUE_MASS = {
+    "A": 71.0788, "R": 156.1875, "N": 114.1038, "D": 115.0886, "C": 103.1388,
+    "E": 129.1155, "Q": 128.1307, "G": 57.0519, "H": 137.1411, "I": 113.1594,
+    "L": 113.1594, "K": 128.1741, "M": 131.1926, "F": 147.1766, "P": 97.1167,
+    "S": 87.0782, "T": 101.1051, "W": 186.2132, "Y": 163.1760, "V": 99.1326,
+    "U": 150.0388, "O": 237.3018,
+}
+
+# Kyte-Doolittle hydropathy
+_HYDROPATHY = {
+    "A": 1.8, "R": -4.5, "N": -3.5, "D": -3.5, "C": 2.5,
+    "E": -3.5, "Q": -3.5, "G": -0.4, "H": -3.2, "I": 4.5,
+    "L": 3.8, "K": -3.9, "M": 1.9, "F": 2.8, "P": -1.6,
+    "S": -0.8, "T": -0.7, "W": -0.9, "Y": -1.3, "V": 4.2,
+}
+
+# EMBOSS pKa values
+_PKA_NTERM = 8.6
+_PKA_CTERM = 3.6
+_POSITIVE_PKA = {"K": 10.8, "R": 12.5, "H": 6.5}
+_NEGATIVE_PKA = {"D": 3.9, "E": 4.1, "C": 8.5, "Y": 10.1}
+
+
+class SeqKitProteinStatsOptions:
+
+    def __init__(self):
+        self.__inner = ProteinStatsOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _opts(self, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+        return opts
+
+    def _run(self, input: IDataFrame, **kwargs):
+        opts = self._opts(**kwargs)
+        libCount = _libSource("ProteinStats").addParam("opts", _optionsToString(opts))
+        return ProteinInfo(input.mapPartitions(libCount).reduce(_libSource("MapSumReduce")))
+
+    def _perRecord(self, input: IDataFrame, **kwargs):
+        opts = self._opts(**kwargs)
+        libRecords = _libSource("ProteinStatsRecords").addParam("opts", _optionsToString(opts))
+        return input.mapPartitions(libRecords)
+
+
+class ProteinStatsOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+
+    def validate(self):
+        self.Config.validate()
+
+
+class ProteinInfo:
+
+    def __init__(self, counts):
+        self.records = counts.get(-1, 0)
+        self.counts = {chr(k): v for k, v in counts.items() if k >= 0}
+
+    def length(self):
+        return sum(self.counts.values())
+
+    def composition(self):
+        n = self.length()
+        return {aa: c / n for aa, c in self.counts.items()}
+
+    def molecularWeight(self):
+        return 18.01528 * self.records + sum(_RESIDUE_MASS.get(aa, 110) * c for aa, c in self.counts.items())
+
+    def __charge(self, pH):
+        charge = self.records / (1 + 10 ** (pH - _PKA_NTERM)) - self.records / (1 + 10 ** (_PKA_CTERM - pH))
+        for aa, pKa in _POSITIVE_PKA.items():
+            charge += self.counts.get(aa, 0) / (1 + 10 ** (pH - pKa))
+        for aa, pKa in _NEGATIVE_PKA.items():
+            charge -= self.counts.get(aa, 0) / (1 + 10 ** (pKa - pH))
+        return charge
+
+    def isoelectricPoint(self):
+        low, high = 0.0, 14.0
+        while high - low > 0.001:
+            mid = (low + high) / 2
+            if self.__charge(mid) > 0:
+                low = mid
+            else:
+                high = mid
+        return (low + high) / 2
+
+    def gravy(self):
+        s, n = 0.0, 0
+        for aa, c in self.counts.items():
+            if aa in _HYDROPATHY:
+                s += _HYDROPATHY[aa] * c
+                n += c
+        return s / n if n > 0 else 0.0
+
+
+def proteinStats(input: IDataFrame, o: SeqKitProteinStatsOptions = None, **kwargs):
+    """Amino-acid composition of all the records, cache the input to also call proteinStatsPerRecord"""
+    if o is None:
+        o = SeqKitProteinStatsOptions()
+    return o._run(input, **kwargs)
+
+
+def proteinStatsPerRecord(input: IDataFrame, o: SeqKitProteinStatsOptions = None, **kwargs):
+    """A line per record: ID, length, molecular weight, isoelectric point and GRAVY, separated by tabs"""
+    if o is None:
+        o = SeqKitProteinStatsOptions()
+    return o._perRecord(input, **kwargs)
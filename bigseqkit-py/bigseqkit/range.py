@@ -1,6 +1,6 @@
 import re
 
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitRangeOptions:
@@ -14,13 +14,14 @@ class SeqKitRangeOptions:
     def range(self, v: str):
         self.__inner.Range = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
-
-        if opts.Range == "":
-            raise RuntimeError("flag -r (--range) needed")
+        opts.validate()
 
         r = opts.Range.split(":")
         start = int(r[0])
@@ -28,9 +29,6 @@ class SeqKitRangeOptions:
         if len(r) > 1:
             end = int(r[1])
 
-        if start == 0 or end == 0:
-            raise RuntimeError("either start and end should not be 0")
-
         if start > 0:
             start -= 1
 
@@ -44,8 +42,8 @@ class SeqKitRangeOptions:
             if end < 0:
                 end += n
 
-        if start <= end:
-            raise RuntimeError("start must be > than end")
+        if start > end:
+            raise RuntimeError("start should not be greater than end")
 
         libprepare = _libSource("RangePrepare").addParam("start", start).addParam("end", end)
         prepared = input.mapWithIndex(libprepare)
@@ -62,6 +60,22 @@ class RangeOptions:
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
         _setDefault(self, "Range", "")
 
+    def validate(self):
+        self.Config.validate()
+        if self.Range == "":
+            raise OptionError("flag -r (--range) needed", "Range")
+        r = self.Range.split(":")
+        try:
+            start, end = int(r[0]), int(r[1]) if len(r) > 1 else -1
+        except ValueError:
+            raise OptionError("invalid range: " + self.Range, "Range")
+        if len(r) > 2:
+            raise OptionError("invalid range: " + self.Range, "Range")
+        if start == 0 or end == 0:
+            raise OptionError("either start and end should not be 0", "Range")
+        if (start > 0) == (end > 0) and start > end:
+            raise OptionError("start should not be greater than end", "Range")
+
 
 def range(input: IDataFrame, o: SeqKitRangeOptions = None, **kwargs):
     if o is None:
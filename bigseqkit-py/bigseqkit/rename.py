@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, SeqKitConfig, IDataFrame
 
 
 class SeqKitRenameOptions:
@@ -12,10 +12,14 @@ class SeqKitRenameOptions:
     def byName(self, v: bool):
         self.__inner.ByName = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
 
         libprepare = _libSource("RenamePrepare").addParam("opts", _optionsToString(opts))
         prepared = input.mapPartitions(libprepare)
@@ -35,6 +39,9 @@ class RenameOptions:
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
         _setDefault(self, "ByName", False)
 
+    def validate(self):
+        self.Config.validate()
+
 
 def rename(input: IDataFrame, o: SeqKitRenameOptions = None, **kwargs):
     if o is None:
@@ -1,4 +1,8 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+import re
+
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, _compile, OptionError, SeqKitConfig, IDataFrame
+
+_reReplaceKV = re.compile(r'\{(KV|kv)\}')
 
 
 class SeqKitReplaceOptions:
@@ -39,10 +43,14 @@ class SeqKitReplaceOptions:
     def keyMissRepl(self, v: str):
         self.__inner.KeyMissRepl = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
         libprepare = _libSource("Replace").addParam("opts", _optionsToString(opts))
         return input.mapPartitions(libprepare)
 
@@ -74,6 +82,32 @@ class SeqOptions:
         _setDefault(self, "KeyCaptIdx", 1)
         _setDefault(self, "KeyMissRepl", "")
 
+    def validate(self):
+        self.Config.validate()
+        if self.Pattern == "":
+            raise OptionError("flags -p (--pattern) needed", "Pattern")
+        _compile("Pattern", self.Pattern, "invalid value of flag -p (--pattern)")
+        if self.NrWidth <= 0:
+            raise OptionError("value of flag --nr-width should be greater than 0", "NrWidth")
+        withKV = _reReplaceKV.search(self.Replacement) is not None
+        if self.KvFile != "":
+            if len(self.Replacement) == 0:
+                raise OptionError("flag -r (--replacement) needed when given flag -k (--kv-file)", "Replacement")
+            if not withKV:
+                raise OptionError("replacement symbol \"{kv}\"/\"{KV}\" not found in value of flag -r (--replacement) "
+                                  "when flag -k (--kv-file) given", "Replacement")
+        if withKV:
+            if re.search(r'\(.+\)', self.Pattern) is None:
+                raise OptionError("value of -p (--pattern) must contains \"(\" and \")\" to capture data which is used "
+                                  "specify the KEY", "Pattern")
+            if self.BySeq:
+                raise OptionError("replaceing with key-value pairs was not supported for sequence", "BySeq")
+            if self.KvFile == "":
+                raise OptionError("since replacement symbol \"{kv}\"/\"{KV}\" found in value of flag -r (--replacement), "
+                                  "tab-delimited key-value file should be given by flag -k (--kv-file)", "KvFile")
+            if self.KeyCaptIdx < 1:
+                raise OptionError("value of flag -I (--key-capt-idx) should be greater than 0", "KeyCaptIdx")
+
 
 def replace(input: IDataFrame, o: SeqKitReplaceOptions = None, **kwargs):
     if o is None:
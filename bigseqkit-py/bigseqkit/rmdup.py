@@ -1,4 +1,13 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+import json
+
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, _compile, OptionError, SeqKitConfig, IDataFrame
+from bigseqkit.pair import unpairedId
+
+defaultIlluminaRegexp = r'^[^:\s]+:\d+:[^:\s]+:(\d+):(\d+):(\d+):(\d+)'
+
+_OPTICAL_READS = 0
+_OPTICAL_OPTICAL = 1
+_OPTICAL_PCR = 2
 
 
 class SeqKitRmDupOptions:
@@ -27,27 +36,83 @@ class SeqKitRmDupOptions:
     def onlyPositiveStrand(self, v: bool):
         self.__inner.OnlyPositiveStrand = v
 
-    def _run(self, input: IDataFrame, **kwargs):
-        opts = self.__inner
-        _parseKargs(opts, kwargs)
-        opts.setDefaults()
+    def optical(self, v: bool):
+        self.__inner.Optical = v
+
+    def illuminaRegexp(self, v: str):
+        self.__inner.IlluminaRegexp = v
 
-        revcom = not opts.OnlyPositiveStrand
+    def pixelDistance(self, v: int):
+        self.__inner.PixelDistance = v
 
-        if opts.BySeq and opts.ByName:
-            raise RuntimeError("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
+    def prefixLength(self, v: int):
+        self.__inner.PrefixLength = v
 
-        if not revcom and not opts.BySeq:
-            raise RuntimeError("flag -s (--by-seq) needed when using -P (--only-positive-strand)")
+    def removeClass(self, v: str):
+        self.__inner.RemoveClass = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _group(self, input: IDataFrame, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
 
         prepare = _libSource("RmDupPrepare").addParam("opts", _optionsToString(opts))
 
         prepared = input.mapPartitions(prepare)
-        grouped = prepared.toPair().groupByKey()
+        return opts, prepared.toPair().groupByKey()
+
+    def _run(self, input: IDataFrame, **kwargs):
+        if self.__inner.Optical or kwargs.get("optical", False):
+            return self._optical(input, False, **kwargs)[0]
+        opts, grouped = self._group(input, **kwargs)
 
         check = _libSource("RmDupCheck").addParam("opts", _optionsToString(opts))
         return grouped.flatmap(check)
 
+    def _optical(self, input: IDataFrame, paired: bool, count: bool = False, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.Optical = True
+        opts.validate()
+
+        if paired:
+            input = input.map(_libSource("RmDupOpticalJoin"))
+
+        prepare = _libSource("RmDupOpticalPrepare").addParam("opts", _optionsToString(opts)).addParam("paired", paired)
+        grouped = input.mapPartitions(prepare).toPair().groupByKey()
+
+        info = None
+        if count:
+            grouped.cache()
+            libCount = _libSource("RmDupOpticalCount").addParam("opts", _optionsToString(opts))
+            total = grouped.mapPartitions(libCount).reduce(_libSource("MapSumReduce"))
+            info = OpticalDupInfo(total.get(_OPTICAL_READS, 0), total.get(_OPTICAL_OPTICAL, 0),
+                                  total.get(_OPTICAL_PCR, 0))
+
+        result = grouped.flatmap(_libSource("RmDupOptical").addParam("opts", _optionsToString(opts)))
+        if paired:
+            result = result.map(_libSource("RmDupOpticalSplit"))
+        return result, info
+
+    def _report(self, input: IDataFrame, **kwargs):
+        opts, grouped = self._group(input, **kwargs)
+
+        # the partitions return the report lines and their histogram from the same grouped records
+        libReport = _libSource("RmDupReport").addParam("opts", _optionsToString(opts))
+        tagged = grouped.mapPartitions(libReport)
+        tagged.cache()
+
+        histogram = {}
+        for partition in unpairedId(tagged, "histogram").collect():
+            for level, n in json.loads(partition).items():
+                histogram[int(level)] = histogram.get(int(level), 0) + n
+        return unpairedId(tagged, "report"), histogram
+
 class RmDupOptions:
 
     def __init__(self):
@@ -58,6 +123,11 @@ class RmDupOptions:
         self.DupSeqsFile = None  # string
         self.DupNumFile = None  # string
         self.OnlyPositiveStrand = None  # bool
+        self.Optical = None  # bool
+        self.IlluminaRegexp = None  # string
+        self.PixelDistance = None  # int
+        self.PrefixLength = None  # int
+        self.RemoveClass = None  # string
 
     def setDefaults(self):
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
@@ -67,9 +137,70 @@ class RmDupOptions:
         _setDefault(self, "DupSeqsFile", "")
         _setDefault(self, "DupNumFile", "")
         _setDefault(self, "OnlyPositiveStrand", False)
+        _setDefault(self, "Optical", False)
+        _setDefault(self, "IlluminaRegexp", defaultIlluminaRegexp)
+        _setDefault(self, "PixelDistance", 100)
+        _setDefault(self, "PrefixLength", 0)
+        _setDefault(self, "RemoveClass", "all")
+
+    def validate(self):
+        self.Config.validate()
+        if self.BySeq and self.ByName:
+            raise OptionError("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
+        if self.OnlyPositiveStrand and not self.BySeq and not self.Optical:
+            raise OptionError("flag -s (--by-seq) needed when using -P (--only-positive-strand)", "OnlyPositiveStrand")
+        if self.Optical and self.ByName:
+            raise OptionError("flag -n (--by-name) is not allowed when detecting optical duplicates", "ByName")
+        if self.Optical and self.DupSeqsFile != "":
+            raise OptionError("flag -d (--dup-seqs-file) is not allowed when detecting optical duplicates",
+                              "DupSeqsFile")
+        if self.Optical and self.DupNumFile != "":
+            raise OptionError("flag -D (--dup-num-file) is not allowed when detecting optical duplicates",
+                              "DupNumFile")
+        if self.PixelDistance < 0:
+            raise OptionError("value of flag --pixel-distance should be non-negative", "PixelDistance")
+        if self.PrefixLength < 0:
+            raise OptionError("value of flag --prefix-length should be non-negative", "PrefixLength")
+        if self.RemoveClass not in ("all", "optical", "pcr"):
+            raise OptionError("invalid value of flag --remove-class: " + self.RemoveClass +
+                              ". available values: 'all', 'optical', 'pcr'", "RemoveClass")
+        if _compile("IlluminaRegexp", self.IlluminaRegexp, "fail to compile regexp").groups != 4:
+            raise OptionError("illumina regexp must capture lane, tile, x and y: " + self.IlluminaRegexp, "IlluminaRegexp")
+
+
+class OpticalDupInfo:
+
+    def __init__(self, reads, optical, pcr):
+        self.reads = reads
+        self.optical = optical
+        self.pcr = pcr
+
+    def opticalRate(self):
+        return self.optical / self.reads if self.reads > 0 else 0.0
+
+    def pcrRate(self):
+        return self.pcr / self.reads if self.reads > 0 else 0.0
 
 
 def rmDup(input: IDataFrame, o: SeqKitRmDupOptions = None, **kwargs):
     if o is None:
         o = SeqKitRmDupOptions()
     return o._run(input, **kwargs)
+
+
+def dupReport(input: IDataFrame, o: SeqKitRmDupOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitRmDupOptions()
+    return o._report(input, **kwargs)
+
+
+def rmDupOptical(input: IDataFrame, o: SeqKitRmDupOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitRmDupOptions()
+    return o._optical(input, False, True, **kwargs)
+
+
+def rmDupOpticalPairs(pairs: IDataFrame, o: SeqKitRmDupOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitRmDupOptions()
+    return o._optical(pairs, True, True, **kwargs)
@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitSampleOptions:
@@ -18,19 +18,14 @@ class SeqKitSampleOptions:
     def proportion(self, v: float):
         self.__inner.Proportion = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
-
-        if opts.Number == 0 and opts.Proportion == 0:
-            raise RuntimeError("one of flags -n (--number) and -p (--proportion) needed")
-
-        if opts.Number < 0:
-            raise RuntimeError("value of -n (--number) and should be greater than 0")
-
-        if opts.Proportion < 0 or opts.Proportion > 1:
-            raise RuntimeError(f"value of -p (--proportion) ({str(opts.Proportion)}) should be in range of (0, 1]")
+        opts.validate()
 
         fraction = opts.Proportion
         if opts.Number > 0:
@@ -53,6 +48,16 @@ class SampleOptions:
         _setDefault(self, "Number", 0)
         _setDefault(self, "Proportion", 0)
 
+    def validate(self):
+        self.Config.validate()
+        if self.Number == 0 and self.Proportion == 0:
+            raise OptionError("one of flags -n (--number) and -p (--proportion) needed")
+        if self.Number < 0:
+            raise OptionError("value of -n (--number) and should be greater than 0", "Number")
+        if self.Proportion < 0 or self.Proportion > 1:
+            raise OptionError(f"value of -p (--proportion) ({str(self.Proportion)}) should be in range of (0, 1]",
+                              "Proportion")
+
 
 def sample(input: IDataFrame, o: SeqKitSampleOptions = None, **kwargs):
     if o is None:
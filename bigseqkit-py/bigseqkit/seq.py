@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitSeqOptions:
@@ -66,10 +66,14 @@ class SeqKitSeqOptions:
     def MaxQual(self, v: float):
         self.__inner.MaxQual = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
         libprepare = _libSource("SeqTransform").addParam("opts", _optionsToString(opts))
         return input.mapPartitions(libprepare)
 
@@ -122,6 +126,23 @@ class SeqOptions:
 
         return self
 
+    def validate(self):
+        self.Config.validate()
+        if self.GapLetters == "":
+            raise OptionError("value of flag -G (--gap-letters) should not be empty", "GapLetters")
+        if not self.GapLetters.isascii():
+            raise OptionError("value of -G (--gap-letters) contains non-ASCII characters", "GapLetters")
+        if 0 <= self.MaxLen < self.MinLen:
+            raise OptionError("value of flag -m (--min-len) should be <= value of flag -M (--max-len)", "MinLen")
+        if self.ValidateSeqLength < 0 or 0 < self.ValidateSeqLength < 1000:
+            raise OptionError("value of flag -V (--validate-seq-length) should be 0 or >= 1000", "ValidateSeqLength")
+        if self.QualAsciiBase <= 0:
+            raise OptionError("value of flag -b (--qual-ascii-base) should be greater than 0", "QualAsciiBase")
+        if 0 <= self.MaxQual < self.MinQual:
+            raise OptionError("value of flag -Q (--min-qual) should be <= value of flag -R (--max-qual)", "MinQual")
+        if self.LowerCase and self.UpperCase:
+            raise OptionError("could not give both flags -l (--lower-case) and -u (--upper-case)")
+
 
 def seq(input: IDataFrame, o: SeqKitSeqOptions = None, **kwargs):
     if o is None:
@@ -0,0 +1,87 @@
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+
+
+class SeqKitSlidingOptions:
+
+    def __init__(self):
+        self.__inner = SlidingOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def step(self, v: int):
+        self.__inner.Step = v
+
+    def window(self, v: int):
+        self.__inner.Window = v
+
+    def circular(self, v: bool):
+        self.__inner.Circular = v
+
+    def greedy(self, v: bool):
+        self.__inner.Greedy = v
+
+    def suffix(self, v: str):
+        self.__inner.Suffix = v
+
+    def stats(self, v: bool):
+        self.__inner.Stats = v
+
+    def splitLen(self, v: int):
+        self.__inner.SplitLen = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _run(self, input: IDataFrame, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+
+        libSplit = _libSource("SlidingSplit").addParam("opts", _optionsToString(opts))
+        chunks = input.mapPartitions(libSplit)
+
+        if opts.SplitLen > 0:
+            chunks = chunks.repartition(chunks.partitions(), True, True)
+
+        libSliding = _libSource("Sliding").addParam("opts", _optionsToString(opts))
+        return chunks.mapPartitions(libSliding)
+
+
+class SlidingOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.Step = None  # int
+        self.Window = None  # int
+        self.Circular = None  # bool
+        self.Greedy = None  # bool
+        self.Suffix = None  # str
+        self.Stats = None  # bool
+        self.SplitLen = None  # int
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "Step", 0)
+        _setDefault(self, "Window", 0)
+        _setDefault(self, "Circular", False)
+        _setDefault(self, "Greedy", False)
+        _setDefault(self, "Suffix", "_sliding")
+        _setDefault(self, "Stats", False)
+        _setDefault(self, "SplitLen", 1000000)
+
+    def validate(self):
+        self.Config.validate()
+        if self.Step <= 0:
+            raise OptionError("value of flag -s (--step) should be greater than 0", "Step")
+        if self.Window <= 0:
+            raise OptionError("value of flag -W (--window) should be greater than 0", "Window")
+        if self.SplitLen < 0:
+            raise OptionError("value of flag --split-len should not be negative", "SplitLen")
+
+
+def sliding(input: IDataFrame, o: SeqKitSlidingOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitSlidingOptions()
+    return o._run(input, **kwargs)
@@ -1,4 +1,4 @@
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
 
 
 class SeqKitSortOptions:
@@ -33,14 +33,17 @@ class SeqKitSortOptions:
     def seqPrefixLength(self, v: int):
         self.__inner.SeqPrefixLength = v
 
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
 
         inNaturalOrder = opts.InNaturalOrder
         bySeq = opts.BySeq
-        byName = opts.ByName
         byLength = opts.ByLength
         byBases = opts.ByBases
         reverse = opts.Reverse
@@ -49,19 +52,6 @@ class SeqKitSortOptions:
             byLength = True
             opts.ByLength = True
 
-        n = 0
-        if bySeq:
-            n+=1
-
-        if byName:
-            n+=1
-
-        if byLength:
-            n+=1
-
-        if n > 1:
-            raise RuntimeError("only one of the options (byLength), (byName) and (bySeq) is allowed")
-
         if byLength:
             parser = _libSource("SortParseInputInt").addParam("opts", _optionsToString(opts))
             conv = input.mapPartitions(parser)
@@ -103,6 +93,11 @@ class SortOptions:
         _setDefault(self, "IgnoreCase", False)
         _setDefault(self, "SeqPrefixLength", 10000)
 
+    def validate(self):
+        self.Config.validate()
+        if [self.BySeq, self.ByName, self.ByLength or self.ByBases].count(True) > 1:
+            raise OptionError("only one of the options (byLength), (byName) and (bySeq) is allowed")
+
 
 def sort(input: IDataFrame, o: SeqKitSortOptions = None, **kwargs):
     if o is None:
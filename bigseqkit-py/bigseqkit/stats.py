@@ -0,0 +1,277 @@
+from dataclasses import dataclass
+
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, OptionError, SeqKitConfig, IDataFrame
+
+_Q20 = -1
+_Q30 = -2
+_GAP_SUM = -3
+_T = -4
+
+_DNA = set("acgtACGT -.nN")
+_RNA = set("acguACGU -.nN")
+_DNA_REDUNDANT = set("acgtryswkmbdhvACGTRYSWKMBDHV -.nN")
+_RNA_REDUNDANT = set("acguryswkmbdhvACGURYSWKMBDHV -.nN")
+_PROTEIN = set("abcdefghijklmnopqrstuvwyzABCDEFGHIJKLMNOPQRSTUVWYZ -xX*_.")
+
+
+class SeqKitStatsOptions:
+
+    def __init__(self):
+        self.__inner = StatsOptions()
+
+    def config(self, v: SeqKitConfig):
+        self.__inner.Config = _config(v)
+
+    def tabular(self, v: bool):
+        self.__inner.Tabular = v
+
+    def gapLetters(self, v: str):
+        self.__inner.GapLetters = v
+
+    def all(self, v: bool):
+        self.__inner.All = v
+
+    def skipErr(self, v: bool):
+        self.__inner.SkipErr = v
+
+    def fqEncoding(self, v: str):
+        self.__inner.FqEncoding = v
+
+    def basename(self, v: bool):
+        self.__inner.Basename = v
+
+    def validate(self):
+        _validate(self.__inner)
+
+    def _prepare(self, kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+        return opts
+
+    def _run(self, name: str, format: str, input: IDataFrame, **kwargs):
+        opts = self._prepare(kwargs)
+
+        libprepare = _libSource("Stats").addParam("opts", _optionsToString(opts))
+        stats = dict(input.mapPartitions(libprepare).reduce(_libSource("StatsReduce")))
+
+        q20 = stats.pop(_Q20, 0)
+        q30 = stats.pop(_Q30, 0)
+        gapSum = stats.pop(_GAP_SUM, 0)
+        ti = stats.pop(_T, 0)
+        if ti == ord('D'):
+            t = "DNA"
+        elif ti == ord('R'):
+            t = "RNA"
+        elif ti == ord('U'):
+            t = ""
+        else:
+            t = _guessAlphabet(input.take(1)[0], opts.Config.AlphabetGuessSeqLength)
+
+        info = StatInfo(name, format, t)
+        lens = _LengthStats(stats)
+        if lens.count > 0:
+            info.numSeqs = lens.count
+            info.sumLen = lens.sum
+            info.sumGap = gapSum
+            info.minLen = lens.min
+            info.avgLen = round(lens.sum / lens.count, 1)
+            info.maxLen = lens.max
+            if opts.All:
+                info.N50, info.L50 = lens.n50()
+                info.Q1, info.Q2, info.Q3 = lens.q1(), lens.q2(), lens.q3()
+            info.Q20 = round(q20 / lens.sum * 100, 2)
+            info.Q30 = round(q30 / lens.sum * 100, 2)
+        return info
+
+    def _string(self, name: str, format: str, input: IDataFrame, **kwargs):
+        info = self._run(name, format, input, **kwargs)
+        opts = self.__inner
+
+        columns = ["file", "format", "type", "num_seqs", "sum_len", "min_len", "avg_len", "max_len"]
+        values = [info.file, info.format, info.type, info.numSeqs, info.sumLen, info.minLen, info.avgLen,
+                  info.maxLen]
+        if opts.All:
+            columns += ["Q1", "Q2", "Q3", "sum_gap", "N50", "Q20(%)", "Q30(%)"]
+            values += [info.Q1, info.Q2, info.Q3, info.sumGap, info.N50, info.Q20, info.Q30]
+
+        if opts.Tabular:
+            formats = ["%s", "%s", "%s", "%d", "%d", "%d", "%.1f", "%d", "%.1f", "%.1f", "%.1f", "%d", "%d", "%.2f",
+                       "%.2f"]
+            return "\t".join(columns) + "\n" + "\t".join(f % v for f, v in zip(formats, values)) + "\n"
+
+        cells = [v if i < 3 else (_commaf(v) if isinstance(v, float) else "{:,}".format(v))
+                 for i, v in enumerate(values)]
+        rows = [columns, cells]
+        widths = [max(len(row[i]) for row in rows) for i in range(len(columns))]
+        lines = []
+        for row in rows:
+            line = []
+            for i, s in enumerate(row):
+                if i >= 3:
+                    line.append(s.rjust(widths[i]))
+                elif i < len(row) - 1:
+                    line.append(s.ljust(widths[i]))
+                else:
+                    line.append(s)
+            lines.append(" ".join(line) + "\n")
+        return "".join(lines)
+
+
+class StatsOptions:
+
+    def __init__(self):
+        self.Config = None  # KitConfig
+        self.Tabular = None  # bool
+        self.GapLetters = None  # str
+        self.All = None  # bool
+        self.SkipErr = None  # bool
+        self.FqEncoding = None  # str
+        self.Basename = None  # bool
+
+    def setDefaults(self):
+        _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
+        _setDefault(self, "Tabular", False)
+        _setDefault(self, "GapLetters", "- .")
+        _setDefault(self, "All", False)
+        _setDefault(self, "SkipErr", False)
+        _setDefault(self, "FqEncoding", "sanger")
+        _setDefault(self, "Basename", False)
+
+    def validate(self):
+        self.Config.validate()
+        if len(self.GapLetters) == 0:
+            raise OptionError("value of flag -G (--gap-letters) should not be empty", "GapLetters")
+        if any(ord(c) > 127 for c in self.GapLetters):
+            raise OptionError("value of -G (--gap-letters) contains non-ASCII characters", "GapLetters")
+        if self.FqEncoding.lower() not in ("", "sanger", "solexa", "illumina-1.3+", "illumina-1.5+", "illumina-1.8+"):
+            raise OptionError("unsupported quality encoding: " + self.FqEncoding + ". available values: 'sanger', "
+                              "'solexa', 'illumina-1.3+', 'illumina-1.5+', 'illumina-1.8+'", "FqEncoding")
+
+
+@dataclass
+class StatInfo:
+    """Statistics of the sequences, the N50, L50 and quartiles are computed only with the option All"""
+    file: str
+    format: str
+    type: str
+    numSeqs: int = 0
+    sumLen: int = 0
+    sumGap: int = 0
+    minLen: int = 0
+    avgLen: float = 0.0
+    maxLen: int = 0
+    N50: int = 0
+    L50: int = 0
+    Q1: float = 0.0
+    Q2: float = 0.0
+    Q3: float = 0.0
+    Q20: float = 0.0
+    Q30: float = 0.0
+
+
+class _LengthStats:
+    """Port of the LengthStats of github.com/shenwei356/bio/util from a histogram of lengths"""
+
+    def __init__(self, lens):
+        self.counts = sorted((k, v) for k, v in lens.items() if v > 0)
+        self.count = sum(v for _, v in self.counts)
+        self.sum = sum(k * v for k, v in self.counts)
+        self.min = self.counts[0][0] if self.counts else 0
+        self.max = self.counts[-1][0] if self.counts else 0
+        self.acc = []
+        for k, v in self.counts:
+            self.acc.append((k, v + (self.acc[-1][1] if self.acc else 0)))
+
+    def __value(self, even, iL, iR):
+        flag, prev = False, 0
+        for k, acc in self.acc:
+            if flag:
+                return (k + prev) / 2
+            if acc >= iL + 1:
+                if not even:
+                    return float(k)
+                if acc >= iR + 1:
+                    return float(k)
+                flag, prev = True, k
+        return 0.0
+
+    def __quartile(self, offset, n):
+        even = n % 2 == 0
+        if even:
+            return self.__value(True, n // 2 - 1 + offset, n // 2 + offset)
+        return self.__value(False, n // 2 + offset, 0)
+
+    def q1(self):
+        if len(self.counts) <= 1:
+            return self.counts[0][0] / 2 if self.counts else 0.0
+        n = self.count // 2 if self.count % 2 == 0 else (self.count + 1) // 2
+        return self.__quartile(0, n)
+
+    def q2(self):
+        if len(self.counts) <= 1:
+            return float(self.counts[0][0]) if self.counts else 0.0
+        return self.__quartile(0, self.count)
+
+    def q3(self):
+        if len(self.counts) <= 1:
+            return self.counts[0][0] / 2 if self.counts else 0.0
+        if self.count % 2 == 0:
+            n = self.count // 2
+            return self.__quartile(n, n)
+        return self.__quartile(self.count // 2, (self.count + 1) // 2)
+
+    def n50(self):
+        """returns N50 and L50"""
+        if len(self.counts) == 0:
+            return 0, 0
+        if len(self.counts) == 1:
+            return self.counts[0][0], 0
+        s = 0
+        for i in range(len(self.counts) - 1, -1, -1):
+            s += self.counts[i][0] * self.counts[i][1]
+            if s >= self.sum / 2:
+                return self.counts[i][0], i + 1
+        return 0, 0
+
+
+def _guessAlphabet(record, guessLength):
+    """type of the first sequence, as the readers of the executors guess it"""
+    lines = record.split("\n")
+    if record.startswith("@"):
+        seq = lines[1] if len(lines) > 1 else ""
+    else:
+        seq = "".join(lines[1:])
+    if guessLength > 0:
+        seq = seq[:guessLength]
+    letters = set(seq)
+    if len(letters) == 0:
+        return "Unlimit"
+    for alphabet, t in ((_DNA, "DNA"), (_RNA, "RNA"), (_DNA_REDUNDANT, "DNA"), (_RNA_REDUNDANT, "RNA"),
+                        (_PROTEIN, "Protein")):
+        if letters <= alphabet:
+            return t
+    return "Unlimit"
+
+
+def _commaf(v):
+    """format of humanize.Commaf, the shortest representation with thousands separators"""
+    s = repr(float(v))
+    if "e" in s:
+        s = "%f" % v
+    i, _, f = s.partition(".")
+    i = ("-" if i.startswith("-") else "") + format(abs(int(i)), ",")
+    return i if f in ("", "0") else i + "." + f
+
+
+def stats(name: str, format: str, input: IDataFrame, o: SeqKitStatsOptions = None, **kwargs) -> StatInfo:
+    if o is None:
+        o = SeqKitStatsOptions()
+    return o._run(name, format, input, **kwargs)
+
+
+def statsString(name: str, format: str, input: IDataFrame, o: SeqKitStatsOptions = None, **kwargs) -> str:
+    if o is None:
+        o = SeqKitStatsOptions()
+    return o._string(name, format, input, **kwargs)
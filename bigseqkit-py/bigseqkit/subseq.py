@@ -1,6 +1,7 @@
 from typing import List
 
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, _validateRegion, OptionError, SeqKitConfig, IDataFrame
+from bigseqkit.codon_usage import _CODON_TABLES
 
 
 class SeqKitSubseqOptions:
@@ -27,7 +28,7 @@ class SeqKitSubseqOptions:
         self.__inner.UpStream = v
 
     def onlyFlank(self, v: bool):
-        self.__inner.OnlyFlankOnlyFlank = v
+        self.__inner.OnlyFlank = v
 
     def bed(self, v: str):
         self.__inner.Bed = v
@@ -35,13 +36,61 @@ class SeqKitSubseqOptions:
     def gtfTag(self, v: str):
         self.__inner.GtfTag = v
 
+    def gff(self, v: str):
+        self.__inner.Gff = v
+
+    def attribute(self, v: List[str]):
+        self.__inner.Attribute = v
+
+    def extract(self, v: str):
+        self.__inner.Extract = v
+
+    def groupBy(self, v: str):
+        self.__inner.GroupBy = v
+
+    def translTable(self, v: int):
+        self.__inner.TranslTable = v
+
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
         libprepare = _libSource("SubseqTransform").addParam("opts", _optionsToString(opts))
         return input.mapPartitions(libprepare)
 
+    def _join(self, input: IDataFrame, annotation: IDataFrame, format: str, **kwargs):
+        opts = self.__inner
+        _parseKargs(opts, kwargs)
+        opts.setDefaults()
+        opts.validate()
+
+        if format not in ("gtf", "gff", "bed"):
+            raise RuntimeError("invalid annotation format: " + format + ". available values: 'gtf', 'gff', 'bed'")
+        if opts.Region != "":
+            raise RuntimeError("flag -r (--region) is not allowed with an annotation")
+        opts.Gtf, opts.Gff, opts.Bed = "", "", ""
+
+        libFeatures = _libSource("SubseqKeyFeatures").addParam("opts", _optionsToString(opts)).addParam("format", format)
+        features = annotation.mapPartitions(libFeatures)
+        features.cache()
+
+        libReach = _libSource("SubseqReach").addParam("opts", _optionsToString(opts)).addParam("format", format)
+        reach = features.mapPartitions(libReach).reduce(_libSource("SubseqReachReduce"))
+
+        libRecords = _libSource("SubseqKeyRecords")\
+            .addParam("opts", _optionsToString(opts))\
+            .addParam("reach", _optionsToString(reach))
+        records = input.mapPartitions(libRecords)
+
+        grouped = records.union(features, preserveOrder=False).toPair().groupByKey()
+
+        join = _libSource("SubseqJoin").addParam("opts", _optionsToString(opts)).addParam("format", format)
+        return grouped.flatmap(join)
+
 
 class SubseqOptions:
 
@@ -56,6 +105,11 @@ class SubseqOptions:
         self.OnlyFlank = None  # bool
         self.Bed = None  # str
         self.GtfTag = None  # str
+        self.Gff = None  # str
+        self.Attribute = None  # list[str]
+        self.Extract = None  # str
+        self.GroupBy = None  # str
+        self.TranslTable = None  # int
 
     def setDefaults(self):
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
@@ -68,9 +122,57 @@ class SubseqOptions:
         _setDefault(self, "OnlyFlank", False)
         _setDefault(self, "Bed", "")
         _setDefault(self, "GtfTag", "")
+        _setDefault(self, "Gff", "")
+        _setDefault(self, "Attribute", [])
+        _setDefault(self, "Extract", "")
+        _setDefault(self, "GroupBy", "transcript")
+        _setDefault(self, "TranslTable", 1)
+
+    def validate(self):
+        self.Config.validate()
+        if self.OnlyFlank:
+            if self.UpStream > 0 and self.DownStream > 0:
+                raise OptionError("when flag -f (--only-flank) given, "
+                                  "only one of flags -u (--up-stream) and -d (--down-stream) is allowed", "OnlyFlank")
+            elif self.UpStream == 0 and self.DownStream == 0:
+                raise OptionError("when flag -f (--only-flank) given, "
+                                  "one of flags -u (--up-stream) and -d (--down-stream) should be given", "OnlyFlank")
+        if self.UpStream < 0:
+            raise OptionError("value of flag -u (--up-stream) should not be negative", "UpStream")
+        if self.DownStream < 0:
+            raise OptionError("value of flag -d (--down-stream) should not be negative", "DownStream")
+        if self.Region != "":
+            if self.UpStream > 0 or self.DownStream > 0 or self.OnlyFlank:
+                raise OptionError("when flag -r (--region) given, "
+                                  "any of flags -u (--up-stream), -d (--down-stream) and -f (--only-flank) is not allowed",
+                                  "Region")
+            _validateRegion("subseq", self.Region)
+        if self.Extract in ("transcript", "cds", "protein"):
+            if self.Gtf != "" or self.Region != "":
+                raise OptionError("flag --extract only works with --gff or --bed", "Extract")
+            if self.UpStream > 0 or self.DownStream > 0 or self.OnlyFlank:
+                raise OptionError("when flag --extract given, "
+                                  "any of flags -u (--up-stream), -d (--down-stream) and -f (--only-flank) is not allowed",
+                                  "Extract")
+        elif self.Extract != "":
+            raise OptionError("invalid value of flag --extract: " + self.Extract +
+                              ". available values: 'transcript', 'cds', 'protein'", "Extract")
+        if self.GroupBy not in ("transcript", "gene"):
+            raise OptionError("invalid value of flag --group-by: " + self.GroupBy +
+                              ". available values: 'transcript', 'gene'", "GroupBy")
+        if self.TranslTable not in _CODON_TABLES:
+            raise OptionError("invalid translate table: " + str(self.TranslTable), "TranslTable")
+        if self.Bed != "" and len(self.Feature) > 0:
+            raise OptionError("when given flag -b (--bed), flag -f (--feature) is not allowed", "Feature")
 
 
 def subSeq(input: IDataFrame, o: SeqKitSubseqOptions = None, **kwargs):
     if o is None:
         o = SeqKitSubseqOptions()
     return o._run(input, **kwargs)
+
+
+def subSeqJoin(input: IDataFrame, annotation: IDataFrame, format: str, o: SeqKitSubseqOptions = None, **kwargs):
+    if o is None:
+        o = SeqKitSubseqOptions()
+    return o._join(input, annotation, format, **kwargs)
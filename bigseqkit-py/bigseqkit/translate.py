@@ -1,6 +1,7 @@
 from typing import List
 
-from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, SeqKitConfig, IDataFrame
+from bigseqkit.helper import _setDefault, _libSource, _config, _optionsToString, _parseKargs, _validate, _compile, OptionError, SeqKitConfig, IDataFrame
+from bigseqkit.codon_usage import _CODON_TABLES
 
 
 class SeqKitTranslateOptions:
@@ -38,10 +39,23 @@ class SeqKitTranslateOptions:
     def appendFrame(self, v: bool):
         self.__inner.AppendFrame = v
 
+    def translTableFrom(self, v: str):
+        self.__inner.TranslTableFrom = v
+
+    def translTableRegexp(self, v: str):
+        self.__inner.TranslTableRegexp = v
+
+    def codonTableFile(self, v: str):
+        self.__inner.CodonTableFile = v
+
+    def validate(self):
+        _validate(self.__inner)
+
     def _run(self, input: IDataFrame, **kwargs):
         opts = self.__inner
         _parseKargs(opts, kwargs)
         opts.setDefaults()
+        opts.validate()
         libprepare = _libSource("Translate").addParam("opts", _optionsToString(opts))
         return input.mapPartitions(libprepare)
 
@@ -59,6 +73,9 @@ class TranslateOptions:
         self.ListTranslTable = None  # int
         self.ListTranslTableWithAmbCodons = None  # int
         self.AppendFrame = None  # bool
+        self.TranslTableFrom = None  # str
+        self.TranslTableRegexp = None  # str
+        self.CodonTableFile = None  # str
 
     def setDefaults(self):
         _setDefault(self, "Config", _config(SeqKitConfig())).setDefaults()
@@ -71,6 +88,27 @@ class TranslateOptions:
         _setDefault(self, "ListTranslTable", -1)
         _setDefault(self, "ListTranslTableWithAmbCodons", -1)
         _setDefault(self, "AppendFrame", False)
+        _setDefault(self, "TranslTableFrom", "")
+        _setDefault(self, "TranslTableRegexp", r"transl_table=(\d+)")
+        _setDefault(self, "CodonTableFile", "")
+
+    def validate(self):
+        self.Config.validate()
+        if self.CodonTableFile == "" and self.TranslTable not in _CODON_TABLES:
+            # tables of the codon table file are only known by the executors
+            raise OptionError("invalid translate table: " + str(self.TranslTable), "TranslTable")
+        if self.TranslTableFrom == "header":
+            if _compile("TranslTableRegexp", self.TranslTableRegexp,
+                        "invalid translate table regular expression").groups < 1:
+                raise OptionError("translate table regular expression must contains \"(\" and \")\" to capture the table: " +
+                                  self.TranslTableRegexp, "TranslTableRegexp")
+        _validateFrames(self.Frame)
+
+
+def _validateFrames(frames):
+    for frame in frames:
+        if frame not in ("1", "2", "3", "-1", "-2", "-3", "6"):
+            raise OptionError("invalid frame: " + frame + ". available: 1, 2, 3, -1, -2, -3, and 6 for all", "Frame")
 
 
 def translate(input: IDataFrame, o: SeqKitTranslateOptions = None, **kwargs):
@@ -5,5 +5,6 @@ setup(
 	version='1.0',
 	description='bigseqkit python library',
 	packages=find_packages(),
-	install_requires=['ignis-core']
+	install_requires=['ignis-core'],
+	extras_require={'pandas': ['pandas'], 'arrow': ['pyarrow']}
 )
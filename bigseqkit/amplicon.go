@@ -0,0 +1,161 @@
+package bigseqkit
+
+import (
+	"ignis/driver/api"
+	"strings"
+)
+
+type SeqKitAmpliconOptions struct {
+	inner AmpliconOptions
+}
+
+type AmpliconOptions struct {
+	Config             KitConfig
+	Forward            *string
+	Reverse            *string
+	PrimerFile         *string
+	MaxMismatch        *int
+	OnlyPositiveStrand *bool
+	OnlyInner          *bool
+	Bed                *bool
+	MinLen             *int
+	MaxLen             *int
+	Circular           *bool
+}
+
+func (this *AmpliconOptions) setDefaults() *AmpliconOptions {
+	this.Config.setDefaults()
+	setDefault(&this.Forward, "")
+	setDefault(&this.Reverse, "")
+	setDefault(&this.PrimerFile, "")
+	setDefault(&this.MaxMismatch, 0)
+	setDefault(&this.OnlyPositiveStrand, false)
+	setDefault(&this.OnlyInner, false)
+	setDefault(&this.Bed, false)
+	setDefault(&this.MinLen, 0)
+	setDefault(&this.MaxLen, 0)
+	setDefault(&this.Circular, false)
+
+	return this
+}
+
+func (this *AmpliconOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.PrimerFile == "" && (*this.Forward == "" || *this.Reverse == "") {
+		return OptionErrorf("flags -F (--forward) and -R (--reverse), or -p (--primer-file) needed")
+	}
+	if *this.MaxMismatch < 0 {
+		return optionError("MaxMismatch", "value of flag -m (--max-mismatch) should not be negative")
+	}
+	if *this.MaxMismatch > 0 {
+		for field, primer := range map[string]string{"Forward": *this.Forward, "Reverse": *this.Reverse} {
+			if degeneratePrimer(primer) {
+				return optionError(field, "degenerate primer not allowed when giving flag -m (--max-mismatch): %s", primer)
+			}
+		}
+	}
+	if *this.MinLen < 0 || *this.MaxLen < 0 {
+		return optionError("MinLen", "values of flags --min-len and --max-len should not be negative")
+	}
+	if *this.MaxLen > 0 && *this.MaxLen < *this.MinLen {
+		return optionError("MaxLen", "value of flag --max-len should not be lower than --min-len")
+	}
+	return nil
+}
+
+// degeneratePrimer reports whether a primer has bases other than A, C, G, T and U.
+func degeneratePrimer(primer string) bool {
+	return strings.IndexFunc(strings.ToUpper(primer), func(r rune) bool {
+		return r != 'A' && r != 'C' && r != 'G' && r != 'T' && r != 'U'
+	}) >= 0
+}
+
+func (this *SeqKitAmpliconOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitAmpliconOptions) Config(v *SeqKitConfig) *SeqKitAmpliconOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+// Forward sets the forward primer (5'-3'), IUPAC degenerate bases are allowed.
+func (this *SeqKitAmpliconOptions) Forward(v string) *SeqKitAmpliconOptions {
+	this.inner.Forward = &v
+	return this
+}
+
+// Reverse sets the reverse primer (5'-3', on the reverse strand), IUPAC degenerate bases are allowed.
+func (this *SeqKitAmpliconOptions) Reverse(v string) *SeqKitAmpliconOptions {
+	this.inner.Reverse = &v
+	return this
+}
+
+// PrimerFile sets a tab-delimited file of primer pairs: name, forward primer and reverse primer.
+func (this *SeqKitAmpliconOptions) PrimerFile(v string) *SeqKitAmpliconOptions {
+	this.inner.PrimerFile = &v
+	return this
+}
+
+// MaxMismatch sets the maximum number of mismatches of every primer, only primers without degenerate bases
+// are allowed.
+func (this *SeqKitAmpliconOptions) MaxMismatch(v int) *SeqKitAmpliconOptions {
+	this.inner.MaxMismatch = &v
+	return this
+}
+
+func (this *SeqKitAmpliconOptions) OnlyPositiveStrand(v bool) *SeqKitAmpliconOptions {
+	this.inner.OnlyPositiveStrand = &v
+	return this
+}
+
+// OnlyInner outputs the region between the primers instead of the whole product.
+func (this *SeqKitAmpliconOptions) OnlyInner(v bool) *SeqKitAmpliconOptions {
+	this.inner.OnlyInner = &v
+	return this
+}
+
+// Bed outputs the coordinates of the amplicons in BED6 format, the number of mismatches is the score.
+func (this *SeqKitAmpliconOptions) Bed(v bool) *SeqKitAmpliconOptions {
+	this.inner.Bed = &v
+	return this
+}
+
+// MinLen sets the minimum product size, primers included.
+func (this *SeqKitAmpliconOptions) MinLen(v int) *SeqKitAmpliconOptions {
+	this.inner.MinLen = &v
+	return this
+}
+
+// MaxLen sets the maximum product size, primers included. 0 for no limit.
+func (this *SeqKitAmpliconOptions) MaxLen(v int) *SeqKitAmpliconOptions {
+	this.inner.MaxLen = &v
+	return this
+}
+
+func (this *SeqKitAmpliconOptions) Circular(v bool) *SeqKitAmpliconOptions {
+	this.inner.Circular = &v
+	return this
+}
+
+// Amplicon retrieves the products of in-silico PCR with one or more primer pairs. Every forward primer hit
+// is paired with the nearest downstream hit of the reverse primer.
+func Amplicon(input *api.IDataFrame[string], o *SeqKitAmpliconOptions) (*api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitAmpliconOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libprepare, err := api.AddParam(libSource("Amplicon"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return api.MapPartitions[string, string](input, libprepare)
+}
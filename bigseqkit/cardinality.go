@@ -0,0 +1,172 @@
+package bigseqkit
+
+import (
+	"fmt"
+	"ignis/driver/api"
+	"math"
+)
+
+type SeqKitCardinalityOptions struct {
+	inner CardinalityOptions
+}
+
+type CardinalityOptions struct {
+	Config             KitConfig
+	ByName             *bool
+	BySeq              *bool
+	IgnoreCase         *bool
+	OnlyPositiveStrand *bool
+	Precision          *int
+}
+
+func (this *CardinalityOptions) setDefaults() *CardinalityOptions {
+	this.Config.setDefaults()
+	setDefault(&this.ByName, false)
+	setDefault(&this.BySeq, false)
+	setDefault(&this.IgnoreCase, false)
+	setDefault(&this.OnlyPositiveStrand, false)
+	setDefault(&this.Precision, 14)
+
+	return this
+}
+
+func (this *CardinalityOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.BySeq && *this.ByName {
+		return OptionErrorf("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
+	}
+	if *this.OnlyPositiveStrand && !*this.BySeq {
+		return optionError("OnlyPositiveStrand", "flag -s (--by-seq) needed when using -P (--only-positive-strand)")
+	}
+	if *this.Precision < 4 || *this.Precision > 18 {
+		return optionError("Precision", "value of flag -p (--precision) should be in range [4, 18]")
+	}
+	return nil
+}
+
+func (this *SeqKitCardinalityOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitCardinalityOptions) Config(v *SeqKitConfig) *SeqKitCardinalityOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+func (this *SeqKitCardinalityOptions) ByName(v bool) *SeqKitCardinalityOptions {
+	this.inner.ByName = &v
+	return this
+}
+
+func (this *SeqKitCardinalityOptions) BySeq(v bool) *SeqKitCardinalityOptions {
+	this.inner.BySeq = &v
+	return this
+}
+
+func (this *SeqKitCardinalityOptions) IgnoreCase(v bool) *SeqKitCardinalityOptions {
+	this.inner.IgnoreCase = &v
+	return this
+}
+
+func (this *SeqKitCardinalityOptions) OnlyPositiveStrand(v bool) *SeqKitCardinalityOptions {
+	this.inner.OnlyPositiveStrand = &v
+	return this
+}
+
+func (this *SeqKitCardinalityOptions) Precision(v int) *SeqKitCardinalityOptions {
+	this.inner.Precision = &v
+	return this
+}
+
+// CardinalityInfo holds the HyperLogLog estimation of the number of distinct keys.
+type CardinalityInfo struct {
+	Records  int64   // number of records
+	Distinct int64   // estimated number of distinct keys
+	StdError float64 // relative standard error of the estimation, 1.04/sqrt(2^precision)
+}
+
+// DuplicationRate returns the estimated fraction of records that are duplicates of a previous key.
+func (this *CardinalityInfo) DuplicationRate() float64 {
+	if this.Records == 0 || this.Distinct >= this.Records {
+		return 0
+	}
+	return 1 - float64(this.Distinct)/float64(this.Records)
+}
+
+// ErrorBound returns the absolute error of the distinct count for a confidence of 95% (two standard errors).
+func (this *CardinalityInfo) ErrorBound() int64 {
+	return int64(math.Ceil(2 * this.StdError * float64(this.Distinct)))
+}
+
+// Cardinality estimates the number of distinct records using the same keys as RmDup (ID, full name,
+// sequence or canonical sequence). Every partition builds a HyperLogLog sketch with 2^Precision
+// registers and the sketches are merged in a reduce, so the records are never shuffled.
+func Cardinality(input *api.IDataFrame[string], o *SeqKitCardinalityOptions) (*CardinalityInfo, error) {
+	if o == nil {
+		o = &SeqKitCardinalityOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libSketch, err := api.AddParam(libSource("CardinalitySketch"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	sketches, err := api.MapPartitions[string, []int64](input, libSketch)
+	if err != nil {
+		return nil, err
+	}
+
+	sketch, err := sketches.Reduce(libSource("CardinalityMerge"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := 1 << *opts.Precision
+	if len(sketch) != m+1 {
+		return nil, fmt.Errorf("invalid HyperLogLog sketch size: %d", len(sketch))
+	}
+
+	return &CardinalityInfo{
+		Records:  sketch[0],
+		Distinct: hllEstimate(sketch[1:]),
+		StdError: 1.04 / math.Sqrt(float64(m)),
+	}, nil
+}
+
+// hllEstimate returns the HyperLogLog estimation of the registers, with linear counting for small cardinalities.
+func hllEstimate(registers []int64) int64 {
+	m := float64(len(registers))
+	var alpha float64
+	switch len(registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+
+	sum := 0.0
+	zeros := 0
+	for _, r := range registers {
+		sum += math.Pow(2, -float64(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(estimate))
+}
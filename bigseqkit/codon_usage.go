@@ -0,0 +1,302 @@
+package bigseqkit
+
+import (
+	"fmt"
+	"github.com/shenwei356/bio/seq"
+	"ignis/driver/api"
+	"math"
+	"sort"
+)
+
+type SeqKitCodonUsageOptions struct {
+	inner CodonUsageOptions
+}
+
+type CodonUsageOptions struct {
+	Config      KitConfig
+	TranslTable *int
+}
+
+func (this *CodonUsageOptions) setDefaults() *CodonUsageOptions {
+	this.Config.setDefaults()
+	setDefault(&this.TranslTable, 1)
+
+	return this
+}
+
+func (this *CodonUsageOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if _, ok := seq.CodonTables[*this.TranslTable]; !ok {
+		return optionError("TranslTable", "invalid translate table: %d", *this.TranslTable)
+	}
+	return nil
+}
+
+func (this *SeqKitCodonUsageOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitCodonUsageOptions) Config(v *SeqKitConfig) *SeqKitCodonUsageOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+// TranslTable sets the genetic code that groups the synonymous codons for RSCU and ENC.
+func (this *SeqKitCodonUsageOptions) TranslTable(v int) *SeqKitCodonUsageOptions {
+	this.inner.TranslTable = &v
+	return this
+}
+
+// Keys of the codon usage counters that are not codon indexes.
+const (
+	CodonUsageRecords    = int64(-1) // number of records
+	CodonUsageAmbiguous  = int64(-2) // codons with ambiguous or invalid bases, not counted
+	CodonUsageIncomplete = int64(-3) // records with a length not multiple of 3
+)
+
+var codonBases = [4]byte{'T', 'C', 'A', 'G'}
+
+// CodonIndex returns the index (0-63, in the TCAG order of the NCBI tables) of a DNA or RNA codon,
+// or -1 if it has ambiguous or invalid bases.
+func CodonIndex(codon []byte) int {
+	if len(codon) != 3 {
+		return -1
+	}
+	idx := 0
+	for _, b := range codon {
+		switch b &^ 0x20 {
+		case 'T', 'U':
+			idx = idx * 4
+		case 'C':
+			idx = idx*4 + 1
+		case 'A':
+			idx = idx*4 + 2
+		case 'G':
+			idx = idx*4 + 3
+		default:
+			return -1
+		}
+	}
+	return idx
+}
+
+// CodonString returns the codon of an index of CodonIndex.
+func CodonString(idx int) string {
+	return string([]byte{codonBases[idx/16], codonBases[idx/4%4], codonBases[idx%4]})
+}
+
+// CodonUsageInfo holds the codon counts of a record or a set of records.
+type CodonUsageInfo struct {
+	Records     int64     // number of records
+	Incomplete  int64     // records with a length not multiple of 3
+	Ambiguous   int64     // codons with ambiguous bases, not counted
+	Counts      [64]int64 // codon counts, indexed by CodonIndex
+	TranslTable int
+	aminoAcids  [64]byte
+}
+
+// NewCodonUsageInfo builds the codon usage of the counters computed by the executors.
+func NewCodonUsageInfo(counts map[int64]int64, translTable int) (*CodonUsageInfo, error) {
+	table, ok := seq.CodonTables[translTable]
+	if !ok {
+		return nil, fmt.Errorf("invalid translate table: %d", translTable)
+	}
+	info := &CodonUsageInfo{
+		Records:     counts[CodonUsageRecords],
+		Incomplete:  counts[CodonUsageIncomplete],
+		Ambiguous:   counts[CodonUsageAmbiguous],
+		TranslTable: translTable,
+	}
+	for i := 0; i < 64; i++ {
+		info.Counts[i] = counts[int64(i)]
+		info.aminoAcids[i], _ = table.Get([]byte(CodonString(i)), false)
+	}
+	return info, nil
+}
+
+// AminoAcid returns the amino acid of a codon index in the translate table, '*' for stop codons.
+func (this *CodonUsageInfo) AminoAcid(idx int) byte {
+	return this.aminoAcids[idx]
+}
+
+// Codons returns the number of sense codons.
+func (this *CodonUsageInfo) Codons() int64 {
+	n := int64(0)
+	for i, c := range this.Counts {
+		if this.aminoAcids[i] != '*' {
+			n += c
+		}
+	}
+	return n
+}
+
+// synonymous groups the sense codon indexes by amino acid.
+func (this *CodonUsageInfo) synonymous() map[byte][]int {
+	groups := make(map[byte][]int, 21)
+	for i := 0; i < 64; i++ {
+		if aa := this.aminoAcids[i]; aa != '*' {
+			groups[aa] = append(groups[aa], i)
+		}
+	}
+	return groups
+}
+
+// RSCU returns the relative synonymous codon usage of every codon: its count divided by the mean count of
+// the codons of its amino acid. Stop codons and codons of absent amino acids have 0.
+func (this *CodonUsageInfo) RSCU() [64]float64 {
+	var rscu [64]float64
+	for _, codons := range this.synonymous() {
+		total := int64(0)
+		for _, i := range codons {
+			total += this.Counts[i]
+		}
+		if total == 0 {
+			continue
+		}
+		for _, i := range codons {
+			rscu[i] = float64(this.Counts[i]) * float64(len(codons)) / float64(total)
+		}
+	}
+	return rscu
+}
+
+// ENC returns the effective number of codons (Wright 1990). The homozygosity F of the amino acids is
+// averaged by degeneracy class, a class without data takes the mean of the other classes (Ile with the
+// two and four fold classes in the standard code). The result is limited to the number of sense codons,
+// NaN is returned without enough codons.
+func (this *CodonUsageInfo) ENC() float64 {
+	type class struct {
+		aminoAcids int
+		sumF       float64
+		n          int
+	}
+	classes := make(map[int]*class)
+	senseCodons := 0
+	fixed := 0
+	for _, codons := range this.synonymous() {
+		k := len(codons)
+		senseCodons += k
+		if k == 1 {
+			fixed++
+			continue
+		}
+		c, ok := classes[k]
+		if !ok {
+			c = &class{}
+			classes[k] = c
+		}
+		c.aminoAcids++
+		total := int64(0)
+		for _, i := range codons {
+			total += this.Counts[i]
+		}
+		if total < 2 {
+			continue
+		}
+		sum := 0.0
+		for _, i := range codons {
+			p := float64(this.Counts[i]) / float64(total)
+			sum += p * p
+		}
+		c.sumF += (float64(total)*sum - 1) / float64(total-1)
+		c.n++
+	}
+
+	ks := make([]int, 0, len(classes))
+	for k := range classes {
+		ks = append(ks, k)
+	}
+	sort.Ints(ks)
+	meanF := 0.0
+	known := 0
+	for _, k := range ks {
+		if classes[k].n > 0 && classes[k].sumF > 0 {
+			meanF += classes[k].sumF / float64(classes[k].n)
+			known++
+		}
+	}
+	if known == 0 {
+		return math.NaN()
+	}
+	meanF /= float64(known)
+
+	enc := float64(fixed)
+	for _, k := range ks {
+		f := meanF
+		if classes[k].n > 0 && classes[k].sumF > 0 {
+			f = classes[k].sumF / float64(classes[k].n)
+		}
+		enc += float64(classes[k].aminoAcids) / f
+	}
+	return math.Min(enc, float64(senseCodons))
+}
+
+// GC3 returns the GC content of the third position of the sense codons.
+func (this *CodonUsageInfo) GC3() float64 {
+	gc, n := int64(0), int64(0)
+	for i, c := range this.Counts {
+		if this.aminoAcids[i] == '*' {
+			continue
+		}
+		n += c
+		if i%4 == 1 || i%4 == 3 {
+			gc += c
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(gc) / float64(n)
+}
+
+// CodonUsage counts the codons of coding sequences, in frame 1 of every record. Every partition counts its
+// codons and the counters are merged in a reduce. Cache the input to also call CodonUsagePerRecord, both read it.
+func CodonUsage(input *api.IDataFrame[string], o *SeqKitCodonUsageOptions) (*CodonUsageInfo, error) {
+	if o == nil {
+		o = &SeqKitCodonUsageOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libCount, err := api.AddParam(libSource("CodonUsage"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := api.MapPartitions[string, map[int64]int64](input, libCount)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := counts.Reduce(libSource("MapSumReduce"))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCodonUsageInfo(total, *opts.TranslTable)
+}
+
+// CodonUsagePerRecord returns the codon usage of every record, a line per record: ID, number of sense codons,
+// GC3, ENC, codon counts and RSCU, separated by tabs. The counts and the RSCU of the 64 codons are separated
+// by commas, in the order of CodonIndex.
+func CodonUsagePerRecord(input *api.IDataFrame[string], o *SeqKitCodonUsageOptions) (*api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitCodonUsageOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libRecords, err := api.AddParam(libSource("CodonUsageRecords"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return api.MapPartitions[string, string](input, libRecords)
+}
@@ -28,6 +28,24 @@ func (this *CommonOptions) setDefaults() *CommonOptions {
 	return this
 }
 
+func (this *CommonOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.BySeq && *this.ByName {
+		return OptionErrorf("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
+	}
+	if *this.OnlyPositiveStrand && !*this.BySeq {
+		return optionError("OnlyPositiveStrand", "flag -s (--by-seq) needed when using -P (--only-positive-strand)")
+	}
+	return nil
+}
+
+func (this *SeqKitCommonOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitCommonOptions) Config(v *SeqKitConfig) *SeqKitCommonOptions {
 	this.inner.Config = v.inner
 	return this
@@ -70,12 +88,18 @@ func Common(inputA *api.IDataFrame[string], inputB *api.IDataFrame[string], o *S
 		o = &SeqKitCommonOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 
 	inputs := make([]*api.IDataFrame[string], 0, len(inputN)+1)
 	inputs = append(inputs, inputB)
 	inputs = append(inputs, inputN...)
 
+	inputA, err := countRecords(inputA, "records_in")
+	if err != nil {
+		return nil, err
+	}
 	u, err := prepareCommon(inputA, &opts, "1")
 	if err != nil {
 		return nil, err
@@ -83,6 +107,10 @@ func Common(inputA *api.IDataFrame[string], inputB *api.IDataFrame[string], o *S
 
 	for i, input := range inputs {
 
+		input, err := countRecords(input, "records_in")
+		if err != nil {
+			return nil, err
+		}
 		pn, err := prepareCommon(input, &opts, strconv.Itoa(i+2))
 		if err != nil {
 			return nil, err
@@ -108,5 +136,9 @@ func Common(inputA *api.IDataFrame[string], inputB *api.IDataFrame[string], o *S
 		return nil, err
 	}
 
-	return api.Flatmap[ipair.IPair[int64, []string], string](grouped.FromPair(), join)
+	output, err := api.Flatmap[ipair.IPair[int64, []string], string](grouped.FromPair(), join)
+	if err != nil {
+		return nil, err
+	}
+	return countRecords(output, "records_out")
 }
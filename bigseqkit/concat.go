@@ -1,8 +1,11 @@
 package bigseqkit
 
 import (
+	"fmt"
 	"ignis/driver/api"
 	"ignis/executor/api/ipair"
+	"strconv"
+	"strings"
 )
 
 type SeqKitConcatOptions struct {
@@ -10,19 +13,42 @@ type SeqKitConcatOptions struct {
 }
 
 type ConcatOptions struct {
-	Config    KitConfig
-	Full      *bool
-	Separator *string
+	Config        KitConfig
+	Full          *bool
+	Separator     *string
+	Fill          *string
+	PartitionType *string
 }
 
 func (this *ConcatOptions) setDefaults() *ConcatOptions {
 	this.Config.setDefaults()
 	setDefault(&this.Full, false)
 	setDefault(&this.Separator, "|")
+	setDefault(&this.Fill, "")
+	setDefault(&this.PartitionType, "DNA")
+
+	if *this.Full && *this.Fill == "" {
+		*this.Fill = "-"
+	}
 
 	return this
 }
 
+func (this *ConcatOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if len(*this.Fill) > 1 {
+		return optionError("Fill", "fill must be a single character")
+	}
+	return nil
+}
+
+func (this *SeqKitConcatOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitConcatOptions) Config(v *SeqKitConfig) *SeqKitConcatOptions {
 	this.inner.Config = v.inner
 	return this
@@ -38,6 +64,16 @@ func (this *SeqKitConcatOptions) Separator(v string) *SeqKitConcatOptions {
 	return this
 }
 
+func (this *SeqKitConcatOptions) Fill(v string) *SeqKitConcatOptions {
+	this.inner.Fill = &v
+	return this
+}
+
+func (this *SeqKitConcatOptions) PartitionType(v string) *SeqKitConcatOptions {
+	this.inner.PartitionType = &v
+	return this
+}
+
 func prepareConcat(input *api.IDataFrame[string], opts *ConcatOptions, id string) (*api.IDataFrame[ipair.IPair[string, string]], error) {
 	libprepare, err := api.AddParam(libSource("ConcatPrepare"), "opts", OptionsToString(*opts))
 	if err != nil {
@@ -51,35 +87,117 @@ func prepareConcat(input *api.IDataFrame[string], opts *ConcatOptions, id string
 }
 
 func Concat(inputA *api.IDataFrame[string], inputB *api.IDataFrame[string], o *SeqKitConcatOptions) (*api.IDataFrame[string], error) {
+	return ConcatN([]*api.IDataFrame[string]{inputA, inputB}, o)
+}
+
+// ConcatN concatenates the sequences with the same ID from all the inputs, in the order of the inputs.
+// With Full, IDs missing from some inputs are kept and the missing blocks are filled with Fill ('-' by
+// default) repeated the length of the locus (the longest sequence of the input).
+func ConcatN(inputs []*api.IDataFrame[string], o *SeqKitConcatOptions) (*api.IDataFrame[string], error) {
 	if o == nil {
 		o = &SeqKitConcatOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
-
-	p1, err := prepareConcat(inputA, &opts, "1")
-	if err != nil {
+	if err := opts.setDefaults().Validate(); err != nil {
 		return nil, err
 	}
-	p2, err := prepareConcat(inputB, &opts, "2")
+
+	result, _, err := concatN(inputs, &opts, *opts.Full)
+	return result, err
+}
+
+// ConcatPartitions works like ConcatN with Full and also returns a RAxML/IQ-TREE style partition file
+// with the block of every input, e.g. "DNA, gene1 = 1-500". The records missing from an input are
+// always filled, so every block of the output starts and ends where the partition file says.
+func ConcatPartitions(inputs []*api.IDataFrame[string], names []string, o *SeqKitConcatOptions) (*api.IDataFrame[string], string, error) {
+	if o == nil {
+		o = &SeqKitConcatOptions{}
+	}
+	opts := o.inner
+	full := true
+	opts.Full = &full
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, "", err
+	}
+
+	if len(names) != len(inputs) {
+		return nil, "", OptionErrorf("one name per input is required for the partition file")
+	}
+
+	result, lengths, err := concatN(inputs, &opts, true)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	var sb strings.Builder
+	start := int64(1)
+	for i, name := range names {
+		sb.WriteString(fmt.Sprintf("%s, %s = %d-%d\n", *opts.PartitionType, name, start, start+lengths[i]-1))
+		start += lengths[i]
+	}
+
+	return result, sb.String(), nil
+}
+
+func concatN(inputs []*api.IDataFrame[string], opts *ConcatOptions, withLengths bool) (*api.IDataFrame[string], []int64, error) {
+	if len(inputs) < 2 {
+		return nil, nil, OptionErrorf("at least 2 inputs needed")
 	}
 
-	u, err := p1.Union(p2, false, nil)
+	u, err := prepareConcat(inputs[0], opts, "0")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	for i := 1; i < len(inputs); i++ {
+		p, err := prepareConcat(inputs[i], opts, strconv.Itoa(i))
+		if err != nil {
+			return nil, nil, err
+		}
+		if u, err = u.Union(p, false, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	lengths := make([]int64, len(inputs))
+	if withLengths {
+		if err = u.Cache(); err != nil {
+			return nil, nil, err
+		}
+		libLengths, err := api.AddParam(libSource("ConcatLengths"), "opts", OptionsToString(*opts))
+		if err != nil {
+			return nil, nil, err
+		}
+		partial, err := api.MapPartitions[ipair.IPair[string, string], map[int64]int64](u, libLengths)
+		if err != nil {
+			return nil, nil, err
+		}
+		maxLengths, err := partial.Reduce(libSource("ConcatLengthsReduce"))
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range lengths {
+			lengths[i] = maxLengths[int64(i)]
+		}
 	}
 
 	grouped, err := api.GroupByKey[string, string](api.ToPair[string, string](u), nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	join, err := api.AddParam(libSource("ConcatJoin"), "opts", OptionsToString(opts))
+	join, err := api.AddParam(libSource("ConcatJoin"), "opts", OptionsToString(*opts))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	join, err = api.AddParam(join, "lengths", OptionsToString(lengths))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := api.Flatmap[ipair.IPair[string, []string], string](grouped.FromPair(), join)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return api.Flatmap[ipair.IPair[string, []string], string](grouped.FromPair(), join)
+	return result, lengths, nil
 }
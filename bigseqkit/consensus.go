@@ -0,0 +1,188 @@
+package bigseqkit
+
+import (
+	"fmt"
+	"ignis/driver/api"
+	"ignis/executor/api/ipair"
+	"strings"
+)
+
+type SeqKitConsensusOptions struct {
+	inner ConsensusOptions
+}
+
+type ConsensusOptions struct {
+	Config    KitConfig
+	Sample    *string
+	Iupac     *bool
+	PassOnly  *bool
+	Conflict  *string
+	MapFormat *string
+}
+
+func (this *ConsensusOptions) setDefaults() *ConsensusOptions {
+	this.Config.setDefaults()
+	setDefault(&this.Sample, "")
+	setDefault(&this.Iupac, false)
+	setDefault(&this.PassOnly, false)
+	setDefault(&this.Conflict, "error")
+	setDefault(&this.MapFormat, "chain")
+
+	return this
+}
+
+func (this *ConsensusOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.Conflict != "error" && *this.Conflict != "skip" {
+		return optionError("Conflict", "invalid value of flag --conflict: %s. available values: 'error', 'skip'", *this.Conflict)
+	}
+	if *this.MapFormat != "chain" && *this.MapFormat != "offsets" {
+		return optionError("MapFormat", "invalid value of flag --map-format: %s. available values: 'chain', 'offsets'", *this.MapFormat)
+	}
+	return nil
+}
+
+func (this *SeqKitConsensusOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitConsensusOptions) Config(v *SeqKitConfig) *SeqKitConsensusOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+// Sample selects the genotypes of a sample, by default the first ALT allele of every variant is applied.
+func (this *SeqKitConsensusOptions) Sample(v string) *SeqKitConsensusOptions {
+	this.inner.Sample = &v
+	return this
+}
+
+// Iupac applies heterozygous SNPs as IUPAC ambiguity codes instead of the first ALT allele of the genotype.
+func (this *SeqKitConsensusOptions) Iupac(v bool) *SeqKitConsensusOptions {
+	this.inner.Iupac = &v
+	return this
+}
+
+func (this *SeqKitConsensusOptions) PassOnly(v bool) *SeqKitConsensusOptions {
+	this.inner.PassOnly = &v
+	return this
+}
+
+// Conflict sets the policy for variants overlapping a previous one or of a chromosome without reference:
+// "error" fails and "skip" ignores them with a warning.
+func (this *SeqKitConsensusOptions) Conflict(v string) *SeqKitConsensusOptions {
+	this.inner.Conflict = &v
+	return this
+}
+
+// MapFormat sets the format of the coordinate mapping: "chain" (UCSC chain) or "offsets".
+func (this *SeqKitConsensusOptions) MapFormat(v string) *SeqKitConsensusOptions {
+	this.inner.MapFormat = &v
+	return this
+}
+
+// Consensus applies the variants of a VCF (the lines of a plain VCF file, see ReadAnnotation) to the reference
+// records. The records and the variants are co-grouped by chromosome, so every chromosome is processed in
+// parallel. It returns the consensus records and the coordinate mapping from the reference to the consensus,
+// one chain (or offsets block) per record.
+func Consensus(input *api.IDataFrame[string], vcf *api.IDataFrame[string], o *SeqKitConsensusOptions) (
+	*api.IDataFrame[string], *api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitConsensusOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	sample := -1
+	if *opts.Sample != "" {
+		headers, err := vcf.Filter(libSource("ConsensusHeader"))
+		if err != nil {
+			return nil, nil, err
+		}
+		header, err := headers.Collect()
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(header) == 0 {
+			return nil, nil, fmt.Errorf("#CHROM header line not found in VCF")
+		}
+		columns := strings.Split(strings.TrimRight(header[0], "\r\n"), "\t")
+		for i := 9; i < len(columns); i++ {
+			if columns[i] == *opts.Sample {
+				sample = i
+				break
+			}
+		}
+		if sample < 0 {
+			return nil, nil, fmt.Errorf("sample not found in VCF: %s", *opts.Sample)
+		}
+	}
+
+	libRecords, err := api.AddParam(libSource("ConsensusKeyRecords"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := api.MapPartitions[string, ipair.IPair[string, string]](input, libRecords)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variants, err := api.MapPartitions[string, ipair.IPair[string, string]](vcf, libSource("ConsensusKeyVariants"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := records.Union(variants, false, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grouped, err := api.GroupByKey[string, string](api.ToPair[string, string](u), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apply, err := api.AddParam(libSource("ConsensusApply"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, nil, err
+	}
+	apply, err = api.AddParam(apply, "sample", sample)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	applied, err := api.Flatmap[ipair.IPair[string, []string], ipair.IPair[string, string]](grouped.FromPair(), apply)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = applied.Cache(); err != nil {
+		return nil, nil, err
+	}
+
+	consensus, err := api.Map[ipair.IPair[string, string], string](applied, libSource("ConsensusRecord"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapping, err := api.Flatmap[ipair.IPair[string, string], string](applied, libSource("ConsensusMapping"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if *opts.MapFormat == "chain" {
+		// every record has a chain, so the index of a chain is unique across chromosomes
+		mapping, err = api.MapWithIndex[string, string](mapping, libSource("ConsensusChainId"))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return consensus, mapping, nil
+}
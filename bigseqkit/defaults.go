@@ -0,0 +1,110 @@
+package bigseqkit
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultsFile is the name of the config files with the default values, in the user config directory
+// (e.g. ~/.config/bigseqkit.yaml) and in the project (current) directory.
+const DefaultsFile = "bigseqkit.yaml"
+
+// DefaultsEnvPrefix is the prefix of the environment variables with default values, e.g. BIGSEQKIT_LINE_WIDTH
+// for line-width.
+const DefaultsEnvPrefix = "BIGSEQKIT_"
+
+// DefaultValue is a default value and its source, a config file or an environment variable.
+type DefaultValue struct {
+	Value  string
+	Source string
+}
+
+// Defaults are the default values of the command line flags, by their long name (e.g. line-width), and the
+// environment variables to export, e.g. of the cluster. The values of the environment variables override the
+// project file, which overrides the user file.
+type Defaults struct {
+	Values map[string]DefaultValue
+	Env    map[string]DefaultValue
+}
+
+// DefaultsFiles returns the config files in increasing priority, the user file and the project file.
+func DefaultsFiles() []string {
+	files := make([]string, 0, 2)
+	if dir, err := os.UserConfigDir(); err == nil {
+		files = append(files, filepath.Join(dir, DefaultsFile))
+	}
+	return append(files, DefaultsFile)
+}
+
+// LoadDefaults reads the config files that exist and the BIGSEQKIT_* environment variables. A config file is
+// a YAML map of flags to values, and an "env" map of environment variables, e.g.:
+//
+//	partitions: 64
+//	line-width: 0
+//	env:
+//	  IGNIS_HOME: /opt/ignis
+func LoadDefaults() (*Defaults, error) {
+	defaults := &Defaults{
+		Values: make(map[string]DefaultValue),
+		Env:    make(map[string]DefaultValue),
+	}
+	for _, file := range DefaultsFiles() {
+		if err := defaults.load(file); err != nil {
+			return nil, err
+		}
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, DefaultsEnvPrefix) {
+			key := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(name, DefaultsEnvPrefix), "_", "-"))
+			defaults.Values[key] = DefaultValue{value, name}
+		}
+	}
+	return defaults, nil
+}
+
+func (this *Defaults) load(file string) error {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	values := make(map[string]any)
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return OptionErrorf("incorrect config file %s: %s", file, err)
+	}
+	for key, value := range values {
+		if key == "env" {
+			env, ok := value.(map[string]any)
+			if !ok {
+				return OptionErrorf("incorrect config file %s: env must be a map of variables", file)
+			}
+			for name, value := range env {
+				this.Env[name] = DefaultValue{fmt.Sprint(value), file}
+			}
+			continue
+		}
+		switch value.(type) {
+		case map[string]any, []any:
+			return OptionErrorf("incorrect config file %s: value of %s must be a scalar", file, key)
+		}
+		this.Values[key] = DefaultValue{fmt.Sprint(value), file}
+	}
+	return nil
+}
+
+// ExportEnv sets the environment variables of the config files that are not already set.
+func (this *Defaults) ExportEnv() error {
+	for name, value := range this.Env {
+		if _, ok := os.LookupEnv(name); !ok {
+			if err := os.Setenv(name, value.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package bigseqkit
+
+import (
+	"ignis/driver/api"
+	"ignis/executor/api/ipair"
+	"strings"
+)
+
+// RestrictionEnzymes is the built-in table of restriction enzymes in REBASE notation. '^' marks the cut of
+// the top strand of palindromic sites, (n/m) the cuts of the top and bottom strands after the 3' end of
+// non-palindromic sites.
+var RestrictionEnzymes = map[string]string{
+	"AatII":    "GACGT^C",
+	"AgeI":     "A^CCGGT",
+	"AluI":     "AG^CT",
+	"ApaI":     "GGGCC^C",
+	"ApeKI":    "G^CWGC",
+	"ApoI":     "R^AATTY",
+	"AscI":     "GG^CGCGCC",
+	"AseI":     "AT^TAAT",
+	"AvaI":     "C^YCGRG",
+	"BamHI":    "G^GATCC",
+	"BbsI":     "GAAGAC(2/6)",
+	"BfaI":     "C^TAG",
+	"BglII":    "A^GATCT",
+	"BsaI":     "GGTCTC(1/5)",
+	"BsmBI":    "CGTCTC(1/5)",
+	"BsrGI":    "T^GTACA",
+	"BstYI":    "R^GATCY",
+	"ClaI":     "AT^CGAT",
+	"Csp6I":    "G^TAC",
+	"CviAII":   "C^ATG",
+	"DdeI":     "C^TNAG",
+	"DpnII":    "^GATC",
+	"EagI":     "C^GGCCG",
+	"EcoRI":    "G^AATTC",
+	"EcoRV":    "GAT^ATC",
+	"FatI":     "^CATG",
+	"HaeIII":   "GG^CC",
+	"HhaI":     "GCG^C",
+	"HindIII":  "A^AGCTT",
+	"HinfI":    "G^ANTC",
+	"HpaII":    "C^CGG",
+	"HpyCH4IV": "A^CGT",
+	"KpnI":     "GGTAC^C",
+	"MboI":     "^GATC",
+	"MluCI":    "^AATT",
+	"MluI":     "A^CGCGT",
+	"MmeI":     "TCCRAC(20/18)",
+	"MseI":     "T^TAA",
+	"MspI":     "C^CGG",
+	"NcoI":     "C^CATGG",
+	"NdeI":     "CA^TATG",
+	"NheI":     "G^CTAGC",
+	"NlaIII":   "CATG^",
+	"NotI":     "GC^GGCCGC",
+	"NspI":     "RCATG^Y",
+	"PacI":     "TTAAT^TAA",
+	"PmeI":     "GTTT^AAAC",
+	"PstI":     "CTGCA^G",
+	"PvuII":    "CAG^CTG",
+	"RsaI":     "GT^AC",
+	"SacI":     "GAGCT^C",
+	"SacII":    "CCGC^GG",
+	"SalI":     "G^TCGAC",
+	"SapI":     "GCTCTTC(1/4)",
+	"Sau3AI":   "^GATC",
+	"Sau96I":   "G^GNCC",
+	"SbfI":     "CCTGCA^GG",
+	"ScaI":     "AGT^ACT",
+	"SfiI":     "GGCCNNNN^NGGCC",
+	"SmaI":     "CCC^GGG",
+	"SpeI":     "A^CTAGT",
+	"SphI":     "GCATG^C",
+	"StuI":     "AGG^CCT",
+	"SwaI":     "ATTT^AAAT",
+	"TaqI":     "T^CGA",
+	"XbaI":     "T^CTAGA",
+	"XhoI":     "C^TCGAG",
+	"XmaI":     "C^CCGGG",
+}
+
+// digestKnownEnzyme returns whether an enzyme, case insensitive, is in RestrictionEnzymes.
+func digestKnownEnzyme(name string) bool {
+	for known := range RestrictionEnzymes {
+		if strings.EqualFold(known, name) {
+			return true
+		}
+	}
+	return false
+}
+
+type SeqKitDigestOptions struct {
+	inner DigestOptions
+}
+
+type DigestOptions struct {
+	Config     KitConfig
+	Enzymes    *[]string
+	EnzymeFile *string
+	Circular   *bool
+	Bed        *bool
+	MinLen     *int
+	MaxLen     *int
+	HistBin    *int
+	SplitLen   *int
+}
+
+func (this *DigestOptions) setDefaults() *DigestOptions {
+	this.Config.setDefaults()
+	setDefault(&this.Enzymes, []string{})
+	setDefault(&this.EnzymeFile, "")
+	setDefault(&this.Circular, false)
+	setDefault(&this.Bed, false)
+	setDefault(&this.MinLen, 0)
+	setDefault(&this.MaxLen, 0)
+	setDefault(&this.HistBin, 100)
+	setDefault(&this.SplitLen, 1000000)
+
+	return this
+}
+
+func (this *DigestOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if len(*this.Enzymes) == 0 {
+		return optionError("Enzymes", "flag -e (--enzyme) needed")
+	}
+	if *this.EnzymeFile == "" {
+		// enzymes of the enzyme file are only known by the executors
+		for _, name := range *this.Enzymes {
+			if !digestKnownEnzyme(name) {
+				return optionError("Enzymes", "unknown enzyme: %s", name)
+			}
+		}
+	}
+	if *this.MinLen < 0 {
+		return optionError("MinLen", "value of flag --min-len should not be negative")
+	}
+	if *this.MaxLen > 0 && *this.MaxLen < *this.MinLen {
+		return optionError("MaxLen", "value of flag --max-len should not be lower than --min-len")
+	}
+	if *this.HistBin <= 0 {
+		return optionError("HistBin", "value of flag --hist-bin should be greater than 0")
+	}
+	if *this.SplitLen < 0 {
+		return optionError("SplitLen", "value of flag --split-len should not be negative")
+	}
+	return nil
+}
+
+func (this *SeqKitDigestOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitDigestOptions) Config(v *SeqKitConfig) *SeqKitDigestOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+// Enzymes sets the names of the enzymes of the digest, case insensitive.
+func (this *SeqKitDigestOptions) Enzymes(v []string) *SeqKitDigestOptions {
+	this.inner.Enzymes = &v
+	return this
+}
+
+// EnzymeFile sets a tab-delimited file of enzymes (name and site in REBASE notation) that extends the
+// built-in table.
+func (this *SeqKitDigestOptions) EnzymeFile(v string) *SeqKitDigestOptions {
+	this.inner.EnzymeFile = &v
+	return this
+}
+
+func (this *SeqKitDigestOptions) Circular(v bool) *SeqKitDigestOptions {
+	this.inner.Circular = &v
+	return this
+}
+
+// Bed outputs the coordinates of the fragments in BED6 format, the fragment length is the score.
+func (this *SeqKitDigestOptions) Bed(v bool) *SeqKitDigestOptions {
+	this.inner.Bed = &v
+	return this
+}
+
+func (this *SeqKitDigestOptions) MinLen(v int) *SeqKitDigestOptions {
+	this.inner.MinLen = &v
+	return this
+}
+
+// MaxLen sets the maximum fragment length. 0 for no limit.
+func (this *SeqKitDigestOptions) MaxLen(v int) *SeqKitDigestOptions {
+	this.inner.MaxLen = &v
+	return this
+}
+
+// HistBin sets the bin size of the fragment-length histogram.
+func (this *SeqKitDigestOptions) HistBin(v int) *SeqKitDigestOptions {
+	this.inner.HistBin = &v
+	return this
+}
+
+// SplitLen sets the length of the chunks that long sequences are split into, so the digest of a long
+// sequence is distributed among the executors. 0 disables the splitting.
+func (this *SeqKitDigestOptions) SplitLen(v int) *SeqKitDigestOptions {
+	this.inner.SplitLen = &v
+	return this
+}
+
+// Digest cuts the sequences with one or more restriction enzymes and returns the fragments, as FASTA/Q
+// records or BED lines. Sequences longer than SplitLen are split into chunks that are repartitioned among
+// the executors, the fragments crossing the ends of the chunks are joined by sequence and returned after
+// the rest.
+func Digest(input *api.IDataFrame[string], o *SeqKitDigestOptions) (*api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitDigestOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libSplit, err := api.AddParam(libSource("DigestSplit"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := api.MapPartitions[string, string](input, libSplit)
+	if err != nil {
+		return nil, err
+	}
+
+	if *opts.SplitLen > 0 {
+		n, err := chunks.Partitions()
+		if err != nil {
+			return nil, err
+		}
+		if chunks, err = chunks.Repartition(n, true, true); err != nil {
+			return nil, err
+		}
+	}
+
+	libDigest, err := api.AddParam(libSource("Digest"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	digested, err := api.MapPartitions[string, ipair.IPair[string, string]](chunks, libDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	if *opts.SplitLen == 0 { // no pieces
+		return api.Flatmap[ipair.IPair[string, string], string](digested, libSource("DigestFragments"))
+	}
+
+	if err = digested.Cache(); err != nil {
+		return nil, err
+	}
+
+	fragments, err := api.Flatmap[ipair.IPair[string, string], string](digested, libSource("DigestFragments"))
+	if err != nil {
+		return nil, err
+	}
+
+	pieces, err := api.Flatmap[ipair.IPair[string, string], ipair.IPair[string, string]](digested, libSource("DigestPieces"))
+	if err != nil {
+		return nil, err
+	}
+
+	grouped, err := api.GroupByKey[string, string](api.ToPair[string, string](pieces), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	libJoin, err := api.AddParam(libSource("DigestJoin"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	joined, err := api.Flatmap[ipair.IPair[string, []string], string](grouped.FromPair(), libJoin)
+	if err != nil {
+		return nil, err
+	}
+
+	return fragments.Union(joined, true, nil)
+}
+
+// DigestHistogram returns the number of fragments by length, the keys are the first length of every bin.
+// The fragments are the output of Digest with the same options, which should be cached.
+func DigestHistogram(fragments *api.IDataFrame[string], o *SeqKitDigestOptions) (map[int64]int64, error) {
+	if o == nil {
+		o = &SeqKitDigestOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libprepare, err := api.AddParam(libSource("DigestHistogram"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := api.MapPartitions[string, map[int64]int64](fragments, libprepare)
+	if err != nil {
+		return nil, err
+	}
+
+	return counts.Reduce(libSource("MapSumReduce"))
+}
@@ -18,6 +18,21 @@ func (this *DuplicateOptions) setDefaults() *DuplicateOptions {
 	return this
 }
 
+func (this *DuplicateOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.Times <= 0 {
+		return optionError("Times", "value of flag -n (--times) should be greater than 0")
+	}
+	return nil
+}
+
+func (this *SeqKitDuplicateOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitDuplicateOptions) Config(v *SeqKitConfig) *SeqKitDuplicateOptions {
 	this.inner.Config = v.inner
 	return this
@@ -33,7 +48,9 @@ func Duplicate(input *api.IDataFrame[string], o *SeqKitDuplicateOptions) (*api.I
 		o = &SeqKitDuplicateOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 
 	dup, err := api.AddParam[int64](libSource("Duplicate"), "times", *opts.Times)
 	if err != nil {
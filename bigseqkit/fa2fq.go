@@ -22,6 +22,21 @@ func (this *Fa2FqOptions) setDefaults() *Fa2FqOptions {
 	return this
 }
 
+func (this *Fa2FqOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.FastaFile == "" {
+		return optionError("FastaFile", "flag -f (--fasta-file) needed")
+	}
+	return nil
+}
+
+func (this *SeqKitFa2FqOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitFa2FqOptions) Config(v *SeqKitConfig) *SeqKitFa2FqOptions {
 	this.inner.Config = v.inner
 	return this
@@ -42,7 +57,9 @@ func Fa2Fq(input *api.IDataFrame[string], o *SeqKitFa2FqOptions) (*api.IDataFram
 		o = &SeqKitFa2FqOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 	libprepare, err := api.AddParam(libSource("Fa2Fq"), "opts", OptionsToString(opts))
 	if err != nil {
 		return nil, err
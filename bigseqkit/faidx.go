@@ -2,6 +2,7 @@ package bigseqkit
 
 import (
 	"ignis/driver/api"
+	"regexp"
 )
 
 type SeqKitFaidxOptions struct {
@@ -28,6 +29,25 @@ func (this *FaidxOptions) setDefaults() *FaidxOptions {
 	return this
 }
 
+func (this *FaidxOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.UseRegexp {
+		for _, query := range *this.Regions {
+			if _, err := regexp.Compile(query); err != nil {
+				return optionError("Regions", "invalid regular expression: %s", query)
+			}
+		}
+	}
+	return nil
+}
+
+func (this *SeqKitFaidxOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitFaidxOptions) Config(v *SeqKitConfig) *SeqKitFaidxOptions {
 	this.inner.Config = v.inner
 	return this
@@ -64,7 +84,9 @@ func Faidx(input *api.IDataFrame[string], o *SeqKitFaidxOptions) (
 		o = &SeqKitFaidxOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err = opts.setDefaults().Validate(); err != nil {
+		return nil, nil, err
+	}
 
 	offsets, err := api.MapPartitions[string, int64](input, libSource("FaidxOffset"))
 	if err != nil {
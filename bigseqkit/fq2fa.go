@@ -17,6 +17,15 @@ func (this *Fq2FaOptions) setDefaults() *Fq2FaOptions {
 	return this
 }
 
+func (this *Fq2FaOptions) Validate() error {
+	return this.Config.Validate()
+}
+
+func (this *SeqKitFq2FaOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitFq2FaOptions) Config(v *SeqKitConfig) *SeqKitFq2FaOptions {
 	this.inner.Config = v.inner
 	return this
@@ -27,7 +36,9 @@ func Fq2Fa(input *api.IDataFrame[string], o *SeqKitFq2FaOptions) (*api.IDataFram
 		o = &SeqKitFq2FaOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 	libprepare, err := api.AddParam(libSource("Fq2Fa"), "opts", OptionsToString(opts))
 	if err != nil {
 		return nil, err
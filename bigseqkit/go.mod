@@ -7,6 +7,7 @@ require (
 	github.com/shenwei356/bio v0.7.0
 	github.com/shenwei356/util v0.5.0
 	github.com/tatsushid/go-prettytable v0.0.0-20141013043238-ed2d14c29939
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 	ignis v0.0.0
 )
 
@@ -20,10 +21,10 @@ require (
 	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/pierrec/xxHash v0.1.5 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/shenwei356/natsort v0.0.0-20190418160752-600d539c017d // indirect
 	github.com/shenwei356/xopen v0.2.1 // indirect
 	github.com/ulikunitz/xz v0.5.10 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
-	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
 
 replace ignis => /home/cesar/core-go/ignis
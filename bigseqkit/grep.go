@@ -3,6 +3,7 @@ package bigseqkit
 import (
 	"ignis/driver/api"
 	"ignis/executor/api/ipair"
+	"regexp"
 	"strconv"
 )
 
@@ -48,6 +49,42 @@ func (this *GrepOptions) setDefaults() *GrepOptions {
 	return this
 }
 
+func (this *GrepOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if noPatterns(*this.Pattern) && *this.PatternFile == "" {
+		return OptionErrorf("one of flags -p (--pattern) and -f (--pattern-file) needed")
+	}
+	if *this.MaxMismatch < 0 {
+		return optionError("MaxMismatch", "value of flag -m (--max-mismatch) should not be negative")
+	}
+	if *this.MaxMismatch > 0 && (*this.UseRegexp || *this.Degenerate) {
+		return optionError("MaxMismatch", "flag -r (--use-regexp) or -d (--degenerate) not allowed when giving flag -m (--max-mismatch)")
+	}
+	if *this.UseRegexp && *this.Degenerate {
+		return OptionErrorf("could not give both flags -d (--degenerate) and -r (--use-regexp)")
+	}
+	if *this.UseRegexp {
+		for _, p := range *this.Pattern {
+			if _, err := regexp.Compile(p); err != nil {
+				return optionError("Pattern", "invalid regular expression: %s", p)
+			}
+		}
+	}
+	if *this.Region != "" {
+		if err := validateRegion("grep", *this.Region); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *SeqKitGrepOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitGrepOptions) Config(v *SeqKitConfig) *SeqKitGrepOptions {
 	this.inner.Config = v.inner
 	return this
@@ -119,7 +156,11 @@ func (this *SeqKitGrepOptions) Circular(v bool) *SeqKitGrepOptions {
 }
 
 func commonGrep(input *api.IDataFrame[string], opts *GrepOptions) (*api.IDataFrame[string], error) {
-	grep, err := api.AddParam(libSource("GrepPairMatched"), "opts", OptionsToString(*opts))
+	grep, err := metricsSource("Grep")
+	if err != nil {
+		return nil, err
+	}
+	grep, err = api.AddParam(grep, "opts", OptionsToString(*opts))
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +173,9 @@ func Grep(input *api.IDataFrame[string], o *SeqKitGrepOptions) (*api.IDataFrame[
 		o = &SeqKitGrepOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 	aux := false
 	opts.Count = &aux
 
@@ -163,7 +206,9 @@ func GrepCount(input *api.IDataFrame[string], o *SeqKitGrepOptions) (int64, erro
 		o = &SeqKitGrepOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return 0, err
+	}
 	aux := true
 	opts.Count = &aux
 
@@ -21,6 +21,21 @@ func (this *HeadOptions) setDefaults() *HeadOptions {
 	return this
 }
 
+func (this *HeadOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.N <= 0 {
+		return optionError("N", "value of flag -n (--number) should be greater than 0")
+	}
+	return nil
+}
+
+func (this *SeqKitHeadOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitHeadOptions) Config(v *SeqKitConfig) *SeqKitHeadOptions {
 	this.inner.Config = v.inner
 	return this
@@ -36,7 +51,9 @@ func Head(input *api.IDataFrame[string], o *SeqKitHeadOptions) (*api.IDataFrame[
 		o = &SeqKitHeadOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 
 	oRange := (&SeqKitRangeOptions{}).Range("1:" + strconv.FormatInt(*opts.N, 10))
 	oRange.inner.Config = opts.Config
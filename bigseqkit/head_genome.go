@@ -23,6 +23,21 @@ func (this *HeadGenomeOptions) setDefaults() *HeadGenomeOptions {
 	return this
 }
 
+func (this *HeadGenomeOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.MiniCommonWords <= 0 {
+		return optionError("MiniCommonWords", "value of flag -m (--mini-common-words) should be greater than 0")
+	}
+	return nil
+}
+
+func (this *SeqKitHeadGenomeOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitHeadGenomeOptions) Config(v *SeqKitConfig) *SeqKitHeadGenomeOptions {
 	this.inner.Config = v.inner
 	return this
@@ -38,7 +53,13 @@ func HeadGenome(input *api.IDataFrame[string], o *SeqKitHeadGenomeOptions) (*api
 		o = &SeqKitHeadGenomeOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+	input, err := countRecords(input, "records_in")
+	if err != nil {
+		return nil, err
+	}
 
 	firstSeq, err := input.Take(1)
 	if err != nil {
@@ -64,5 +85,9 @@ func HeadGenome(input *api.IDataFrame[string], o *SeqKitHeadGenomeOptions) (*api
 		return nil, err
 	}
 
-	return api.MapPartitionsWithIndex[string, string](input, lib)
+	output, err := api.MapPartitionsWithIndex[string, string](input, lib)
+	if err != nil {
+		return nil, err
+	}
+	return countRecords(output, "records_out")
 }
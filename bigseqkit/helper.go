@@ -9,6 +9,8 @@ import (
 	"ignis/driver/api"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -38,6 +40,60 @@ type KitConfig struct {
 	ValidateSeqLength      *int
 }
 
+// OptionError is returned when the options are invalid, before any job is run. Option is the name of the
+// invalid field of the options, empty when the error is not caused by a single one.
+type OptionError struct {
+	Option string
+	msg    string
+}
+
+func (this *OptionError) Error() string {
+	return this.msg
+}
+
+func OptionErrorf(format string, a ...any) error {
+	return &OptionError{msg: fmt.Sprintf(format, a...)}
+}
+
+func optionError(option string, format string, a ...any) error {
+	return &OptionError{Option: option, msg: fmt.Sprintf(format, a...)}
+}
+
+var reRegion = regexp.MustCompile(`\-?\d+:\-?\d+`)
+
+// validateRegion checks a region "start:end" of the flag -r (--region) of a command.
+func validateRegion(command string, region string) error {
+	r := strings.Split(region, ":")
+	if !reRegion.MatchString(region) || len(r) != 2 {
+		return optionError("Region", `invalid region: %s. type "seqkit %s -h" for more examples`, region, command)
+	}
+	start, err := strconv.Atoi(r[0])
+	if err != nil {
+		return optionError("Region", "invalid start of region: %s", region)
+	}
+	end, err := strconv.Atoi(r[1])
+	if err != nil {
+		return optionError("Region", "invalid end of region: %s", region)
+	}
+	if start == 0 || end == 0 {
+		return optionError("Region", "both start and end should not be 0")
+	}
+	if start < 0 && end > 0 {
+		return optionError("Region", "when start < 0, end should not > 0")
+	}
+	return nil
+}
+
+// noPatterns returns whether no pattern is given, the default value of the patterns is a single empty one.
+func noPatterns(patterns []string) bool {
+	for _, p := range patterns {
+		if p != "" {
+			return false
+		}
+	}
+	return true
+}
+
 func setDefault[T any](pvar **T, val T) {
 	if *pvar == nil {
 		*pvar = &val
@@ -79,7 +135,7 @@ func (this *KitConfig) GetAlphabet() (*seq.Alphabet, error) {
 	case "auto":
 		return nil, nil
 	default:
-		return nil, fmt.Errorf("invalid sequence type: %s, available value: dna|rna|protein|unlimit|auto", value)
+		return nil, optionError("SeqType", "invalid sequence type: %s, available value: dna|rna|protein|unlimit|auto", value)
 	}
 }
 
@@ -102,6 +158,36 @@ func (this *KitConfig) setDefaults() *KitConfig {
 	return this
 }
 
+// Validate checks the options, their defaults must be set.
+func (this *KitConfig) Validate() error {
+	if _, err := this.GetAlphabet(); err != nil {
+		return err
+	}
+	if *this.LineWidth < 0 {
+		return optionError("LineWidth", "value of flag -w (--line-width) should not be negative")
+	}
+	if *this.AlphabetGuessSeqLength < 0 {
+		return optionError("AlphabetGuessSeqLength", "value of flag --alphabet-guess-seq-length should not be negative")
+	}
+	if *this.AlphabetGuessSeqLength > 0 && *this.AlphabetGuessSeqLength < 1000 {
+		return optionError("AlphabetGuessSeqLength", "value of flag --alphabet-guess-seq-length too small, should >= 1000")
+	}
+	re, err := regexp.Compile(*this.IDRegexp)
+	if err != nil {
+		return optionError("IDRegexp", "invalid value of flag --id-regexp: %s", err)
+	}
+	if re.NumSubexp() < 1 {
+		return optionError("IDRegexp", `value of flag --id-regexp must contain "(" and ")" to capture matched ID: %s`, *this.IDRegexp)
+	}
+	return nil
+}
+
+// Validate checks the options without running any job.
+func (this *SeqKitConfig) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitConfig) SeqType(v string) *SeqKitConfig {
 	this.inner.SeqType = &v
 	return this
@@ -138,7 +224,11 @@ func (this *SeqKitConfig) ValidateSeqLength(v int) *SeqKitConfig {
 }
 
 func fixer(input *api.IDataFrame[string], delim string) (*api.IDataFrame[string], error) {
-	fixer, err := api.AddParam(libSource("ReadFixer"), "delim", delim)
+	fixer, err := readerSource("ReadFixer")
+	if err != nil {
+		return nil, err
+	}
+	fixer, err = api.AddParam(fixer, "delim", delim)
 	if err != nil {
 		return nil, err
 	}
@@ -177,8 +267,17 @@ func ReadFASTQN(path string, minPartitions int64, worker *api.IWorker) (*api.IDa
 	return fixer(input, "@")
 }
 
+// ReadAnnotation reads the lines of a GTF, GFF3 or BED file to be joined with the sequences, see SubseqJoin.
+func ReadAnnotation(path string, worker *api.IWorker) (*api.IDataFrame[string], error) {
+	return worker.PlainFile(path, "\n")
+}
+
 func StoreFASTX(input *api.IDataFrame[string], path string) error {
-	store, err := api.AddParam(libSource("FileStore"), "path", path)
+	store, err := metricsSource("FileStore")
+	if err != nil {
+		return err
+	}
+	store, err = api.AddParam(store, "path", path)
 	if err != nil {
 		return err
 	}
@@ -191,5 +290,9 @@ func StoreFASTX(input *api.IDataFrame[string], path string) error {
 }
 
 func StoreFASTXN(input *api.IDataFrame[string], path string) error {
-	return input.SaveAsTextFile(path)
+	counted, err := countRecords(input, "records_out")
+	if err != nil {
+		return err
+	}
+	return counted.SaveAsTextFile(path)
 }
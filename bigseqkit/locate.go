@@ -1,6 +1,9 @@
 package bigseqkit
 
-import "ignis/driver/api"
+import (
+	"ignis/driver/api"
+	"regexp"
+)
 
 type SeqKitLocateOptions struct {
 	inner LocateOptions
@@ -44,6 +47,47 @@ func (this *LocateOptions) setDefaults() *LocateOptions {
 	return this
 }
 
+func (this *LocateOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if noPatterns(*this.Pattern) && *this.PatternFile == "" {
+		return OptionErrorf("one of flags -p (--pattern) and -f (--pattern-file) needed")
+	}
+	if *this.MaxMismatch < 0 {
+		return optionError("MaxMismatch", "value of flag -m (--max-mismatch) should not be negative")
+	}
+	if *this.MaxMismatch > 0 {
+		if *this.Degenerate {
+			return optionError("Degenerate", "flag -d (--degenerate) not allowed when giving flag -m (--max-mismatch)")
+		}
+		if *this.UseRegexp {
+			return optionError("UseRegexp", "flag -r (--use-regexp) not allowed when giving flag -m (--max-mismatch)")
+		}
+	}
+	if *this.UseFmi {
+		if *this.Degenerate {
+			return optionError("Degenerate", "flag -d (--degenerate) not allowed when giving flag -F (--use-fmi)")
+		}
+		if *this.UseRegexp {
+			return optionError("UseRegexp", "flag -r (--use-regexp) not allowed when giving flag -F (--use-fmi)")
+		}
+	}
+	if *this.UseRegexp {
+		for _, p := range *this.Pattern {
+			if _, err := regexp.Compile(p); err != nil {
+				return optionError("Pattern", "invalid regular expression: %s", p)
+			}
+		}
+	}
+	return nil
+}
+
+func (this *SeqKitLocateOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitLocateOptions) Config(v *SeqKitConfig) *SeqKitLocateOptions {
 	this.inner.Config = v.inner
 	return this
@@ -124,7 +168,9 @@ func Locate(input *api.IDataFrame[string], o *SeqKitLocateOptions) (*api.IDataFr
 		o = &SeqKitLocateOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 	libprepare, err := api.AddParam(libSource("Locate"), "opts", OptionsToString(opts))
 	if err != nil {
 		return nil, err
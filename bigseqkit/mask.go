@@ -0,0 +1,124 @@
+package bigseqkit
+
+import (
+	"ignis/driver/api"
+)
+
+type SeqKitMaskOptions struct {
+	inner MaskOptions
+}
+
+type MaskOptions struct {
+	Config        KitConfig
+	Method        *string
+	Window        *int
+	DustLevel     *float64
+	MinEntropy    *float64
+	Mode          *string
+	MaxMaskedFrac *float64
+	Bed           *bool
+}
+
+func (this *MaskOptions) setDefaults() *MaskOptions {
+	this.Config.setDefaults()
+	setDefault(&this.Method, "dust")
+	setDefault(&this.Window, 64)
+	setDefault(&this.DustLevel, 20.0)
+	setDefault(&this.MinEntropy, 0.5)
+	setDefault(&this.Mode, "soft")
+	setDefault(&this.MaxMaskedFrac, 1.0)
+	setDefault(&this.Bed, false)
+
+	return this
+}
+
+func (this *MaskOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.Method != "dust" && *this.Method != "entropy" {
+		return optionError("Method", "invalid method: %s, available: dust, entropy", *this.Method)
+	}
+	if *this.Mode != "soft" && *this.Mode != "hard" && *this.Mode != "none" {
+		return optionError("Mode", "invalid mask mode: %s, available: soft, hard, none", *this.Mode)
+	}
+	if *this.Window < 4 {
+		return optionError("Window", "value of flag -W (--window) should be at least 4")
+	}
+	if *this.MinEntropy < 0 || *this.MinEntropy > 1 {
+		return optionError("MinEntropy", "value of flag --min-entropy should be in range [0, 1]")
+	}
+	if *this.MaxMaskedFrac < 0 || *this.MaxMaskedFrac > 1 {
+		return optionError("MaxMaskedFrac", "value of flag --max-masked-frac should be in range [0, 1]")
+	}
+	return nil
+}
+
+func (this *SeqKitMaskOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitMaskOptions) Config(v *SeqKitConfig) *SeqKitMaskOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+// Method sets the complexity measure: "dust" or "entropy".
+func (this *SeqKitMaskOptions) Method(v string) *SeqKitMaskOptions {
+	this.inner.Method = &v
+	return this
+}
+
+func (this *SeqKitMaskOptions) Window(v int) *SeqKitMaskOptions {
+	this.inner.Window = &v
+	return this
+}
+
+// DustLevel sets the DUST score (x10) over which a region is low-complexity.
+func (this *SeqKitMaskOptions) DustLevel(v float64) *SeqKitMaskOptions {
+	this.inner.DustLevel = &v
+	return this
+}
+
+// MinEntropy sets the normalized trinucleotide entropy (0-1) under which a window is low-complexity.
+func (this *SeqKitMaskOptions) MinEntropy(v float64) *SeqKitMaskOptions {
+	this.inner.MinEntropy = &v
+	return this
+}
+
+// Mode sets how low-complexity regions are masked: "soft" (lower case), "hard" (N) or "none".
+func (this *SeqKitMaskOptions) Mode(v string) *SeqKitMaskOptions {
+	this.inner.Mode = &v
+	return this
+}
+
+// MaxMaskedFrac drops the sequences with a greater fraction of low-complexity bases.
+func (this *SeqKitMaskOptions) MaxMaskedFrac(v float64) *SeqKitMaskOptions {
+	this.inner.MaxMaskedFrac = &v
+	return this
+}
+
+// Bed outputs the low-complexity regions in BED format instead of the sequences.
+func (this *SeqKitMaskOptions) Bed(v bool) *SeqKitMaskOptions {
+	this.inner.Bed = &v
+	return this
+}
+
+// Mask finds low-complexity regions with DUST or Shannon entropy to mask them or to drop the sequences.
+func Mask(input *api.IDataFrame[string], o *SeqKitMaskOptions) (*api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitMaskOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libprepare, err := api.AddParam(libSource("Mask"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return api.MapPartitions[string, string](input, libprepare)
+}
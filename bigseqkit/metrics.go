@@ -0,0 +1,217 @@
+package bigseqkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"ignis/driver/api"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MetricsExt and MetricsProgressExt are the extensions of the records saved by the executor functions when
+// they end and while they run.
+const (
+	MetricsExt         = ".json"
+	MetricsProgressExt = ".progress"
+)
+
+// MetricsRecord is saved by an executor function to the metrics directory, with the counters of the
+// partitions it processed, e.g. the records in, out and dropped by a filter. Start and End are the unix
+// times in nanoseconds of its Before and After, End is 0 while it runs.
+type MetricsRecord struct {
+	Stage      string           `json:"stage"`
+	Function   string           `json:"function"`
+	Executor   int              `json:"executor"`
+	Start      int64            `json:"start"`
+	End        int64            `json:"end"`
+	Partitions int64            `json:"partitions"`
+	Counters   map[string]int64 `json:"counters"`
+}
+
+// StageMetrics are the counters of the executor functions of a stage, summed by name. WallTime is the time in
+// seconds from the first Before to the last After of its functions.
+type StageMetrics struct {
+	Name       string           `json:"name"`
+	WallTime   float64          `json:"wall_time"`
+	Partitions int64            `json:"partitions"`
+	Counters   map[string]int64 `json:"counters"`
+
+	start int64
+}
+
+var metricsDir string
+var metricsStage string
+var progressDir string
+var progressInterval time.Duration
+
+// EnableMetrics makes the executor functions with counters (the readers, Seq, Grep, the stores and the records
+// in and out of the filters) save them to dir, which must be shared with the executors. An empty dir disables
+// the metrics.
+func EnableMetrics(dir string) {
+	metricsDir = dir
+}
+
+// EnableProgress makes the readers save their counters to dir, which must be shared with the executors, and
+// a snapshot of them at most every interval while they run, see ReadProgress. The other functions only count
+// with EnableMetrics. An empty dir disables the progress.
+func EnableProgress(dir string, interval time.Duration) {
+	progressDir = dir
+	progressInterval = interval
+}
+
+// MetricsStage sets the stage of the functions created from now on, e.g. the command or the pipe node.
+func MetricsStage(name string) {
+	metricsStage = name
+}
+
+// metricsSource is a libSource of a function with counters, with the metrics directory and stage if the
+// metrics are enabled.
+func metricsSource(name string) (*api.ISource, error) {
+	return countersSource(name, metricsDir)
+}
+
+// readerSource is metricsSource of a reader, whose counters are also the progress. The progress directory is
+// used if only the progress is enabled.
+func readerSource(name string) (*api.ISource, error) {
+	dir := metricsDir
+	if dir == "" {
+		dir = progressDir
+	}
+	src, err := countersSource(name, dir)
+	if err != nil || dir == "" || progressDir == "" {
+		return src, err
+	}
+	return api.AddParam(src, "progress", int64(progressInterval))
+}
+
+func countersSource(name string, dir string) (*api.ISource, error) {
+	src := libSource(name)
+	if dir == "" {
+		return src, nil
+	}
+	src, err := api.AddParam(src, "metrics", dir)
+	if err != nil {
+		return nil, err
+	}
+	return api.AddParam(src, "stage", metricsStage)
+}
+
+// countRecords counts the records of the frame in the counter name (e.g. records_in) of the current stage, if
+// the metrics are enabled.
+func countRecords(input *api.IDataFrame[string], name string) (*api.IDataFrame[string], error) {
+	if metricsDir == "" {
+		return input, nil
+	}
+	counter, err := metricsSource("RecordCounter")
+	if err != nil {
+		return nil, err
+	}
+	counter, err = api.AddParam(counter, "counter", name)
+	if err != nil {
+		return nil, err
+	}
+	return api.MapPartitions[string, string](input, counter)
+}
+
+// CollectMetrics reads the records of the metrics directory and returns the metrics of every stage, in order
+// of start.
+func CollectMetrics() ([]StageMetrics, error) {
+	records, err := readMetrics(metricsDir, false)
+	if err != nil {
+		return nil, err
+	}
+	return stageMetrics(records), nil
+}
+
+// ReadProgress sums the counters of the readers while the job runs, with the snapshots of the readers that
+// have not ended, e.g. the partitions and the bytes_in read until now. The stage is named "read".
+func ReadProgress() (StageMetrics, error) {
+	dir := metricsDir
+	if dir == "" {
+		dir = progressDir
+	}
+	records, err := readMetrics(dir, true)
+	if err != nil {
+		return StageMetrics{}, err
+	}
+	read := StageMetrics{Name: "read", Counters: make(map[string]int64)}
+	for _, record := range records {
+		if record.Function != "ReadFixer" {
+			continue
+		}
+		read.Partitions += record.Partitions
+		for name, n := range record.Counters {
+			read.Counters[name] += n
+		}
+	}
+	return read, nil
+}
+
+// readMetrics reads the records of dir, and the snapshots of the functions that have not ended if progress.
+func readMetrics(dir string, progress bool) ([]MetricsRecord, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+MetricsExt))
+	if err != nil {
+		return nil, err
+	}
+	if progress {
+		running, err := filepath.Glob(filepath.Join(dir, "*"+MetricsProgressExt))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, running...)
+	}
+	records := make([]MetricsRecord, 0, len(files))
+	seen := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if progress && os.IsNotExist(err) {
+			continue // the function ended and removed its snapshot
+		} else if err != nil {
+			return nil, err
+		}
+		var record MetricsRecord
+		if err = json.Unmarshal(data, &record); err != nil {
+			return nil, err
+		}
+		id := fmt.Sprintf("%s-%d-%d", record.Function, record.Executor, record.Start)
+		if seen[id] {
+			continue // the snapshot of a function that ended after the glob
+		}
+		seen[id] = true
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// stageMetrics sums the records by stage, in order of start.
+func stageMetrics(records []MetricsRecord) []StageMetrics {
+	stages := make(map[string]*StageMetrics)
+	ends := make(map[string]int64)
+	for _, record := range records {
+		stage, found := stages[record.Stage]
+		if !found {
+			stage = &StageMetrics{Name: record.Stage, Counters: make(map[string]int64), start: record.Start}
+			stages[record.Stage] = stage
+		}
+		stage.Partitions += record.Partitions
+		for name, n := range record.Counters {
+			stage.Counters[name] += n
+		}
+		if record.Start < stage.start {
+			stage.start = record.Start
+		}
+		if record.End > ends[record.Stage] {
+			ends[record.Stage] = record.End
+		}
+	}
+
+	result := make([]StageMetrics, 0, len(stages))
+	for name, stage := range stages {
+		stage.WallTime = float64(ends[name]-stage.start) / 1e9
+		result = append(result, *stage)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].start < result[j].start })
+	return result
+}
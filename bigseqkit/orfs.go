@@ -0,0 +1,117 @@
+package bigseqkit
+
+import (
+	"github.com/shenwei356/bio/seq"
+	"ignis/driver/api"
+)
+
+type SeqKitOrfsOptions struct {
+	inner OrfsOptions
+}
+
+type OrfsOptions struct {
+	Config      KitConfig
+	TranslTable *int
+	Frame       *[]string
+	MinLen      *int
+	Start       *string
+	Nested      *bool
+	OutFormat   *string
+}
+
+func (this *OrfsOptions) setDefaults() *OrfsOptions {
+	this.Config.setDefaults()
+	setDefault(&this.TranslTable, 1)
+	setDefault(&this.Frame, []string{"6"})
+	setDefault(&this.MinLen, 90)
+	setDefault(&this.Start, "atg")
+	setDefault(&this.Nested, false)
+	setDefault(&this.OutFormat, "nucl")
+
+	return this
+}
+
+func (this *OrfsOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if _, ok := seq.CodonTables[*this.TranslTable]; !ok {
+		return optionError("TranslTable", "invalid translate table: %d", *this.TranslTable)
+	}
+	if *this.Start != "atg" && *this.Start != "alt" && *this.Start != "none" {
+		return optionError("Start", "invalid start codons: %s. available values: 'atg', 'alt', 'none'", *this.Start)
+	}
+	switch *this.OutFormat {
+	case "nucl", "protein", "bed", "gtf":
+	default:
+		return optionError("OutFormat", "invalid output format: %s. available values: 'nucl', 'protein', 'bed', 'gtf'", *this.OutFormat)
+	}
+	if *this.MinLen < 0 {
+		return optionError("MinLen", "value of flag -m (--min-len) should not be negative")
+	}
+	return validateFrames(*this.Frame)
+}
+
+func (this *SeqKitOrfsOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitOrfsOptions) Config(v *SeqKitConfig) *SeqKitOrfsOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+func (this *SeqKitOrfsOptions) TranslTable(v int) *SeqKitOrfsOptions {
+	this.inner.TranslTable = &v
+	return this
+}
+
+// Frame sets the frames to search, like Translate: 1, 2, 3, -1, -2, -3, and 6 for all six frames.
+func (this *SeqKitOrfsOptions) Frame(v []string) *SeqKitOrfsOptions {
+	this.inner.Frame = &v
+	return this
+}
+
+// MinLen sets the minimum length of the ORFs in nucleotides, the stop codon is not counted.
+func (this *SeqKitOrfsOptions) MinLen(v int) *SeqKitOrfsOptions {
+	this.inner.MinLen = &v
+	return this
+}
+
+// Start sets the start codons: "atg", "alt" (the initiation codons of the translate table) or "none"
+// (ORFs span from stop codon to stop codon).
+func (this *SeqKitOrfsOptions) Start(v string) *SeqKitOrfsOptions {
+	this.inner.Start = &v
+	return this
+}
+
+// Nested outputs an ORF for every start codon instead of only the longest ORF of every stop codon.
+func (this *SeqKitOrfsOptions) Nested(v bool) *SeqKitOrfsOptions {
+	this.inner.Nested = &v
+	return this
+}
+
+// OutFormat sets the output: "nucl" or "protein" FASTA, or "bed" or "gtf" lines with the coordinates
+// on the source records.
+func (this *SeqKitOrfsOptions) OutFormat(v string) *SeqKitOrfsOptions {
+	this.inner.OutFormat = &v
+	return this
+}
+
+func Orfs(input *api.IDataFrame[string], o *SeqKitOrfsOptions) (*api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitOrfsOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libprepare, err := api.AddParam(libSource("Orfs"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return api.MapPartitions[string, string](input, libprepare)
+}
@@ -21,6 +21,15 @@ func (this *PairOptions) setDefaults() *PairOptions {
 	return this
 }
 
+func (this *PairOptions) Validate() error {
+	return this.Config.Validate()
+}
+
+func (this *SeqKitPairOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitPairOptions) Config(v *SeqKitConfig) *SeqKitPairOptions {
 	this.inner.Config = v.inner
 	return this
@@ -74,7 +83,9 @@ func Pair(inputA *api.IDataFrame[string], inputB *api.IDataFrame[string], o *Seq
 		o = &SeqKitPairOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err = opts.setDefaults().Validate(); err != nil {
+		return nil, nil, nil, err
+	}
 
 	cache, err = commonPair(inputA, inputB, &opts)
 	if err != nil {
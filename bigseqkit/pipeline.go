@@ -0,0 +1,231 @@
+package bigseqkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"ignis/driver/api"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// PipelineStage is a stage of a Pipeline. Run receives the outputs of Inputs, in the same order, and returns
+// the output of the stage, nil if the stage produces no sequences. Files are the files read by the stage
+// and Options its options, e.g. from OptionsToString, both are part of the fingerprint of the stage.
+type PipelineStage struct {
+	Name       string
+	Inputs     []string
+	Files      []string
+	Options    string
+	Checkpoint bool
+	Run        func(inputs []*api.IDataFrame[string]) (*api.IDataFrame[string], error)
+
+	fingerprint string
+	done        bool
+	resumed     bool
+	output      *api.IDataFrame[string]
+}
+
+// PipelineFile is the fingerprint of an input file.
+type PipelineFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+}
+
+// PipelineManifest is saved next to the output of every checkpointed stage. A stage is resumed when the
+// fingerprint of its manifest matches the current one.
+type PipelineManifest struct {
+	Stage       string            `json:"stage"`
+	Files       []PipelineFile    `json:"files"`
+	Options     string            `json:"options"`
+	Inputs      map[string]string `json:"inputs"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+// Pipeline runs stages lazily, the output of a stage is computed when it is requested. The outputs of
+// checkpointed stages are persisted to the work directory, and reused by later runs while their files,
+// options and inputs have not changed.
+type Pipeline struct {
+	worker  *api.IWorker
+	workDir string
+	stages  map[string]*PipelineStage
+}
+
+func NewPipeline(worker *api.IWorker, workDir string) *Pipeline {
+	return &Pipeline{
+		worker:  worker,
+		workDir: workDir,
+		stages:  make(map[string]*PipelineStage),
+	}
+}
+
+// AddStage adds a stage, its inputs must be added before it.
+func (this *Pipeline) AddStage(stage *PipelineStage) error {
+	if stage.Name == "" {
+		return fmt.Errorf("stage name needed")
+	}
+	if _, ok := this.stages[stage.Name]; ok {
+		return fmt.Errorf("duplicated stage: %s", stage.Name)
+	}
+	if stage.Run == nil {
+		return fmt.Errorf("stage %s: run function needed", stage.Name)
+	}
+	if stage.Checkpoint && this.workDir == "" {
+		return fmt.Errorf("stage %s: work directory needed for checkpoints", stage.Name)
+	}
+	for _, in := range stage.Inputs {
+		if _, ok := this.stages[in]; !ok {
+			return fmt.Errorf("stage %s: unknown input: %s", stage.Name, in)
+		}
+	}
+	this.stages[stage.Name] = stage
+	return nil
+}
+
+// Resumed returns whether the output of the stage was loaded from its checkpoint.
+func (this *Pipeline) Resumed(name string) bool {
+	stage, ok := this.stages[name]
+	return ok && stage.resumed
+}
+
+// Resumable returns whether the stage has a valid checkpoint, its output would be loaded without running it.
+func (this *Pipeline) Resumable(name string) (bool, error) {
+	stage, ok := this.stages[name]
+	if !ok {
+		return false, fmt.Errorf("unknown stage: %s", name)
+	}
+	manifest, err := this.manifest(stage)
+	if err != nil {
+		return false, err
+	}
+	return this.resumable(stage, manifest), nil
+}
+
+func (this *Pipeline) resumable(stage *PipelineStage, manifest *PipelineManifest) bool {
+	if !stage.Checkpoint {
+		return false
+	}
+	dir := filepath.Join(this.workDir, stage.Name)
+	data, err := os.ReadFile(dir + ".manifest.json")
+	if err != nil {
+		return false
+	}
+	var old PipelineManifest
+	if json.Unmarshal(data, &old) != nil || old.Fingerprint != manifest.Fingerprint {
+		return false
+	}
+	_, err = os.Stat(dir)
+	return err == nil
+}
+
+// Output returns the output of a stage, running it and the stages it depends on if needed.
+func (this *Pipeline) Output(name string) (*api.IDataFrame[string], error) {
+	stage, ok := this.stages[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown stage: %s", name)
+	}
+	if stage.done {
+		return stage.output, nil
+	}
+
+	manifest, err := this.manifest(stage)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(this.workDir, stage.Name)
+	manifestFile := dir + ".manifest.json"
+
+	if this.resumable(stage, manifest) {
+		if stage.output, err = this.worker.PartitionTextFile(dir); err != nil {
+			return nil, err
+		}
+		stage.done, stage.resumed = true, true
+		return stage.output, nil
+	}
+
+	inputs := make([]*api.IDataFrame[string], len(stage.Inputs))
+	for i, in := range stage.Inputs {
+		if inputs[i], err = this.Output(in); err != nil {
+			return nil, err
+		}
+		if inputs[i] == nil {
+			return nil, fmt.Errorf("stage %s: input %s produces no sequences", stage.Name, in)
+		}
+	}
+	if stage.output, err = stage.Run(inputs); err != nil {
+		return nil, fmt.Errorf("stage %s: %s", stage.Name, err)
+	}
+
+	if stage.Checkpoint {
+		if stage.output == nil {
+			return nil, fmt.Errorf("stage %s: checkpointed stages must produce sequences", stage.Name)
+		}
+		// the manifest is written after the output, interrupted checkpoints are never resumed
+		if err = os.Remove(manifestFile); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err = os.RemoveAll(dir); err != nil {
+			return nil, err
+		}
+		if err = os.MkdirAll(this.workDir, 0755); err != nil {
+			return nil, err
+		}
+		if err = stage.output.Cache(); err != nil {
+			return nil, err
+		}
+		if err = StoreFASTXN(stage.output, dir); err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err = os.WriteFile(manifestFile, data, 0644); err != nil {
+			return nil, err
+		}
+	}
+	stage.done = true
+	return stage.output, nil
+}
+
+// manifest computes the fingerprint of a stage from its files, options and the fingerprints of its inputs.
+func (this *Pipeline) manifest(stage *PipelineStage) (*PipelineManifest, error) {
+	manifest := &PipelineManifest{
+		Stage:   stage.Name,
+		Files:   make([]PipelineFile, 0, len(stage.Files)),
+		Options: stage.Options,
+		Inputs:  make(map[string]string, len(stage.Inputs)),
+	}
+	for _, in := range stage.Inputs {
+		dep := this.stages[in]
+		if dep.fingerprint == "" {
+			if _, err := this.manifest(dep); err != nil {
+				return nil, err
+			}
+		}
+		manifest.Inputs[in] = dep.fingerprint
+	}
+	for _, file := range stage.Files {
+		err := filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				manifest.Files = append(manifest.Files, PipelineFile{path, info.Size(), info.ModTime().UnixNano()})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("stage %s: %s", stage.Name, err)
+		}
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+
+	sum := sha256.Sum256([]byte(OptionsToString(manifest)))
+	manifest.Fingerprint = hex.EncodeToString(sum[:])
+	stage.fingerprint = manifest.Fingerprint
+	return manifest, nil
+}
@@ -0,0 +1,218 @@
+package bigseqkit
+
+import (
+	"ignis/driver/api"
+	"math"
+)
+
+type SeqKitProteinStatsOptions struct {
+	inner ProteinStatsOptions
+}
+
+type ProteinStatsOptions struct {
+	Config KitConfig
+}
+
+func (this *ProteinStatsOptions) setDefaults() *ProteinStatsOptions {
+	this.Config.setDefaults()
+
+	return this
+}
+
+func (this *ProteinStatsOptions) Validate() error {
+	return this.Config.Validate()
+}
+
+func (this *SeqKitProteinStatsOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitProteinStatsOptions) Config(v *SeqKitConfig) *SeqKitProteinStatsOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+// Keys of the amino-acid counters that are not residues.
+const (
+	ProteinStatsRecords = int64(-1) // number of records
+)
+
+// Average masses of the residues (amino acid minus water), in Daltons.
+var proteinResidueMass = map[byte]float64{
+	'A': 71.0788, 'R': 156.1875, 'N': 114.1038, 'D': 115.0886, 'C': 103.1388,
+	'E': 129.1155, 'Q': 128.1307, 'G': 57.0519, 'H': 137.1411, 'I': 113.1594,
+	'L': 113.1594, 'K': 128.1741, 'M': 131.1926, 'F': 147.1766, 'P': 97.1167,
+	'S': 87.0782, 'T': 101.1051, 'W': 186.2132, 'Y': 163.1760, 'V': 99.1326,
+	'U': 150.0388, 'O': 237.3018,
+}
+
+// Kyte-Doolittle hydropathy of the amino acids.
+var proteinHydropathy = map[byte]float64{
+	'A': 1.8, 'R': -4.5, 'N': -3.5, 'D': -3.5, 'C': 2.5,
+	'E': -3.5, 'Q': -3.5, 'G': -0.4, 'H': -3.2, 'I': 4.5,
+	'L': 3.8, 'K': -3.9, 'M': 1.9, 'F': 2.8, 'P': -1.6,
+	'S': -0.8, 'T': -0.7, 'W': -0.9, 'Y': -1.3, 'V': 4.2,
+}
+
+// EMBOSS pKa values of the charged groups.
+const (
+	pKaNTerm = 8.6
+	pKaCTerm = 3.6
+)
+
+var proteinPositivePKa = map[byte]float64{'K': 10.8, 'R': 12.5, 'H': 6.5}
+var proteinNegativePKa = map[byte]float64{'D': 3.9, 'E': 4.1, 'C': 8.5, 'Y': 10.1}
+
+// ProteinInfo holds the amino-acid composition and the properties of a protein or a set of proteins.
+type ProteinInfo struct {
+	Records int64
+	Counts  map[byte]int64 // residue counts in upper case, stop codons and gaps are not counted
+}
+
+// NewProteinInfo builds the protein information of the counters computed by the executors.
+func NewProteinInfo(counts map[int64]int64) *ProteinInfo {
+	info := &ProteinInfo{Records: counts[ProteinStatsRecords], Counts: make(map[byte]int64, len(counts))}
+	for k, v := range counts {
+		if k >= 0 {
+			info.Counts[byte(k)] = v
+		}
+	}
+	return info
+}
+
+// CountResidues counts the residues of a sequence, stops ('*') and gaps ('-', '.') are ignored.
+func CountResidues(sequence []byte, counts map[byte]int64) {
+	for _, b := range sequence {
+		if b == '*' || b == '-' || b == '.' {
+			continue
+		}
+		if 'a' <= b && b <= 'z' {
+			b &^= 0x20
+		}
+		counts[b]++
+	}
+}
+
+// Length returns the number of residues.
+func (this *ProteinInfo) Length() int64 {
+	n := int64(0)
+	for _, c := range this.Counts {
+		n += c
+	}
+	return n
+}
+
+// Composition returns the fraction of every residue.
+func (this *ProteinInfo) Composition() map[byte]float64 {
+	n := float64(this.Length())
+	composition := make(map[byte]float64, len(this.Counts))
+	for aa, c := range this.Counts {
+		composition[aa] = float64(c) / n
+	}
+	return composition
+}
+
+// MolecularWeight returns the average molecular weight in Daltons, a water per record is added.
+// Unknown residues take the mean residue mass.
+func (this *ProteinInfo) MolecularWeight() float64 {
+	mw := 18.01528 * float64(this.Records)
+	for aa, c := range this.Counts {
+		mass, ok := proteinResidueMass[aa]
+		if !ok {
+			mass = 110
+		}
+		mw += mass * float64(c)
+	}
+	return mw
+}
+
+// charge returns the net charge at a pH.
+func (this *ProteinInfo) charge(pH float64) float64 {
+	records := float64(this.Records)
+	charge := records/(1+math.Pow(10, pH-pKaNTerm)) - records/(1+math.Pow(10, pKaCTerm-pH))
+	for aa, pKa := range proteinPositivePKa {
+		charge += float64(this.Counts[aa]) / (1 + math.Pow(10, pH-pKa))
+	}
+	for aa, pKa := range proteinNegativePKa {
+		charge -= float64(this.Counts[aa]) / (1 + math.Pow(10, pKa-pH))
+	}
+	return charge
+}
+
+// IsoelectricPoint returns the pH with net charge 0, with the EMBOSS pKa values.
+func (this *ProteinInfo) IsoelectricPoint() float64 {
+	low, high := 0.0, 14.0
+	for high-low > 0.001 {
+		mid := (low + high) / 2
+		if this.charge(mid) > 0 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// Gravy returns the grand average of hydropathy (Kyte-Doolittle), unknown residues are not counted.
+func (this *ProteinInfo) Gravy() float64 {
+	sum, n := 0.0, int64(0)
+	for aa, c := range this.Counts {
+		if h, ok := proteinHydropathy[aa]; ok {
+			sum += h * float64(c)
+			n += c
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// ProteinStats computes the amino-acid composition of protein sequences. Every partition counts its residues
+// and the counters are merged in a reduce. Cache the input to also call ProteinStatsPerRecord, both read it.
+func ProteinStats(input *api.IDataFrame[string], o *SeqKitProteinStatsOptions) (*ProteinInfo, error) {
+	if o == nil {
+		o = &SeqKitProteinStatsOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libCount, err := api.AddParam(libSource("ProteinStats"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := api.MapPartitions[string, map[int64]int64](input, libCount)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := counts.Reduce(libSource("MapSumReduce"))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProteinInfo(total), nil
+}
+
+// ProteinStatsPerRecord returns the properties of every record, a line per record: ID, length, molecular weight,
+// isoelectric point and GRAVY, separated by tabs.
+func ProteinStatsPerRecord(input *api.IDataFrame[string], o *SeqKitProteinStatsOptions) (*api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitProteinStatsOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libRecords, err := api.AddParam(libSource("ProteinStatsRecords"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return api.MapPartitions[string, string](input, libRecords)
+}
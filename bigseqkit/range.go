@@ -1,7 +1,6 @@
 package bigseqkit
 
 import (
-	"fmt"
 	"ignis/driver/api"
 	"strconv"
 	"strings"
@@ -23,6 +22,39 @@ func (this *RangeOptions) setDefaults() *RangeOptions {
 	return this
 }
 
+func (this *RangeOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.Range == "" {
+		return optionError("Range", "flag -r (--range) needed")
+	}
+	r := strings.Split(*this.Range, ":")
+	if len(r) > 2 {
+		return optionError("Range", "invalid range: %s", *this.Range)
+	}
+	end := int64(-1)
+	start, err := strconv.ParseInt(r[0], 10, 64)
+	if err == nil && len(r) > 1 {
+		end, err = strconv.ParseInt(r[1], 10, 64)
+	}
+	if err != nil {
+		return optionError("Range", "invalid range: %s", *this.Range)
+	}
+	if start == 0 || end == 0 {
+		return optionError("Range", "either start and end should not be 0")
+	}
+	if (start > 0) == (end > 0) && start > end {
+		return optionError("Range", "start should not be greater than end")
+	}
+	return nil
+}
+
+func (this *SeqKitRangeOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitRangeOptions) Config(v *SeqKitConfig) *SeqKitRangeOptions {
 	this.inner.Config = v.inner
 	return this
@@ -38,10 +70,12 @@ func Range(input *api.IDataFrame[string], o *SeqKitRangeOptions) (*api.IDataFram
 		o = &SeqKitRangeOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
-
-	if *opts.Range == "" {
-		return nil, fmt.Errorf("flag -r (--range) needed")
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+	input, err := countRecords(input, "records_in")
+	if err != nil {
+		return nil, err
 	}
 
 	r := strings.Split(*opts.Range, ":")
@@ -57,10 +91,6 @@ func Range(input *api.IDataFrame[string], o *SeqKitRangeOptions) (*api.IDataFram
 		}
 	}
 
-	if start == 0 || end == 0 {
-		return nil, fmt.Errorf("either start and end should not be 0")
-	}
-
 	if start > 0 {
 		start--
 	}
@@ -82,8 +112,8 @@ func Range(input *api.IDataFrame[string], o *SeqKitRangeOptions) (*api.IDataFram
 		}
 	}
 
-	if start <= end {
-		return nil, fmt.Errorf("start must be > than end")
+	if start > end {
+		return nil, OptionErrorf("start should not be greater than end")
 	}
 
 	libprepare := libSource("RangePrepare")
@@ -100,5 +130,9 @@ func Range(input *api.IDataFrame[string], o *SeqKitRangeOptions) (*api.IDataFram
 		return nil, err
 	}
 
-	return prepared.Filter(libSource("RangeFilter"))
+	output, err := prepared.Filter(libSource("RangeFilter"))
+	if err != nil {
+		return nil, err
+	}
+	return countRecords(output, "records_out")
 }
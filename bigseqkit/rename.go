@@ -21,6 +21,15 @@ func (this *RenameOptions) setDefaults() *RenameOptions {
 	return this
 }
 
+func (this *RenameOptions) Validate() error {
+	return this.Config.Validate()
+}
+
+func (this *SeqKitRenameOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitRenameOptions) Config(v *SeqKitConfig) *SeqKitRenameOptions {
 	this.inner.Config = v.inner
 	return this
@@ -36,7 +45,9 @@ func Rename(input *api.IDataFrame[string], o *SeqKitRenameOptions) (*api.IDataFr
 		o = &SeqKitRenameOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 
 	libprepare, err := api.AddParam(libSource("RenamePrepare"), "opts", OptionsToString(opts))
 	if err != nil {
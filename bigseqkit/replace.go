@@ -1,6 +1,11 @@
 package bigseqkit
 
-import "ignis/driver/api"
+import (
+	"ignis/driver/api"
+	"regexp"
+)
+
+var reReplaceKV = regexp.MustCompile(`\{(KV|kv)\}`)
 
 type SeqKitReplaceOptions struct {
 	inner ReplaceOptions
@@ -36,6 +41,50 @@ func (this *ReplaceOptions) setDefaults() *ReplaceOptions {
 	return this
 }
 
+func (this *ReplaceOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.Pattern == "" {
+		return optionError("Pattern", "flags -p (--pattern) needed")
+	}
+	if _, err := regexp.Compile(*this.Pattern); err != nil {
+		return optionError("Pattern", "invalid value of flag -p (--pattern): %s", err)
+	}
+	if *this.NrWidth <= 0 {
+		return optionError("NrWidth", "value of flag --nr-width should be greater than 0")
+	}
+	withKV := reReplaceKV.MatchString(*this.Replacement)
+	if *this.KvFile != "" {
+		if len(*this.Replacement) == 0 {
+			return optionError("Replacement", "flag -r (--replacement) needed when given flag -k (--kv-file)")
+		}
+		if !withKV {
+			return optionError("Replacement", `replacement symbol "{kv}"/"{KV}" not found in value of flag -r (--replacement) when flag -k (--kv-file) given`)
+		}
+	}
+	if withKV {
+		if !regexp.MustCompile(`\(.+\)`).MatchString(*this.Pattern) {
+			return optionError("Pattern", `value of -p (--pattern) must contains "(" and ")" to capture data which is used specify the KEY`)
+		}
+		if *this.BySeq {
+			return optionError("BySeq", `replaceing with key-value pairs was not supported for sequence`)
+		}
+		if *this.KvFile == "" {
+			return optionError("KvFile", `since replacement symbol "{kv}"/"{KV}" found in value of flag -r (--replacement), tab-delimited key-value file should be given by flag -k (--kv-file)`)
+		}
+		if *this.KeyCaptIdx < 1 {
+			return optionError("KeyCaptIdx", "value of flag -I (--key-capt-idx) should be greater than 0")
+		}
+	}
+	return nil
+}
+
+func (this *SeqKitReplaceOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitReplaceOptions) Config(v *SeqKitConfig) *SeqKitReplaceOptions {
 	this.inner.Config = v.inner
 	return this
@@ -96,7 +145,9 @@ func Replace(input *api.IDataFrame[string], o *SeqKitReplaceOptions) (*api.IData
 		o = &SeqKitReplaceOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 	libprepare, err := api.AddParam(libSource("Replace"), "opts", OptionsToString(opts))
 	if err != nil {
 		return nil, err
@@ -1,9 +1,10 @@
 package bigseqkit
 
 import (
-	"fmt"
+	"encoding/json"
 	"ignis/driver/api"
 	"ignis/executor/api/ipair"
+	"regexp"
 )
 
 type SeqKitRmDupOptions struct {
@@ -18,6 +19,43 @@ type RmDupOptions struct {
 	DupSeqsFile        *string
 	DupNumFile         *string
 	OnlyPositiveStrand *bool
+	Optical            *bool
+	IlluminaRegexp     *string
+	PixelDistance      *int
+	PrefixLength       *int
+	RemoveClass        *string
+}
+
+// DefaultIlluminaRegexp captures lane, tile, x and y from Illumina read names
+// (instrument:run:flowcell:lane:tile:x:y).
+const DefaultIlluminaRegexp = `^[^:\s]+:\d+:[^:\s]+:(\d+):(\d+):(\d+):(\d+)`
+
+// Keys of the optical duplicate counters.
+const (
+	OpticalDupReads   = int64(0) // number of reads (read pairs in paired mode)
+	OpticalDupOptical = int64(1) // optical duplicates
+	OpticalDupPCR     = int64(2) // PCR duplicates
+)
+
+// OpticalDupInfo holds the read counts of the optical/PCR duplicate classification.
+type OpticalDupInfo struct {
+	Reads   int64
+	Optical int64
+	PCR     int64
+}
+
+func (this *OpticalDupInfo) OpticalRate() float64 {
+	if this.Reads == 0 {
+		return 0
+	}
+	return float64(this.Optical) / float64(this.Reads)
+}
+
+func (this *OpticalDupInfo) PCRRate() float64 {
+	if this.Reads == 0 {
+		return 0
+	}
+	return float64(this.PCR) / float64(this.Reads)
 }
 
 func (this *RmDupOptions) setDefaults() *RmDupOptions {
@@ -28,10 +66,60 @@ func (this *RmDupOptions) setDefaults() *RmDupOptions {
 	setDefault(&this.DupSeqsFile, "")
 	setDefault(&this.DupNumFile, "")
 	setDefault(&this.OnlyPositiveStrand, false)
+	setDefault(&this.Optical, false)
+	setDefault(&this.IlluminaRegexp, DefaultIlluminaRegexp)
+	setDefault(&this.PixelDistance, 100)
+	setDefault(&this.PrefixLength, 0)
+	setDefault(&this.RemoveClass, "all")
 
 	return this
 }
 
+func (this *RmDupOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.BySeq && *this.ByName {
+		return OptionErrorf("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
+	}
+	if *this.OnlyPositiveStrand && !*this.BySeq && !*this.Optical {
+		return optionError("OnlyPositiveStrand", "flag -s (--by-seq) needed when using -P (--only-positive-strand)")
+	}
+	if *this.Optical && *this.ByName {
+		return optionError("ByName", "flag -n (--by-name) is not allowed when detecting optical duplicates")
+	}
+	if *this.Optical && *this.DupSeqsFile != "" {
+		return optionError("DupSeqsFile", "flag -d (--dup-seqs-file) is not allowed when detecting optical duplicates")
+	}
+	if *this.Optical && *this.DupNumFile != "" {
+		return optionError("DupNumFile", "flag -D (--dup-num-file) is not allowed when detecting optical duplicates")
+	}
+	if *this.PixelDistance < 0 {
+		return optionError("PixelDistance", "value of flag --pixel-distance should be non-negative")
+	}
+	if *this.PrefixLength < 0 {
+		return optionError("PrefixLength", "value of flag --prefix-length should be non-negative")
+	}
+	switch *this.RemoveClass {
+	case "all", "optical", "pcr":
+	default:
+		return optionError("RemoveClass", "invalid value of flag --remove-class: %s. available values: 'all', 'optical', 'pcr'", *this.RemoveClass)
+	}
+	re, err := regexp.Compile(*this.IlluminaRegexp)
+	if err != nil {
+		return optionError("IlluminaRegexp", "fail to compile regexp: %s", *this.IlluminaRegexp)
+	}
+	if re.NumSubexp() != 4 {
+		return optionError("IlluminaRegexp", "illumina regexp must capture lane, tile, x and y: %s", *this.IlluminaRegexp)
+	}
+	return nil
+}
+
+func (this *SeqKitRmDupOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitRmDupOptions) Config(v *SeqKitConfig) *SeqKitRmDupOptions {
 	this.inner.Config = v.inner
 	return this
@@ -67,24 +155,134 @@ func (this *SeqKitRmDupOptions) OnlyPositiveStrand(v bool) *SeqKitRmDupOptions {
 	return this
 }
 
+func (this *SeqKitRmDupOptions) Optical(v bool) *SeqKitRmDupOptions {
+	this.inner.Optical = &v
+	return this
+}
+
+func (this *SeqKitRmDupOptions) IlluminaRegexp(v string) *SeqKitRmDupOptions {
+	this.inner.IlluminaRegexp = &v
+	return this
+}
+
+func (this *SeqKitRmDupOptions) PixelDistance(v int) *SeqKitRmDupOptions {
+	this.inner.PixelDistance = &v
+	return this
+}
+
+func (this *SeqKitRmDupOptions) PrefixLength(v int) *SeqKitRmDupOptions {
+	this.inner.PrefixLength = &v
+	return this
+}
+
+func (this *SeqKitRmDupOptions) RemoveClass(v string) *SeqKitRmDupOptions {
+	this.inner.RemoveClass = &v
+	return this
+}
+
 func RmDup(input *api.IDataFrame[string], o *SeqKitRmDupOptions) (*api.IDataFrame[string], error) {
 	if o == nil {
 		o = &SeqKitRmDupOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+	input, err := countRecords(input, "records_in")
+	if err != nil {
+		return nil, err
+	}
+
+	if *opts.Optical {
+		grouped, err := opticalGroup(input, &opts, false)
+		if err != nil {
+			return nil, err
+		}
+		output, err := opticalCheck(grouped, &opts)
+		if err != nil {
+			return nil, err
+		}
+		return countRecords(output, "records_out")
+	}
+
+	grouped, err := rmDupGroup(input, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := api.AddParam(libSource("RmDupCheck"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := api.Flatmap[ipair.IPair[int64, []string], string](grouped, check)
+	if err != nil {
+		return nil, err
+	}
+	return countRecords(output, "records_out")
+}
+
+// DupReport groups the records like RmDup but, instead of dropping the duplicates, returns one tab-separated
+// line for every duplicated key: the name of the representative (first) record, the number of copies,
+// the comma-separated IDs of the duplicates and the representative sequence (and qualities for FASTQ).
+// The duplication-level histogram (copies -> number of distinct keys seen that many times) is counted by
+// every partition in the same pass that builds the lines and merged here, so the input is only shuffled once.
+func DupReport(input *api.IDataFrame[string], o *SeqKitRmDupOptions) (*api.IDataFrame[string], map[int64]int64, error) {
+	if o == nil {
+		o = &SeqKitRmDupOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, nil, err
+	}
 
-	revcom := !*opts.OnlyPositiveStrand
+	grouped, err := rmDupGroup(input, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if *opts.BySeq && *opts.ByName {
-		return nil, fmt.Errorf("only one/none of the flags -s (--by-seq) and -n (--by-name) is allowed")
+	libReport, err := api.AddParam(libSource("RmDupReport"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if !revcom && !*opts.BySeq {
-		return nil, fmt.Errorf("flag -s (--by-seq) needed when using -P (--only-positive-strand)")
+	tagged, err := api.MapPartitions[ipair.IPair[int64, []string], ipair.IPair[string, string]](grouped, libReport)
+	if err != nil {
+		return nil, nil, err
 	}
+	if err = tagged.Cache(); err != nil {
+		return nil, nil, err
+	}
+
+	levels, err := UnpairedId(tagged, "histogram")
+	if err != nil {
+		return nil, nil, err
+	}
+	partitions, err := levels.Collect()
+	if err != nil {
+		return nil, nil, err
+	}
+	histogram := make(map[int64]int64)
+	for _, partition := range partitions {
+		counts := make(map[int64]int64)
+		if err = json.Unmarshal([]byte(partition), &counts); err != nil {
+			return nil, nil, err
+		}
+		for level, n := range counts {
+			histogram[level] += n
+		}
+	}
+
+	report, err := UnpairedId(tagged, "report")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return report, histogram, nil
+}
 
-	prepare, err := api.AddParam(libSource("RmDupPrepare"), "opts", OptionsToString(opts))
+func rmDupGroup(input *api.IDataFrame[string], opts *RmDupOptions) (*api.IDataFrame[ipair.IPair[int64, []string]], error) {
+	prepare, err := api.AddParam(libSource("RmDupPrepare"), "opts", OptionsToString(*opts))
 	if err != nil {
 		return nil, err
 	}
@@ -99,10 +297,144 @@ func RmDup(input *api.IDataFrame[string], o *SeqKitRmDupOptions) (*api.IDataFram
 		return nil, err
 	}
 
-	check, err := api.AddParam(libSource("RmDupCheck"), "opts", OptionsToString(opts))
+	return grouped.FromPair(), nil
+}
+
+// RmDupOptical classifies the reads with identical sequences (or identical first PrefixLength bases) into
+// optical duplicates, when they lie on the same lane and tile within PixelDistance pixels of another copy
+// according to the Illumina read-name coordinates, and PCR duplicates otherwise. The reads of the class
+// selected by RemoveClass (all, optical or pcr) are removed, and the counts of both classes are returned.
+func RmDupOptical(input *api.IDataFrame[string], o *SeqKitRmDupOptions) (*api.IDataFrame[string], *OpticalDupInfo, error) {
+	if o == nil {
+		o = &SeqKitRmDupOptions{}
+	}
+	opts := o.inner
+	optical := true
+	opts.Optical = &optical
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, nil, err
+	}
+	input, err := countRecords(input, "records_in")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grouped, err := opticalGroup(input, &opts, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := opticalCount(grouped, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := opticalCheck(grouped, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err = countRecords(result, "records_out")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, info, nil
+}
+
+// RmDupOpticalPairs is the paired-end version of RmDupOptical, it takes the mates joined by Pair and
+// compares the first PrefixLength bases of each mate. The coordinates are parsed from the first mate.
+func RmDupOpticalPairs(pairs *api.IDataFrame[ipair.IPair[string, string]], o *SeqKitRmDupOptions) (
+	*api.IDataFrame[ipair.IPair[string, string]], *OpticalDupInfo, error) {
+	if o == nil {
+		o = &SeqKitRmDupOptions{}
+	}
+	opts := o.inner
+	optical := true
+	opts.Optical = &optical
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	joined, err := api.Map[ipair.IPair[string, string], string](pairs, libSource("RmDupOpticalJoin"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grouped, err := opticalGroup(joined, &opts, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := opticalCount(grouped, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := opticalCheck(grouped, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	split, err := api.Map[string, ipair.IPair[string, string]](result, libSource("RmDupOpticalSplit"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return split, info, nil
+}
+
+func opticalGroup(input *api.IDataFrame[string], opts *RmDupOptions, paired bool) (*api.IDataFrame[ipair.IPair[int64, []string]], error) {
+	prepare, err := api.AddParam(libSource("RmDupOpticalPrepare"), "opts", OptionsToString(*opts))
+	if err != nil {
+		return nil, err
+	}
+
+	prepare, err = api.AddParam(prepare, "paired", paired)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared, err := api.MapPartitions[string, ipair.IPair[int64, string]](input, prepare)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped, err := api.GroupByKey[int64, string](api.ToPair[int64, string](prepared), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return grouped.FromPair(), nil
+}
+
+func opticalCount(grouped *api.IDataFrame[ipair.IPair[int64, []string]], opts *RmDupOptions) (*OpticalDupInfo, error) {
+	if err := grouped.Cache(); err != nil {
+		return nil, err
+	}
+
+	libCount, err := api.AddParam(libSource("RmDupOpticalCount"), "opts", OptionsToString(*opts))
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := api.MapPartitions[ipair.IPair[int64, []string], map[int64]int64](grouped, libCount)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := counts.Reduce(libSource("MapSumReduce"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpticalDupInfo{Reads: total[OpticalDupReads], Optical: total[OpticalDupOptical], PCR: total[OpticalDupPCR]}, nil
+}
+
+func opticalCheck(grouped *api.IDataFrame[ipair.IPair[int64, []string]], opts *RmDupOptions) (*api.IDataFrame[string], error) {
+	check, err := api.AddParam(libSource("RmDupOptical"), "opts", OptionsToString(*opts))
 	if err != nil {
 		return nil, err
 	}
 
-	return api.Flatmap[ipair.IPair[int64, []string], string](grouped.FromPair(), check)
+	return api.Flatmap[ipair.IPair[int64, []string], string](grouped, check)
 }
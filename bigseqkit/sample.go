@@ -1,7 +1,6 @@
 package bigseqkit
 
 import (
-	"fmt"
 	"ignis/driver/api"
 )
 
@@ -25,6 +24,27 @@ func (this *SampleOptions) setDefaults() *SampleOptions {
 	return this
 }
 
+func (this *SampleOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.Number == 0 && *this.Proportion == 0 {
+		return OptionErrorf("one of flags -n (--number) and -p (--proportion) needed")
+	}
+	if *this.Number < 0 {
+		return optionError("Number", "value of -n (--number) and should be greater than 0")
+	}
+	if *this.Proportion < 0 || *this.Proportion > 1 {
+		return optionError("Proportion", "value of -p (--proportion) (%f) should be in range of (0, 1]", *this.Proportion)
+	}
+	return nil
+}
+
+func (this *SeqKitSampleOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitSampleOptions) Config(v *SeqKitConfig) *SeqKitSampleOptions {
 	this.inner.Config = v.inner
 	return this
@@ -50,17 +70,12 @@ func Sample(input *api.IDataFrame[string], o *SeqKitSampleOptions) (*api.IDataFr
 		o = &SeqKitSampleOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
-
-	if *opts.Number == 0 && *opts.Proportion == 0 {
-		return nil, fmt.Errorf("one of flags -n (--number) and -p (--proportion) needed")
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
 	}
-
-	if *opts.Number < 0 {
-		return nil, fmt.Errorf("value of -n (--number) and should be greater than 0")
-	}
-	if *opts.Proportion < 0 || *opts.Proportion > 1 {
-		return nil, fmt.Errorf("value of -p (--proportion) (%f) should be in range of (0, 1]", *opts.Proportion)
+	input, err := countRecords(input, "records_in")
+	if err != nil {
+		return nil, err
 	}
 
 	fraction := float64(*opts.Proportion)
@@ -72,5 +87,9 @@ func Sample(input *api.IDataFrame[string], o *SeqKitSampleOptions) (*api.IDataFr
 		fraction = float64(*opts.Number) / float64(n)
 	}
 
-	return input.Sample(false, fraction, *opts.Seed)
+	output, err := input.Sample(false, fraction, *opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+	return countRecords(output, "records_out")
 }
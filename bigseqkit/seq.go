@@ -54,6 +54,41 @@ func (this *SeqOptions) setDefaults() *SeqOptions {
 	return this
 }
 
+func (this *SeqOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.GapLetters == "" {
+		return optionError("GapLetters", "value of flag -G (--gap-letters) should not be empty")
+	}
+	for _, c := range *this.GapLetters {
+		if c > 127 {
+			return optionError("GapLetters", "value of -G (--gap-letters) contains non-ASCII characters")
+		}
+	}
+	if *this.MinLen >= 0 && *this.MaxLen >= 0 && *this.MinLen > *this.MaxLen {
+		return optionError("MinLen", "value of flag -m (--min-len) should be <= value of flag -M (--max-len)")
+	}
+	if *this.ValidateSeqLength < 0 || *this.ValidateSeqLength > 0 && *this.ValidateSeqLength < 1000 {
+		return optionError("ValidateSeqLength", "value of flag -V (--validate-seq-length) should be 0 or >= 1000")
+	}
+	if *this.QualAsciiBase <= 0 {
+		return optionError("QualAsciiBase", "value of flag -b (--qual-ascii-base) should be greater than 0")
+	}
+	if *this.MinQual >= 0 && *this.MaxQual >= 0 && *this.MinQual > *this.MaxQual {
+		return optionError("MinQual", "value of flag -Q (--min-qual) should be <= value of flag -R (--max-qual)")
+	}
+	if *this.LowerCase && *this.UpperCase {
+		return OptionErrorf("could not give both flags -l (--lower-case) and -u (--upper-case)")
+	}
+	return nil
+}
+
+func (this *SeqKitSeqOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitSeqOptions) Config(v *SeqKitConfig) *SeqKitSeqOptions {
 	this.inner.Config = v.inner
 	return this
@@ -159,9 +194,15 @@ func Seq(input *api.IDataFrame[string], o *SeqKitSeqOptions) (*api.IDataFrame[st
 		o = &SeqKitSeqOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 
-	libprepare, err := api.AddParam(libSource("SeqTransform"), "opts", OptionsToString(opts))
+	libprepare, err := metricsSource("SeqTransform")
+	if err != nil {
+		return nil, err
+	}
+	libprepare, err = api.AddParam(libprepare, "opts", OptionsToString(opts))
 	if err != nil {
 		return nil, err
 	}
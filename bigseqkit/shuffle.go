@@ -20,6 +20,15 @@ func (this *ShuffleOptions) setDefaults() *ShuffleOptions {
 	return this
 }
 
+func (this *ShuffleOptions) Validate() error {
+	return this.Config.Validate()
+}
+
+func (this *SeqKitShuffleOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitShuffleOptions) Config(v *SeqKitConfig) *SeqKitShuffleOptions {
 	this.inner.Config = v.inner
 	return this
@@ -35,7 +44,9 @@ func Shuffle(input *api.IDataFrame[string], o *SeqKitShuffleOptions) (*api.IData
 		o = &SeqKitShuffleOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 
 	n, err := input.Partitions()
 	if err != nil {
@@ -0,0 +1,138 @@
+package bigseqkit
+
+import (
+	"ignis/driver/api"
+)
+
+type SeqKitSlidingOptions struct {
+	inner SlidingOptions
+}
+
+type SlidingOptions struct {
+	Config   KitConfig
+	Step     *int
+	Window   *int
+	Circular *bool
+	Greedy   *bool
+	Suffix   *string
+	Stats    *bool
+	SplitLen *int
+}
+
+func (this *SlidingOptions) setDefaults() *SlidingOptions {
+	this.Config.setDefaults()
+	setDefault(&this.Step, 0)
+	setDefault(&this.Window, 0)
+	setDefault(&this.Circular, false)
+	setDefault(&this.Greedy, false)
+	setDefault(&this.Suffix, "_sliding")
+	setDefault(&this.Stats, false)
+	setDefault(&this.SplitLen, 1000000)
+
+	return this
+}
+
+func (this *SlidingOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.Step <= 0 {
+		return optionError("Step", "value of flag -s (--step) should be greater than 0")
+	}
+	if *this.Window <= 0 {
+		return optionError("Window", "value of flag -W (--window) should be greater than 0")
+	}
+	if *this.SplitLen < 0 {
+		return optionError("SplitLen", "value of flag --split-len should not be negative")
+	}
+	return nil
+}
+
+func (this *SeqKitSlidingOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
+func (this *SeqKitSlidingOptions) Config(v *SeqKitConfig) *SeqKitSlidingOptions {
+	this.inner.Config = v.inner
+	return this
+}
+
+func (this *SeqKitSlidingOptions) Step(v int) *SeqKitSlidingOptions {
+	this.inner.Step = &v
+	return this
+}
+
+func (this *SeqKitSlidingOptions) Window(v int) *SeqKitSlidingOptions {
+	this.inner.Window = &v
+	return this
+}
+
+func (this *SeqKitSlidingOptions) Circular(v bool) *SeqKitSlidingOptions {
+	this.inner.Circular = &v
+	return this
+}
+
+// Greedy outputs the last windows even if they are shorter than the window size.
+func (this *SeqKitSlidingOptions) Greedy(v bool) *SeqKitSlidingOptions {
+	this.inner.Greedy = &v
+	return this
+}
+
+func (this *SeqKitSlidingOptions) Suffix(v string) *SeqKitSlidingOptions {
+	this.inner.Suffix = &v
+	return this
+}
+
+// Stats outputs a table line per window (ID, start, end, length, GC, GC skew, AT skew, N fraction and
+// Shannon entropy) instead of the window subsequences.
+func (this *SeqKitSlidingOptions) Stats(v bool) *SeqKitSlidingOptions {
+	this.inner.Stats = &v
+	return this
+}
+
+// SplitLen sets the length of the chunks that long sequences are split into, so the windows of a long
+// sequence are distributed among the executors. 0 disables the splitting.
+func (this *SeqKitSlidingOptions) SplitLen(v int) *SeqKitSlidingOptions {
+	this.inner.SplitLen = &v
+	return this
+}
+
+// Sliding extracts the subsequences (or their statistics) of sliding windows. Sequences longer than SplitLen
+// are split into chunks of whole windows that are repartitioned among the executors keeping the order.
+func Sliding(input *api.IDataFrame[string], o *SeqKitSlidingOptions) (*api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitSlidingOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	libSplit, err := api.AddParam(libSource("SlidingSplit"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := api.MapPartitions[string, string](input, libSplit)
+	if err != nil {
+		return nil, err
+	}
+
+	if *opts.SplitLen > 0 {
+		n, err := chunks.Partitions()
+		if err != nil {
+			return nil, err
+		}
+		if chunks, err = chunks.Repartition(n, true, true); err != nil {
+			return nil, err
+		}
+	}
+
+	libSliding, err := api.AddParam(libSource("Sliding"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return api.MapPartitions[string, string](chunks, libSliding)
+}
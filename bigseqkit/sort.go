@@ -1,7 +1,6 @@
 package bigseqkit
 
 import (
-	"fmt"
 	"ignis/driver/api"
 	"ignis/executor/api/ipair"
 )
@@ -38,6 +37,27 @@ func (this *SortOptions) setDefaults() *SortOptions {
 	return this
 }
 
+func (this *SortOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	n := 0
+	for _, by := range []bool{*this.BySeq, *this.ByName, *this.ByLength || *this.ByBases} {
+		if by {
+			n++
+		}
+	}
+	if n > 1 {
+		return OptionErrorf("only one of the options (byLength), (byName) and (bySeq) is allowed")
+	}
+	return nil
+}
+
+func (this *SeqKitSortOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitSortOptions) Config(v *SeqKitConfig) *SeqKitSortOptions {
 	this.inner.Config = v.inner
 	return this
@@ -93,11 +113,12 @@ func Sort(input *api.IDataFrame[string], o *SeqKitSortOptions) (*api.IDataFrame[
 		o = &SeqKitSortOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 
 	inNaturalOrder := *opts.InNaturalOrder
 	bySeq := *opts.BySeq
-	byName := *opts.ByName
 	byLength := *opts.ByLength
 	byBases := *opts.ByBases
 	reverse := *opts.Reverse
@@ -107,20 +128,6 @@ func Sort(input *api.IDataFrame[string], o *SeqKitSortOptions) (*api.IDataFrame[
 		*opts.ByLength = true
 	}
 
-	n := 0
-	if bySeq {
-		n++
-	}
-	if byName {
-		n++
-	}
-	if byLength {
-		n++
-	}
-	if n > 1 {
-		return nil, fmt.Errorf("only one of the options (byLength), (byName) and (bySeq) is allowed")
-	}
-
 	if byLength {
 		parser, err := api.AddParam(libSource("SortParseInputInt"), "opts", OptionsToString(opts))
 		if err != nil {
@@ -37,6 +37,31 @@ func (this *StatsOptions) setDefaults() *StatsOptions {
 	return this
 }
 
+func (this *StatsOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if len(*this.GapLetters) == 0 {
+		return optionError("GapLetters", "value of flag -G (--gap-letters) should not be empty")
+	}
+	for _, c := range *this.GapLetters {
+		if c > 127 {
+			return optionError("GapLetters", "value of -G (--gap-letters) contains non-ASCII characters")
+		}
+	}
+	switch strings.ToLower(*this.FqEncoding) {
+	case "", "sanger", "solexa", "illumina-1.3+", "illumina-1.5+", "illumina-1.8+":
+	default:
+		return optionError("FqEncoding", "unsupported quality encoding: %s. available values: 'sanger', 'solexa', 'illumina-1.3+', 'illumina-1.5+', 'illumina-1.8+'", *this.FqEncoding)
+	}
+	return nil
+}
+
+func (this *SeqKitStatsOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitStatsOptions) Config(v *SeqKitConfig) *SeqKitStatsOptions {
 	this.inner.Config = v.inner
 	return this
@@ -77,7 +102,9 @@ func Stats(name string, format string, input *api.IDataFrame[string], o *SeqKitS
 		o = &SeqKitStatsOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 	libprepare, err := api.AddParam(libSource("Stats"), "opts", OptionsToString(opts))
 	if err != nil {
 		return nil, err
@@ -1,22 +1,31 @@
 package bigseqkit
 
-import "ignis/driver/api"
+import (
+	"github.com/shenwei356/bio/seq"
+	"ignis/driver/api"
+	"ignis/executor/api/ipair"
+)
 
 type SeqKitSubseqOptions struct {
 	inner SubseqOptions
 }
 
 type SubseqOptions struct {
-	Config     KitConfig
-	Chr        *[]string
-	Region     *string
-	Gtf        *string
-	Feature    *[]string
-	UpStream   *int
-	DownStream *int
-	OnlyFlank  *bool
-	Bed        *string
-	GtfTag     *string
+	Config      KitConfig
+	Chr         *[]string
+	Region      *string
+	Gtf         *string
+	Feature     *[]string
+	UpStream    *int
+	DownStream  *int
+	OnlyFlank   *bool
+	Bed         *string
+	GtfTag      *string
+	Gff         *string
+	Attribute   *[]string
+	Extract     *string
+	GroupBy     *string
+	TranslTable *int
 }
 
 func (this *SubseqOptions) setDefaults() *SubseqOptions {
@@ -30,10 +39,73 @@ func (this *SubseqOptions) setDefaults() *SubseqOptions {
 	setDefault(&this.OnlyFlank, false)
 	setDefault(&this.Bed, "")
 	setDefault(&this.GtfTag, "")
+	setDefault(&this.Gff, "")
+	setDefault(&this.Attribute, []string{})
+	setDefault(&this.Extract, "")
+	setDefault(&this.GroupBy, "transcript")
+	setDefault(&this.TranslTable, 1)
 
 	return this
 }
 
+func (this *SubseqOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.OnlyFlank {
+		if *this.UpStream > 0 && *this.DownStream > 0 {
+			return optionError("OnlyFlank", "when flag -f (--only-flank) given,"+
+				" only one of flags -u (--up-stream) and -d (--down-stream) is allowed")
+		} else if *this.UpStream == 0 && *this.DownStream == 0 {
+			return optionError("OnlyFlank", "when flag -f (--only-flank) given,"+
+				" one of flags -u (--up-stream) and -d (--down-stream) should be given")
+		}
+	}
+	if *this.UpStream < 0 {
+		return optionError("UpStream", "value of flag -u (--up-stream) should not be negative")
+	}
+	if *this.DownStream < 0 {
+		return optionError("DownStream", "value of flag -d (--down-stream) should not be negative")
+	}
+	if *this.Region != "" {
+		if *this.UpStream > 0 || *this.DownStream > 0 || *this.OnlyFlank {
+			return optionError("Region", "when flag -r (--region) given,"+
+				" any of flags -u (--up-stream), -d (--down-stream) and -f (--only-flank) is not allowed")
+		}
+		if err := validateRegion("subseq", *this.Region); err != nil {
+			return err
+		}
+	}
+	switch *this.Extract {
+	case "":
+	case "transcript", "cds", "protein":
+		if *this.Gtf != "" || *this.Region != "" {
+			return optionError("Extract", "flag --extract only works with --gff or --bed")
+		}
+		if *this.UpStream > 0 || *this.DownStream > 0 || *this.OnlyFlank {
+			return optionError("Extract", "when flag --extract given,"+
+				" any of flags -u (--up-stream), -d (--down-stream) and -f (--only-flank) is not allowed")
+		}
+	default:
+		return optionError("Extract", "invalid value of flag --extract: %s. available values: 'transcript', 'cds', 'protein'", *this.Extract)
+	}
+	if *this.GroupBy != "transcript" && *this.GroupBy != "gene" {
+		return optionError("GroupBy", "invalid value of flag --group-by: %s. available values: 'transcript', 'gene'", *this.GroupBy)
+	}
+	if _, ok := seq.CodonTables[*this.TranslTable]; !ok {
+		return optionError("TranslTable", "invalid translate table: %d", *this.TranslTable)
+	}
+	if *this.Bed != "" && len(*this.Feature) > 0 {
+		return optionError("Feature", "when given flag -b (--bed), flag -f (--feature) is not allowed")
+	}
+	return nil
+}
+
+func (this *SeqKitSubseqOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitSubseqOptions) Config(v *SeqKitConfig) *SeqKitSubseqOptions {
 	this.inner.Config = v.inner
 	return this
@@ -84,12 +156,48 @@ func (this *SeqKitSubseqOptions) GtfTag(v string) *SeqKitSubseqOptions {
 	return this
 }
 
+func (this *SeqKitSubseqOptions) Gff(v string) *SeqKitSubseqOptions {
+	this.inner.Gff = &v
+	return this
+}
+
+func (this *SeqKitSubseqOptions) Attribute(v []string) *SeqKitSubseqOptions {
+	this.inner.Attribute = &v
+	return this
+}
+
+// Extract selects the spliced sequences to output from GFF3 or BED12 files: "transcript" joins the exons
+// (or BED12 blocks), "cds" joins the CDS (or the thick part of the blocks) and "protein" translates the CDS.
+// The default "" outputs every selected feature.
+func (this *SeqKitSubseqOptions) Extract(v string) *SeqKitSubseqOptions {
+	this.inner.Extract = &v
+	return this
+}
+
+// GroupBy names the spliced sequences by "transcript" ID, or by "gene" ID keeping the longest transcript of every gene.
+func (this *SeqKitSubseqOptions) GroupBy(v string) *SeqKitSubseqOptions {
+	this.inner.GroupBy = &v
+	return this
+}
+
+func (this *SeqKitSubseqOptions) TranslTable(v int) *SeqKitSubseqOptions {
+	this.inner.TranslTable = &v
+	return this
+}
+
+// Subseq outputs the subsequences of a region or, with Gtf, Gff or Bed, one subsequence for every selected
+// feature of each record.
 func Subseq(input *api.IDataFrame[string], o *SeqKitSubseqOptions) (*api.IDataFrame[string], error) {
 	if o == nil {
 		o = &SeqKitSubseqOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+	if *opts.Region == "" && *opts.Gtf == "" && *opts.Gff == "" && *opts.Bed == "" {
+		return nil, OptionErrorf("one of the options needed: -r/--region, --bed, --gtf, --gff")
+	}
 
 	libprepare, err := api.AddParam(libSource("SubseqTransform"), "opts", OptionsToString(opts))
 	if err != nil {
@@ -98,3 +206,97 @@ func Subseq(input *api.IDataFrame[string], o *SeqKitSubseqOptions) (*api.IDataFr
 
 	return api.MapPartitions[string, string](input, libprepare)
 }
+
+// SubseqJoin extracts the features of an annotation DataFrame (the lines of a "gtf", "gff" or "bed" file)
+// without loading the whole annotation in every executor. The annotation lines are keyed by chromosome and
+// by the bin (a fixed window of the chromosome) where the feature starts, and every record is split in the
+// slices of the bins with features, from the bin to the end of its last feature. The records and the lines
+// are co-grouped, so a big chromosome is spread over several groups and a feature spanning several bins is
+// extracted whole by the bin where it starts. The transcripts of a GFF3 file are built from several lines,
+// so with Extract they are keyed by chromosome only. The annotation file options (Gtf, Gff and Bed) are ignored.
+func SubseqJoin(input *api.IDataFrame[string], annotation *api.IDataFrame[string], format string, o *SeqKitSubseqOptions) (*api.IDataFrame[string], error) {
+	if o == nil {
+		o = &SeqKitSubseqOptions{}
+	}
+	opts := o.inner
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
+
+	if format != "gtf" && format != "gff" && format != "bed" {
+		return nil, OptionErrorf("invalid annotation format: %s. available values: 'gtf', 'gff', 'bed'", format)
+	}
+	if *opts.Region != "" {
+		return nil, OptionErrorf("flag -r (--region) is not allowed with an annotation")
+	}
+	opts.Gtf, opts.Gff, opts.Bed = new(string), new(string), new(string)
+
+	libFeatures, err := api.AddParam(libSource("SubseqKeyFeatures"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+	libFeatures, err = api.AddParam(libFeatures, "format", format)
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := api.MapPartitions[string, ipair.IPair[string, string]](annotation, libFeatures)
+	if err != nil {
+		return nil, err
+	}
+	if err = features.Cache(); err != nil {
+		return nil, err
+	}
+
+	libReach, err := api.AddParam(libSource("SubseqReach"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+	libReach, err = api.AddParam(libReach, "format", format)
+	if err != nil {
+		return nil, err
+	}
+	partial, err := api.MapPartitions[ipair.IPair[string, string], map[string]int64](features, libReach)
+	if err != nil {
+		return nil, err
+	}
+	reach, err := partial.Reduce(libSource("SubseqReachReduce"))
+	if err != nil {
+		return nil, err
+	}
+
+	libRecords, err := api.AddParam(libSource("SubseqKeyRecords"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+	libRecords, err = api.AddParam(libRecords, "reach", OptionsToString(reach))
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := api.MapPartitions[string, ipair.IPair[string, string]](input, libRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := records.Union(features, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped, err := api.GroupByKey[string, string](api.ToPair[string, string](u), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	join, err := api.AddParam(libSource("SubseqJoin"), "opts", OptionsToString(opts))
+	if err != nil {
+		return nil, err
+	}
+	join, err = api.AddParam(join, "format", format)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.Flatmap[ipair.IPair[string, []string], string](grouped.FromPair(), join)
+}
@@ -1,6 +1,11 @@
 package bigseqkit
 
-import "ignis/driver/api"
+import (
+	"github.com/shenwei356/bio/seq"
+	"ignis/driver/api"
+	"regexp"
+	"strconv"
+)
 
 type SeqKitTranslateOptions struct {
 	inner TranslateOptions
@@ -17,6 +22,9 @@ type TranslateOptions struct {
 	ListTranslTable              *int
 	ListTranslTableWithAmbCodons *int
 	AppendFrame                  *bool
+	TranslTableFrom              *string
+	TranslTableRegexp            *string
+	CodonTableFile               *string
 }
 
 func (this *TranslateOptions) setDefaults() *TranslateOptions {
@@ -30,10 +38,54 @@ func (this *TranslateOptions) setDefaults() *TranslateOptions {
 	setDefault(&this.ListTranslTable, -1)
 	setDefault(&this.ListTranslTableWithAmbCodons, -1)
 	setDefault(&this.AppendFrame, false)
+	setDefault(&this.TranslTableFrom, "")
+	setDefault(&this.TranslTableRegexp, `transl_table=(\d+)`)
+	setDefault(&this.CodonTableFile, "")
 
 	return this
 }
 
+func (this *TranslateOptions) Validate() error {
+	if err := this.Config.Validate(); err != nil {
+		return err
+	}
+	if *this.CodonTableFile == "" {
+		// tables of the codon table file are only known by the executors
+		if _, ok := seq.CodonTables[*this.TranslTable]; !ok {
+			return optionError("TranslTable", "invalid translate table: %d", *this.TranslTable)
+		}
+	}
+	if *this.TranslTableFrom == "header" {
+		re, err := regexp.Compile(*this.TranslTableRegexp)
+		if err != nil {
+			return optionError("TranslTableRegexp", "invalid translate table regular expression: %s", err)
+		}
+		if re.NumSubexp() < 1 {
+			return optionError("TranslTableRegexp", `translate table regular expression must contains "(" and ")" to capture the table: %s`, *this.TranslTableRegexp)
+		}
+	}
+	return validateFrames(*this.Frame)
+}
+
+// validateFrames checks the frames of translate and orfs.
+func validateFrames(frames []string) error {
+	for _, _frame := range frames {
+		frame, err := strconv.Atoi(_frame)
+		if err != nil {
+			return optionError("Frame", "invalid frame(s): %s. available: 1, 2, 3, -1, -2, -3, and 6 for all. multiple frames should be separated by comma", _frame)
+		}
+		if !(frame == 1 || frame == 2 || frame == 3 || frame == -1 || frame == -2 || frame == -3 || frame == 6) {
+			return optionError("Frame", "invalid frame: %d. available: 1, 2, 3, -1, -2, -3, and 6 for all", frame)
+		}
+	}
+	return nil
+}
+
+func (this *SeqKitTranslateOptions) Validate() error {
+	opts := this.inner
+	return opts.setDefaults().Validate()
+}
+
 func (this *SeqKitTranslateOptions) Config(v *SeqKitConfig) *SeqKitTranslateOptions {
 	this.inner.Config = v.inner
 	return this
@@ -84,12 +136,34 @@ func (this *SeqKitTranslateOptions) AppendFrame(v bool) *SeqKitTranslateOptions
 	return this
 }
 
+// TranslTableFrom selects the translate table of every record: "header" captures it from the header with
+// TranslTableRegexp, any other value is a tab-delimited file mapping record IDs to tables. Records without
+// table use TranslTable.
+func (this *SeqKitTranslateOptions) TranslTableFrom(v string) *SeqKitTranslateOptions {
+	this.inner.TranslTableFrom = &v
+	return this
+}
+
+// TranslTableRegexp sets the regular expression capturing the translate table from the header.
+func (this *SeqKitTranslateOptions) TranslTableRegexp(v string) *SeqKitTranslateOptions {
+	this.inner.TranslTableRegexp = &v
+	return this
+}
+
+// CodonTableFile loads user-defined codon tables in NCBI format, they can replace the built-in tables.
+func (this *SeqKitTranslateOptions) CodonTableFile(v string) *SeqKitTranslateOptions {
+	this.inner.CodonTableFile = &v
+	return this
+}
+
 func Translate(input *api.IDataFrame[string], o *SeqKitTranslateOptions) (*api.IDataFrame[string], error) {
 	if o == nil {
 		o = &SeqKitTranslateOptions{}
 	}
 	opts := o.inner
-	opts.setDefaults()
+	if err := opts.setDefaults().Validate(); err != nil {
+		return nil, err
+	}
 
 	libprepare, err := api.AddParam(libSource("Translate"), "opts", OptionsToString(opts))
 	if err != nil {